@@ -11,7 +11,7 @@ type Base struct {
 	ID        uuid.UUID `gorm:"type:uuid;primaryKey;"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	DeletedAt time.Time `gorm:"index"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 func (b *Base) BeforeCreate(tx *gorm.DB) (err error) {
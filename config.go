@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+)
+
+const configUsage = "usage: golang-todo config check"
+
+// runConfig implements the config subcommand
+func runConfig(cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(configUsage)
+	}
+
+	switch args[0] {
+	case "check":
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, setting := range cfg.Settings() {
+			source := "env"
+			if setting.Default {
+				source = "default"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, source)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		// Problems are reported the way startup treats them in the configured mode
+		fmt.Fprintln(out)
+		warnings, err := cfg.Check()
+		for _, warning := range warnings {
+			fmt.Fprintf(out, "warning: %s\n", warning)
+		}
+		var invalid *config.ValidationError
+		if errors.As(err, &invalid) {
+			for _, problem := range invalid.Problems {
+				fmt.Fprintf(out, "error: %s\n", problem)
+			}
+			return fmt.Errorf("configuration has %d problems that fail startup in %s mode", len(invalid.Problems), cfg.App.Mode)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "configuration is valid for %s mode\n", cfg.App.Mode)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown config command %q\n%s", args[0], configUsage)
+	}
+}
@@ -11,6 +11,9 @@ type UserResponse struct {
 type LoginRequest struct {
 	Username string `json:"username" validate:"required,max=255,min=1"`
 	Password string `json:"password" validate:"required,max=100,min=1"`
+
+	// DeviceName labels the session in the session list, such as "Alice's phone"
+	DeviceName string `json:"device_name" validate:"omitempty,max=255"`
 }
 type LoginResponse struct {
 	AccessToken  string       `json:"access_token"`
@@ -33,6 +36,13 @@ type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required,min=1"`
 }
 
+// Change Password
+type ChangePasswordRequest struct {
+	CurrentPassword      string `json:"current_password" validate:"required,max=100,min=1"`
+	Password             string `json:"password" validate:"required,max=100,min=1"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,max=100,min=1,eqfield=Password"`
+}
+
 // Refresh Token
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required,min=1"`
@@ -40,4 +50,18 @@ type RefreshTokenRequest struct {
 type RefreshTokenResponse struct {
 	AccessToken  string       `json:"access_token"`
 	RefreshToken string       `json:"refresh_token"`
-}
\ No newline at end of file
+}
+
+// Sessions
+type SessionResponse struct {
+	ID         string `json:"id"`
+	DeviceName string `json:"device_name"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	Current    bool   `json:"current"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+}
+type LogoutAllResponse struct {
+	RevokedSessions int `json:"revoked_sessions"`
+}
@@ -20,7 +20,7 @@ func NewAuthHandler(authService AuthService, log logger.Logger) AuthHandler {
 }
 
 // @Summary      User login
-// @Description  User login with username and password
+// @Description  User login with username and password, which starts a session labelled with the optional device name
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
@@ -36,7 +36,7 @@ func (handler AuthHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	response := handler.authService.Login(ctx, req)
+	response := handler.authService.Login(ctx, req, clientInfo(ctx))
 	if response.StatusCode != 200 {
 		handler.log.Warn("Login request failed",
 			logger.F("operation", "Login"),
@@ -79,7 +79,7 @@ func (handler AuthHandler) Register(ctx *gin.Context) {
 }
 
 // @Summary      User logout
-// @Description  User logout with refresh token
+// @Description  User logout with refresh token, which signs out its session and revokes the access token of the request
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
@@ -87,6 +87,7 @@ func (handler AuthHandler) Register(ctx *gin.Context) {
 // @Security	 BearerAuth
 // @Success      200  {object}  models.Response
 // @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
 // @Failure      422  {object}  models.Response
 // @Failure      500  {object}  models.Response
 // @Router       /auth/logout [post]
@@ -96,11 +97,10 @@ func (handler AuthHandler) Logout(ctx *gin.Context) {
 		return
 	}
 
-	// Get user ID from JWT claims (optional - for logging purposes)
-	// This demonstrates how to use GetUserIDFromContext helper
-	userID, err := utils.GetUserIDFromContext(ctx)
+	// Get JWT claims, the access token of the request is revoked along with the session
+	claims, err := utils.GetClaimsFromContext(ctx)
 	if err != nil {
-		handler.log.Warn("Failed to get user ID from context",
+		handler.log.Warn("Failed to get claims from context",
 			logger.F("operation", "Logout"),
 			logger.F("error", err),
 		)
@@ -109,12 +109,57 @@ func (handler AuthHandler) Logout(ctx *gin.Context) {
 		return
 	}
 
-	response := handler.authService.Logout(ctx, req.RefreshToken)
-	if response.StatusCode != 201 {
+	response := handler.authService.Logout(ctx, req.RefreshToken, claims)
+	if response.StatusCode != 200 {
 		handler.log.Warn("Logout request failed",
 			logger.F("operation", "Logout"),
 			logger.F("status_code", response.StatusCode),
 			logger.F("message", response.Message),
+			logger.F("user_id", claims.Subject),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Change password
+// @Description  Change the password of the authenticated user. Every session is signed out, including the current one, so the user has to log in again with the new password
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ChangePasswordRequest  true  "Change Password Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /auth/change-password [post]
+func (handler AuthHandler) ChangePassword(ctx *gin.Context) {
+	var req ChangePasswordRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Change password"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+	claims, _ := utils.GetClaimsFromContext(ctx)
+
+	response := handler.authService.ChangePassword(ctx, userID, req, claims)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Change password request failed",
+			logger.F("operation", "Change password"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
 			logger.F("user_id", userID.String()),
 		)
 	}
@@ -172,12 +217,13 @@ func (handler AuthHandler) Me(ctx *gin.Context) {
 }
 
 // @Summary      User refresh token
-// @Description  User refresh access token with refresh token
+// @Description  User refresh access token with refresh token, the refresh token is rotated and presenting a rotated one again revokes every token of its login
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Param        body  body      RefreshTokenRequest  true  "Refresh Token Request"
 // @Success      201  {object}  models.Response{data=auth.RefreshTokenResponse}
+// @Failure      401  {object}  models.Response
 // @Failure      404  {object}  models.Response
 // @Failure      422  {object}  models.Response
 // @Failure      500  {object}  models.Response
@@ -190,7 +236,7 @@ func (handler AuthHandler) RefreshToken(ctx *gin.Context) {
 
 	// Call service with just the refresh token
 	// Service will validate the refresh token and return new tokens
-	response := handler.authService.RefreshToken(ctx, req.RefreshToken)
+	response := handler.authService.RefreshToken(ctx, req.RefreshToken, clientInfo(ctx))
 	if response.StatusCode != 201 {
 		handler.log.Warn("Refresh Token request failed",
 			logger.F("operation", "Refresh Token"),
@@ -201,3 +247,11 @@ func (handler AuthHandler) RefreshToken(ctx *gin.Context) {
 
 	ctx.JSON(response.StatusCode, response)
 }
+
+// clientInfo describes the client of the request for the session it logs in or refreshes
+func clientInfo(ctx *gin.Context) ClientInfo {
+	return ClientInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+}
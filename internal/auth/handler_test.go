@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func newTestRouter(service AuthService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	jwtUtils := newTestJWTUtils()
+	handler := NewAuthHandler(service, logger.NewNopLogger())
+
+	r := gin.New()
+	authGroup := r.Group("/auth")
+	authGroup.POST("/login", handler.Login)
+	authGroup.POST("/register", handler.Register)
+	authenticate := middleware.AuthMiddleware(jwtUtils, NewAccessTokenValidator(service.authRepository, service.denylist), true)
+	authGroup.POST("/logout", authenticate, handler.Logout)
+	authGroup.POST("/logout-all", authenticate, handler.LogoutAll)
+	authGroup.POST("/change-password", authenticate, handler.ChangePassword)
+	authGroup.POST("/refresh-token", handler.RefreshToken)
+	authGroup.GET("/me", authenticate, handler.Me)
+	authGroup.GET("/sessions", authenticate, handler.GetSessions)
+	authGroup.DELETE("/sessions/:id", authenticate, handler.RevokeSession)
+	return r
+}
+
+func doRequest(r http.Handler, method string, path string, body any, accessToken string) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", accessToken)
+	}
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAuthHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       func(login LoginResponse) any
+		withToken  bool
+		wantStatus int
+	}{
+		{
+			name:       "logs in",
+			method:     http.MethodPost,
+			path:       "/auth/login",
+			body:       func(LoginResponse) any { return LoginRequest{Username: "alice", Password: "secret"} },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "validates the login body",
+			method:     http.MethodPost,
+			path:       "/auth/login",
+			body:       func(LoginResponse) any { return LoginRequest{Username: "alice"} },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "registers",
+			method: http.MethodPost,
+			path:   "/auth/register",
+			body: func(LoginResponse) any {
+				return RegisterRequest{Username: "bob", Password: "secret", PasswordConfirmation: "secret"}
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:   "requires a matching password confirmation",
+			method: http.MethodPost,
+			path:   "/auth/register",
+			body: func(LoginResponse) any {
+				return RegisterRequest{Username: "bob", Password: "secret", PasswordConfirmation: "other"}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "returns the current user",
+			method:     http.MethodGet,
+			path:       "/auth/me",
+			body:       func(LoginResponse) any { return nil },
+			withToken:  true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "requires a token for the current user",
+			method:     http.MethodGet,
+			path:       "/auth/me",
+			body:       func(LoginResponse) any { return nil },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "logs out",
+			method:     http.MethodPost,
+			path:       "/auth/logout",
+			body:       func(login LoginResponse) any { return LogoutRequest{RefreshToken: login.RefreshToken} },
+			withToken:  true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "lists sessions",
+			method:     http.MethodGet,
+			path:       "/auth/sessions",
+			body:       func(LoginResponse) any { return nil },
+			withToken:  true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "requires a token for the sessions",
+			method:     http.MethodGet,
+			path:       "/auth/sessions",
+			body:       func(LoginResponse) any { return nil },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "validates the session ID",
+			method:     http.MethodDelete,
+			path:       "/auth/sessions/not-a-uuid",
+			body:       func(LoginResponse) any { return nil },
+			withToken:  true,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "reports an unknown session",
+			method:     http.MethodDelete,
+			path:       "/auth/sessions/" + uuid.NewString(),
+			body:       func(LoginResponse) any { return nil },
+			withToken:  true,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "logs out everywhere",
+			method:     http.MethodPost,
+			path:       "/auth/logout-all",
+			body:       func(LoginResponse) any { return nil },
+			withToken:  true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "changes the password",
+			method: http.MethodPost,
+			path:   "/auth/change-password",
+			body: func(LoginResponse) any {
+				return ChangePasswordRequest{CurrentPassword: "secret", Password: "new-secret", PasswordConfirmation: "new-secret"}
+			},
+			withToken:  true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "requires a matching new password confirmation",
+			method: http.MethodPost,
+			path:   "/auth/change-password",
+			body: func(LoginResponse) any {
+				return ChangePasswordRequest{CurrentPassword: "secret", Password: "new-secret", PasswordConfirmation: "other"}
+			},
+			withToken:  true,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "refreshes tokens",
+			method:     http.MethodPost,
+			path:       "/auth/refresh-token",
+			body:       func(login LoginResponse) any { return RefreshTokenRequest{RefreshToken: login.RefreshToken} },
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService()
+			seedUser(t, service, "alice", "secret")
+			r := newTestRouter(service)
+
+			recorder := doRequest(r, http.MethodPost, "/auth/login", LoginRequest{Username: "alice", Password: "secret"}, "")
+			var login struct {
+				Data LoginResponse `json:"data"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &login); err != nil {
+				t.Fatalf("invalid login response: %v", err)
+			}
+
+			accessToken := ""
+			if tt.withToken {
+				accessToken = login.Data.AccessToken
+			}
+
+			recorder = doRequest(r, tt.method, tt.path, tt.body(login.Data), accessToken)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+		})
+	}
+}
+
+// An access token stops working as soon as its session is revoked, without waiting for it to expire
+func TestAuthHandlerRevokedSession(t *testing.T) {
+	service, _ := newTestService()
+	seedUser(t, service, "alice", "secret")
+	r := newTestRouter(service)
+
+	recorder := doRequest(r, http.MethodPost, "/auth/login", LoginRequest{Username: "alice", Password: "secret"}, "")
+	var login struct {
+		Data LoginResponse `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &login); err != nil {
+		t.Fatalf("invalid login response: %v", err)
+	}
+	claims, err := newTestJWTUtils().ParseJWT(login.Data.AccessToken)
+	if err != nil {
+		t.Fatalf("invalid access token: %v", err)
+	}
+
+	recorder = doRequest(r, http.MethodDelete, "/auth/sessions/"+claims.SessionID, nil, login.Data.AccessToken)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("revoke status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+	recorder = doRequest(r, http.MethodGet, "/auth/me", nil, login.Data.AccessToken)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("me with the revoked session status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+// The access token a user logged out with is rejected right away, without waiting for it to expire
+func TestAuthHandlerLoggedOutAccessToken(t *testing.T) {
+	service, _ := newTestService()
+	seedUser(t, service, "alice", "secret")
+	r := newTestRouter(service)
+
+	recorder := doRequest(r, http.MethodPost, "/auth/login", LoginRequest{Username: "alice", Password: "secret"}, "")
+	var login struct {
+		Data LoginResponse `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &login); err != nil {
+		t.Fatalf("invalid login response: %v", err)
+	}
+
+	recorder = doRequest(r, http.MethodPost, "/auth/logout", LogoutRequest{RefreshToken: login.Data.RefreshToken}, login.Data.AccessToken)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("logout status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body.String())
+	}
+	recorder = doRequest(r, http.MethodGet, "/auth/me", nil, login.Data.AccessToken)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("me after logout status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+}
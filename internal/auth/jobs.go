@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/denylist"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/module"
+)
+
+// NewPurgeUsersJob permanently deletes the users that were soft deleted longer than retention ago
+func NewPurgeUsersJob(authRepository AuthRepository, retention time.Duration, interval time.Duration, log logger.Logger) module.Job {
+	return module.Job{
+		Name:     "purge-users",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			purged, err := authRepository.PurgeUsersDeletedBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return err
+			}
+			if purged > 0 {
+				log.Info("Purged deleted users",
+					logger.F("operation", "Purge users"),
+					logger.F("count", purged),
+				)
+			}
+			return nil
+		},
+	}
+}
+
+// NewPurgeDenylistJob drops the denylisted access tokens that have expired since
+func NewPurgeDenylistJob(denylist denylist.Denylist, interval time.Duration, log logger.Logger) module.Job {
+	return module.Job{
+		Name:     "purge-denylist",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			purged, err := denylist.Purge(ctx)
+			if err != nil {
+				return err
+			}
+			if purged > 0 {
+				log.Info("Purged expired denylist entries",
+					logger.F("operation", "Purge denylist"),
+					logger.F("count", purged),
+				)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func TestPurgeUsersJob(t *testing.T) {
+	service, repository := newTestService()
+	kept := uuid.MustParse(seedUser(t, service, "alice", "secret").ID)
+	recent := uuid.MustParse(seedUser(t, service, "bob", "secret").ID)
+	expired := uuid.MustParse(seedUser(t, service, "carol", "secret").ID)
+	softDeleteUser(repository, recent, time.Now().Add(-time.Hour))
+	softDeleteUser(repository, expired, time.Now().Add(-48*time.Hour))
+	err := repository.CreateRefreshToken(context.Background(), &RefreshToken{
+		TokenHash: utils.HashRefreshToken("carol-token"),
+		FamilyID:  uuid.New(),
+		UserID:    expired,
+		ExpiredAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("failed to seed refresh token: %v", err)
+	}
+
+	job := NewPurgeUsersJob(repository, 24*time.Hour, time.Minute, logger.NewNopLogger())
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("job failed: %v", err)
+	}
+
+	// Soft deleted users are hidden from lookups, so check the stored rows directly
+	if _, exists := repository.users[kept]; !exists {
+		t.Errorf("active user was purged")
+	}
+	if _, exists := repository.users[recent]; !exists {
+		t.Errorf("user within the retention period was purged")
+	}
+	if _, exists := repository.users[expired]; exists {
+		t.Errorf("user past the retention period was kept")
+	}
+	if _, err := findRefreshToken(repository, "carol-token"); err == nil {
+		t.Errorf("refresh token of the purged user was kept")
+	}
+}
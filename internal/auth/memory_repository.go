@@ -0,0 +1,273 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MemoryAuthRepository is a thread-safe in-memory AuthRepository
+// It mirrors the semantics of the database implementation and is meant for tests
+type MemoryAuthRepository struct {
+	mu            sync.RWMutex
+	users         map[uuid.UUID]User
+	refreshTokens map[uuid.UUID]RefreshToken
+	sessions      map[uuid.UUID]Session
+}
+
+var _ AuthRepository = (*MemoryAuthRepository)(nil)
+
+func NewMemoryAuthRepository() *MemoryAuthRepository {
+	return &MemoryAuthRepository{
+		users:         make(map[uuid.UUID]User),
+		refreshTokens: make(map[uuid.UUID]RefreshToken),
+		sessions:      make(map[uuid.UUID]Session),
+	}
+}
+
+func (repository *MemoryAuthRepository) FindUserByUsername(ctx context.Context, username string) (User, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	for _, user := range repository.users {
+		if user.Username == username && !user.DeletedAt.Valid {
+			return user, nil
+		}
+	}
+	return User{}, gorm.ErrRecordNotFound
+}
+
+func (repository *MemoryAuthRepository) FindUserByID(ctx context.Context, userID uuid.UUID) (User, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	user, exists := repository.users[userID]
+	if !exists || user.DeletedAt.Valid {
+		return User{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (repository *MemoryAuthRepository) CreateUser(ctx context.Context, user *User) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	// Usernames are unique, like the constraint on the users table
+	// The constraint also covers soft deleted users until they are purged
+	for _, existing := range repository.users {
+		if existing.Username == user.Username {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	_ = user.BeforeCreate(nil)
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	repository.users[user.ID] = *user
+	return nil
+}
+
+func (repository *MemoryAuthRepository) UpdateUserPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	user, exists := repository.users[userID]
+	if !exists || user.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+	user.Password = password
+	user.UpdatedAt = time.Now()
+	repository.users[userID] = user
+	return nil
+}
+
+func (repository *MemoryAuthRepository) CreateRefreshToken(ctx context.Context, refreshToken *RefreshToken) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	now := time.Now()
+	for id, existing := range repository.refreshTokens {
+		if existing.UserID == refreshToken.UserID && existing.ExpiredAt.Before(now) {
+			delete(repository.refreshTokens, id)
+		}
+	}
+	for _, existing := range repository.refreshTokens {
+		if existing.TokenHash == refreshToken.TokenHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	_ = refreshToken.BeforeCreate(nil)
+	refreshToken.CreatedAt = now
+	refreshToken.UpdatedAt = now
+
+	repository.refreshTokens[refreshToken.ID] = *refreshToken
+	return nil
+}
+
+func (repository *MemoryAuthRepository) FindRefreshTokenByTokenHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	for _, refreshToken := range repository.refreshTokens {
+		if refreshToken.TokenHash == tokenHash {
+			return refreshToken, nil
+		}
+	}
+	return RefreshToken{}, gorm.ErrRecordNotFound
+}
+
+func (repository *MemoryAuthRepository) RotateRefreshToken(ctx context.Context, refreshToken RefreshToken, rotatedAt time.Time) (int, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	existing, exists := repository.refreshTokens[refreshToken.ID]
+	if !exists || existing.RotatedAt != nil {
+		return 0, nil
+	}
+	existing.RotatedAt = &rotatedAt
+	existing.UpdatedAt = time.Now()
+	repository.refreshTokens[existing.ID] = existing
+	return 1, nil
+}
+
+func (repository *MemoryAuthRepository) CreateSession(ctx context.Context, session *Session) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	now := time.Now()
+	for id, existing := range repository.sessions {
+		if existing.UserID == session.UserID && existing.ExpiredAt.Before(now) {
+			repository.deleteSession(id)
+		}
+	}
+
+	_ = session.BeforeCreate(nil)
+	session.CreatedAt = now
+	session.UpdatedAt = now
+
+	repository.sessions[session.ID] = *session
+	return nil
+}
+
+func (repository *MemoryAuthRepository) FindSessionByID(ctx context.Context, sessionID uuid.UUID) (Session, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	session, exists := repository.sessions[sessionID]
+	if !exists {
+		return Session{}, gorm.ErrRecordNotFound
+	}
+	return session, nil
+}
+
+func (repository *MemoryAuthRepository) FindSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]Session, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	now := time.Now()
+	sessions := make([]Session, 0)
+	for _, session := range repository.sessions {
+		if session.UserID == userID && session.ExpiredAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b Session) int {
+		if c := b.LastUsedAt.Compare(a.LastUsedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return sessions, nil
+}
+
+func (repository *MemoryAuthRepository) UpdateSession(ctx context.Context, session *Session) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	existing, exists := repository.sessions[session.ID]
+	if !exists {
+		return nil
+	}
+	existing.UserAgent = session.UserAgent
+	existing.IPAddress = session.IPAddress
+	existing.LastUsedAt = session.LastUsedAt
+	existing.AccessTokenID = session.AccessTokenID
+	existing.ExpiredAt = session.ExpiredAt
+	existing.UpdatedAt = time.Now()
+	repository.sessions[session.ID] = existing
+	return nil
+}
+
+func (repository *MemoryAuthRepository) DeleteSession(ctx context.Context, sessionID uuid.UUID) (int, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	return repository.deleteSession(sessionID), nil
+}
+
+func (repository *MemoryAuthRepository) DeleteSessionsByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	rowsAffected := 0
+	for id, session := range repository.sessions {
+		if session.UserID == userID {
+			rowsAffected += repository.deleteSession(id)
+		}
+	}
+	for id, refreshToken := range repository.refreshTokens {
+		if refreshToken.UserID == userID {
+			delete(repository.refreshTokens, id)
+		}
+	}
+	return rowsAffected, nil
+}
+
+// deleteSession removes the session and its refresh tokens, the caller holds the write lock
+func (repository *MemoryAuthRepository) deleteSession(sessionID uuid.UUID) int {
+	for id, refreshToken := range repository.refreshTokens {
+		if refreshToken.FamilyID == sessionID {
+			delete(repository.refreshTokens, id)
+		}
+	}
+	if _, exists := repository.sessions[sessionID]; !exists {
+		return 0
+	}
+	delete(repository.sessions, sessionID)
+	return 1
+}
+
+func (repository *MemoryAuthRepository) PurgeUsersDeletedBefore(ctx context.Context, before time.Time) (int, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	rowsAffected := 0
+	for id, user := range repository.users {
+		if !user.DeletedAt.Valid || !user.DeletedAt.Time.Before(before) {
+			continue
+		}
+		delete(repository.users, id)
+		rowsAffected++
+
+		// Mirror the ON DELETE CASCADE of the refresh_tokens and sessions tables
+		for tokenID, refreshToken := range repository.refreshTokens {
+			if refreshToken.UserID == id {
+				delete(repository.refreshTokens, tokenID)
+			}
+		}
+		for sessionID, session := range repository.sessions {
+			if session.UserID == id {
+				delete(repository.sessions, sessionID)
+			}
+		}
+	}
+	return rowsAffected, nil
+}
@@ -0,0 +1,4 @@
+-- Rows written before soft delete was enabled stored the zero time instead of NULL,
+-- which would now hide every one of them
+UPDATE users SET deleted_at = NULL WHERE deleted_at < '1970-01-01';
+UPDATE refresh_tokens SET deleted_at = NULL WHERE deleted_at < '1970-01-01';
@@ -0,0 +1,9 @@
+-- The hashes can't be turned back into tokens, so every session has to sign in again
+DELETE FROM refresh_tokens;
+
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+ALTER TABLE refresh_tokens DROP COLUMN rotated_at;
+ALTER TABLE refresh_tokens DROP COLUMN family_id;
+
+ALTER INDEX idx_refresh_tokens_token_hash RENAME TO idx_refresh_tokens_token;
+ALTER TABLE refresh_tokens RENAME COLUMN token_hash TO token;
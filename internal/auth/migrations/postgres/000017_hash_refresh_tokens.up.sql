@@ -0,0 +1,13 @@
+-- Refresh tokens are stored as the SHA-256 hex digest of the token, so a dump of the table can't be used to sign in
+ALTER TABLE refresh_tokens RENAME COLUMN token TO token_hash;
+ALTER INDEX idx_refresh_tokens_token RENAME TO idx_refresh_tokens_token_hash;
+UPDATE refresh_tokens SET token_hash = encode(sha256(convert_to(token_hash, 'UTF8')), 'hex');
+
+-- Every login starts a family that the tokens rotated from it belong to
+-- A rotated token is kept until it expires, so presenting it again can be told apart from an unknown token
+ALTER TABLE refresh_tokens ADD COLUMN family_id UUID NULL;
+UPDATE refresh_tokens SET family_id = id;
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+ALTER TABLE refresh_tokens ADD COLUMN rotated_at TIMESTAMP NULL;
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
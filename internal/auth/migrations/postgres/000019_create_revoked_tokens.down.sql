@@ -0,0 +1,4 @@
+ALTER TABLE sessions DROP COLUMN access_token_id;
+
+DROP INDEX IF EXISTS idx_revoked_tokens_expires_at;
+DROP TABLE IF EXISTS revoked_tokens;
@@ -0,0 +1,12 @@
+-- SQLite variant: UUIDs are stored as text and timestamps as ISO-8601 text
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    username VARCHAR(255) NOT NULL UNIQUE,
+    password VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL
+);
+
+-- Create index on deleted_at for soft delete queries
+CREATE INDEX idx_users_deleted_at ON users(deleted_at);
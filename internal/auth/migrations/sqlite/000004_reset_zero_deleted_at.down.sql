@@ -0,0 +1,2 @@
+-- NULL is the only valid value for rows that are not deleted, so there is nothing to undo
+SELECT 1;
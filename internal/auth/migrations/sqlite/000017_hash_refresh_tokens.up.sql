@@ -0,0 +1,26 @@
+-- Refresh tokens are stored as the SHA-256 hex digest of the token, so a dump of the table can't be used to sign in
+-- SQLite has no SHA-256 function to hash the stored tokens with, so every session has to sign in again
+DROP INDEX IF EXISTS idx_refresh_tokens_token;
+DROP INDEX IF EXISTS idx_refresh_tokens_user_id;
+DROP INDEX IF EXISTS idx_refresh_tokens_deleted_at;
+DROP TABLE IF EXISTS refresh_tokens;
+
+-- Every login starts a family that the tokens rotated from it belong to
+-- A rotated token is kept until it expires, so presenting it again can be told apart from an unknown token
+CREATE TABLE refresh_tokens (
+    id TEXT PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    family_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    expired_at TIMESTAMP NOT NULL,
+    rotated_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL,
+    CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refresh_tokens_deleted_at ON refresh_tokens(deleted_at);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
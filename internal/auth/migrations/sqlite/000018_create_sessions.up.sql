@@ -0,0 +1,23 @@
+-- A session is a login of a user, the refresh tokens rotated from it share its ID as their family_id
+CREATE TABLE sessions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    device_name VARCHAR(255) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    last_used_at TIMESTAMP NOT NULL,
+    expired_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL,
+    CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sessions_deleted_at ON sessions(deleted_at);
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+
+-- Logins from before sessions were recorded become sessions of an unknown device
+INSERT INTO sessions (id, user_id, last_used_at, expired_at, created_at, updated_at)
+SELECT family_id, user_id, MAX(created_at), MAX(expired_at), MIN(created_at), MAX(created_at)
+FROM refresh_tokens
+GROUP BY family_id, user_id;
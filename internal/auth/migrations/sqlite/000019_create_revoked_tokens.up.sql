@@ -0,0 +1,11 @@
+-- Access tokens revoked before they expired, see pkg/denylist
+-- A row is only needed until the token expires and is purged after that
+CREATE TABLE revoked_tokens (
+    token_id VARCHAR(36) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- The ID of the newest access token of a session, which is revoked along with the session
+ALTER TABLE sessions ADD COLUMN access_token_id VARCHAR(36) NOT NULL DEFAULT '';
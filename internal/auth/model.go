@@ -13,9 +13,15 @@ type User struct {
 	Password string
 }
 
+// RefreshToken is stored by the hash of the token, see utils.HashRefreshToken
+// Every login starts a family, and each refresh rotates the token into a new one of the same family
 type RefreshToken struct {
 	models.Base
-	Token     string `gorm:"index"`
+	TokenHash string `gorm:"index"`
+	FamilyID  uuid.UUID
 	UserID    uuid.UUID
 	ExpiredAt time.Time
+
+	// RotatedAt is set once the token was exchanged for a new one, presenting it again revokes the family
+	RotatedAt *time.Time
 }
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/Alfian57/golang-todo/pkg/middleware"
+	"github.com/Alfian57/golang-todo/pkg/module"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+func init() {
+	module.Register(Module{})
+}
+
+// Module wires the auth feature into the server
+type Module struct{}
+
+func (Module) Name() string {
+	return "auth"
+}
+
+func (Module) RegisterRoutes(router *gin.RouterGroup, deps *module.Dependencies) {
+	RegisterRoutes(router, deps)
+}
+
+// TokenValidator checks access tokens against the denylist and the sessions of the auth module
+func (Module) TokenValidator(deps *module.Dependencies) middleware.TokenValidator {
+	return NewAccessTokenValidator(NewAuthRepository(deps.DB), deps.Denylist)
+}
+
+func (Module) Migrations() fs.FS {
+	sub, _ := fs.Sub(migrations, "migrations")
+	return sub
+}
+
+func (Module) Jobs(deps *module.Dependencies) []module.Job {
+	var jobs []module.Job
+
+	trash := deps.Config.Trash
+	if trash.Retention > 0 && trash.PurgeInterval > 0 {
+		jobs = append(jobs, NewPurgeUsersJob(NewAuthRepository(deps.DB), trash.Retention, trash.PurgeInterval, deps.Log))
+	}
+	if interval := deps.Config.JWT.DenylistPurgeInterval; interval > 0 {
+		jobs = append(jobs, NewPurgeDenylistJob(deps.Denylist, interval, deps.Log))
+	}
+	return jobs
+}
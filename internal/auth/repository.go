@@ -8,50 +8,156 @@ import (
 	"gorm.io/gorm"
 )
 
-type AuthRepository struct {
+// AuthRepository is the persistence contract used by AuthService
+type AuthRepository interface {
+	FindUserByUsername(ctx context.Context, username string) (User, error)
+	FindUserByID(ctx context.Context, userID uuid.UUID) (User, error)
+	CreateUser(ctx context.Context, user *User) error
+	UpdateUserPassword(ctx context.Context, userID uuid.UUID, password string) error
+	CreateRefreshToken(ctx context.Context, refreshToken *RefreshToken) error
+	FindRefreshTokenByTokenHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, refreshToken RefreshToken, rotatedAt time.Time) (int, error)
+	CreateSession(ctx context.Context, session *Session) error
+	FindSessionByID(ctx context.Context, sessionID uuid.UUID) (Session, error)
+	FindSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	UpdateSession(ctx context.Context, session *Session) error
+	DeleteSession(ctx context.Context, sessionID uuid.UUID) (int, error)
+	DeleteSessionsByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	PurgeUsersDeletedBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+type authRepository struct {
 	db *gorm.DB
 }
 
 func NewAuthRepository(db *gorm.DB) AuthRepository {
-	return AuthRepository{
+	return authRepository{
 		db: db,
 	}
 }
 
-func (repository AuthRepository) FindUserByUsername(ctx context.Context, username string) (User, error) {
+func (repository authRepository) FindUserByUsername(ctx context.Context, username string) (User, error) {
 	user, err := gorm.G[User](repository.db).Where("username = ? ", username).First(ctx)
 	return user, err
 }
 
-func (repository AuthRepository) FindUserByID(ctx context.Context, userID uuid.UUID) (User, error) {
+func (repository authRepository) FindUserByID(ctx context.Context, userID uuid.UUID) (User, error) {
 	user, err := gorm.G[User](repository.db).Where("id = ?", userID).First(ctx)
 	return user, err
 }
 
-func (repository AuthRepository) CreateUser(ctx context.Context, user *User) error {
+func (repository authRepository) CreateUser(ctx context.Context, user *User) error {
 	result := gorm.WithResult()
 	return gorm.G[User](repository.db, result).Create(ctx, user)
 }
 
-func (repository AuthRepository) CreateRefreshToken(ctx context.Context, token string, userID uuid.UUID, expiredAt time.Time) (RefreshToken, error) {
-	refreshToken := RefreshToken{
-		Token:     token,
-		UserID:    userID,
-		ExpiredAt: expiredAt,
+// UpdateUserPassword replaces the password hash of the user
+func (repository authRepository) UpdateUserPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	result := repository.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("password", password)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// CreateRefreshToken saves the token, the expired tokens of the user are cleaned up along the way
+func (repository authRepository) CreateRefreshToken(ctx context.Context, refreshToken *RefreshToken) error {
+	err := repository.db.WithContext(ctx).Unscoped().
+		Where("user_id = ? AND expired_at < ?", refreshToken.UserID, time.Now().UTC()).
+		Delete(&RefreshToken{}).Error
+	if err != nil {
+		return err
 	}
 
 	result := gorm.WithResult()
-	err := gorm.G[RefreshToken](repository.db, result).Create(ctx, &refreshToken)
+	return gorm.G[RefreshToken](repository.db, result).Create(ctx, refreshToken)
+}
 
+func (repository authRepository) FindRefreshTokenByTokenHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	refreshToken, err := gorm.G[RefreshToken](repository.db).Where("token_hash = ?", tokenHash).First(ctx)
 	return refreshToken, err
 }
 
-func (repository AuthRepository) FindRefreshTokenByToken(ctx context.Context, token string) (RefreshToken, error) {
-	refreshToken, err := gorm.G[RefreshToken](repository.db).Where("token = ?", token).First(ctx)
-	return refreshToken, err
+// RotateRefreshToken marks the token as rotated, unless it already was
+// No rows are affected when a concurrent request rotated it first, which counts as a reuse of the token
+func (repository authRepository) RotateRefreshToken(ctx context.Context, refreshToken RefreshToken, rotatedAt time.Time) (int, error) {
+	result := repository.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL", refreshToken.ID).
+		Update("rotated_at", rotatedAt.UTC())
+	return int(result.RowsAffected), result.Error
 }
 
-func (repository AuthRepository) DeleteRefreshTokenByToken(ctx context.Context, token string) (int, error) {
-	rowsAffected, err := gorm.G[RefreshToken](repository.db).Where("token = ?", token).Delete(ctx)
+// CreateSession saves the session, the expired sessions of the user are cleaned up along the way
+func (repository authRepository) CreateSession(ctx context.Context, session *Session) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&Session{}).Unscoped().Select("id").
+			Where("user_id = ? AND expired_at < ?", session.UserID, time.Now().UTC())
+		if err := tx.Unscoped().Where("family_id IN (?)", expired).Delete(&RefreshToken{}).Error; err != nil {
+			return err
+		}
+		err := tx.Unscoped().Where("user_id = ? AND expired_at < ?", session.UserID, time.Now().UTC()).Delete(&Session{}).Error
+		if err != nil {
+			return err
+		}
+
+		result := gorm.WithResult()
+		return gorm.G[Session](tx, result).Create(ctx, session)
+	})
+}
+
+func (repository authRepository) FindSessionByID(ctx context.Context, sessionID uuid.UUID) (Session, error) {
+	session, err := gorm.G[Session](repository.db).Where("id = ?", sessionID).First(ctx)
+	return session, err
+}
+
+// FindSessionsByUserID lists the sessions of the user that haven't expired, the most recently used first
+func (repository authRepository) FindSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]Session, error) {
+	return gorm.G[Session](repository.db).
+		Where("user_id = ? AND expired_at > ?", userID, time.Now().UTC()).
+		Order("last_used_at DESC, id").
+		Find(ctx)
+}
+
+// UpdateSession saves the client and the times of the session, which change whenever its refresh token is rotated
+func (repository authRepository) UpdateSession(ctx context.Context, session *Session) error {
+	return repository.db.WithContext(ctx).Model(session).
+		Select("user_agent", "ip_address", "last_used_at", "access_token_id", "expired_at").
+		Updates(session).Error
+}
+
+// DeleteSession revokes the session together with its refresh tokens, bypassing soft delete since a revoked session is never restored
+func (repository authRepository) DeleteSession(ctx context.Context, sessionID uuid.UUID) (int, error) {
+	rowsAffected := 0
+	err := repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("family_id = ?", sessionID).Delete(&RefreshToken{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id = ?", sessionID).Delete(&Session{})
+		rowsAffected = int(result.RowsAffected)
+		return result.Error
+	})
 	return rowsAffected, err
 }
+
+// DeleteSessionsByUserID revokes every session of the user together with their refresh tokens
+func (repository authRepository) DeleteSessionsByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	rowsAffected := 0
+	err := repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&RefreshToken{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("user_id = ?", userID).Delete(&Session{})
+		rowsAffected = int(result.RowsAffected)
+		return result.Error
+	})
+	return rowsAffected, err
+}
+
+// PurgeUsersDeletedBefore permanently deletes the users soft deleted before the given time
+// Their todos, sessions and refresh tokens go with them through the ON DELETE CASCADE foreign keys
+func (repository authRepository) PurgeUsersDeletedBefore(ctx context.Context, before time.Time) (int, error) {
+	result := repository.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before.UTC()).
+		Delete(&User{})
+	return int(result.RowsAffected), result.Error
+}
@@ -1,28 +1,27 @@
 package auth
 
 import (
-	"github.com/Alfian57/golang-todo/pkg/config"
-	"github.com/Alfian57/golang-todo/pkg/logger"
 	"github.com/Alfian57/golang-todo/pkg/middleware"
-	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/Alfian57/golang-todo/pkg/module"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB, cfg *config.Config, log logger.Logger) {
-	jwtUtils := utils.NewJWTUtils(cfg)
-	isDebug := cfg.App.Mode != "release"
-
-	authRepository := NewAuthRepository(db)
-	authService := NewAuthService(authRepository, jwtUtils, log, isDebug)
-	authHandler := NewAuthHandler(authService, log)
+func RegisterRoutes(router *gin.RouterGroup, deps *module.Dependencies) {
+	authRepository := NewAuthRepository(deps.DB)
+	authService := NewAuthService(authRepository, deps.JWTUtils, deps.Denylist, deps.Log, deps.IsDebug)
+	authHandler := NewAuthHandler(authService, deps.Log)
+	authenticate := middleware.AuthMiddleware(deps.JWTUtils, deps.TokenValidator, deps.IsDebug)
 
 	authGroup := router.Group("/auth")
 	{
 		authGroup.POST("/login", authHandler.Login)
 		authGroup.POST("/register", authHandler.Register)
-		authGroup.POST("/logout", middleware.AuthMiddleware(jwtUtils, isDebug), authHandler.Logout)
+		authGroup.POST("/logout", authenticate, authHandler.Logout)
+		authGroup.POST("/logout-all", authenticate, authHandler.LogoutAll)
+		authGroup.POST("/change-password", authenticate, authHandler.ChangePassword)
 		authGroup.POST("/refresh-token", authHandler.RefreshToken)
-		authGroup.GET("/me", middleware.AuthMiddleware(jwtUtils, isDebug), authHandler.Me)
+		authGroup.GET("/me", authenticate, authHandler.Me)
+		authGroup.GET("/sessions", authenticate, authHandler.GetSessions)
+		authGroup.DELETE("/sessions/:id", authenticate, authHandler.RevokeSession)
 	}
 }
@@ -2,31 +2,36 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/denylist"
 	"github.com/Alfian57/golang-todo/pkg/logger"
 	"github.com/Alfian57/golang-todo/pkg/utils"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AuthService struct {
 	authRepository AuthRepository
 	jwtUtils       *utils.JWTUtils
+	denylist       denylist.Denylist
 	log            logger.Logger
 	isDebug        bool
 }
 
-func NewAuthService(authRepository AuthRepository, jwtUtils *utils.JWTUtils, log logger.Logger, isDebug bool) AuthService {
+func NewAuthService(authRepository AuthRepository, jwtUtils *utils.JWTUtils, denylist denylist.Denylist, log logger.Logger, isDebug bool) AuthService {
 	return AuthService{
 		authRepository: authRepository,
 		jwtUtils:       jwtUtils,
+		denylist:       denylist,
 		log:            log,
 		isDebug:        isDebug,
 	}
 }
 
-func (service AuthService) Login(ctx context.Context, req LoginRequest) models.Response {
+func (service AuthService) Login(ctx context.Context, req LoginRequest, client ClientInfo) models.Response {
 	user, err := service.authRepository.FindUserByUsername(ctx, req.Username)
 	if err != nil {
 		service.log.Debug("User not found during login",
@@ -42,7 +47,10 @@ func (service AuthService) Login(ctx context.Context, req LoginRequest) models.R
 		return utils.UnauthorizedResponse("Username or password wrong", nil, service.isDebug)
 	}
 
-	accessToken, err := service.jwtUtils.CreateJWT(user.ID.String())
+	// Every login starts a new session, whose ID is the family of its refresh tokens
+	sessionID := uuid.New()
+	claims := service.jwtUtils.NewClaims(user.ID.String(), sessionID.String())
+	accessToken, err := service.jwtUtils.SignJWT(claims)
 	if err != nil {
 		service.log.Error("Failed to create access token",
 			logger.F("operation", "Create access token"),
@@ -52,24 +60,31 @@ func (service AuthService) Login(ctx context.Context, req LoginRequest) models.R
 		return utils.InternalServerErrorResponse("Failed to create access token", err, service.isDebug)
 	}
 
-	refreshToken, err := utils.CreateRefreshToken()
+	// The access token, the refresh token and the session expire together
+	expiredAt := claims.ExpiresAt.Time
+	session := Session{
+		Base:          models.Base{ID: sessionID},
+		UserID:        user.ID,
+		DeviceName:    req.DeviceName,
+		UserAgent:     client.UserAgent,
+		IPAddress:     client.IPAddress,
+		LastUsedAt:    time.Now(),
+		AccessTokenID: claims.ID,
+		ExpiredAt:     expiredAt,
+	}
+	err = service.authRepository.CreateSession(ctx, &session)
 	if err != nil {
-		service.log.Error("Failed to create refresh token",
-			logger.F("operation", "Create refresh token"),
+		service.log.Error("Failed to create session",
+			logger.F("operation", "Create session"),
 			logger.F("user_id", user.ID.String()),
 			logger.F("error", err),
 		)
-		return utils.InternalServerErrorResponse("Failed to create refresh token", err, service.isDebug)
+		return utils.InternalServerErrorResponse("Failed to create session", err, service.isDebug)
 	}
 
-	_, err = service.authRepository.CreateRefreshToken(ctx, refreshToken, user.ID, service.jwtUtils.GetJWTTTL())
+	refreshToken, err := service.issueRefreshToken(ctx, user.ID, session.ID, expiredAt)
 	if err != nil {
-		service.log.Error("Failed to save refresh token",
-			logger.F("operation", "Save refresh token"),
-			logger.F("user_id", user.ID.String()),
-			logger.F("error", err),
-		)
-		return utils.InternalServerErrorResponse("Failed to save refresh token", err, service.isDebug)
+		return utils.InternalServerErrorResponse("Failed to create refresh token", err, service.isDebug)
 	}
 
 	responseData := LoginResponse{
@@ -108,6 +123,13 @@ func (service AuthService) Register(ctx context.Context, req RegisterRequest) mo
 		Password: hashedPassword,
 	}
 	err = service.authRepository.CreateUser(ctx, &newUser)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// A soft deleted user keeps the username until it is purged
+		service.log.Debug("Username already exists during registration",
+			logger.F("username", req.Username),
+		)
+		return utils.UnprocessableEntityResponse("Username already exists", nil, service.isDebug)
+	}
 	if err != nil {
 		service.log.Error("Failed to create user",
 			logger.F("operation", "Create user"),
@@ -127,26 +149,90 @@ func (service AuthService) Register(ctx context.Context, req RegisterRequest) mo
 	return utils.CreatedResponse("Success to register", responseData)
 }
 
-func (service AuthService) Logout(ctx context.Context, token string) models.Response {
-	rowsAffected, err := service.authRepository.DeleteRefreshTokenByToken(ctx, token)
+// Logout revokes the session of the refresh token, signing out every token rotated from the same login
+// The access token the request was made with is denylisted as well
+func (service AuthService) Logout(ctx context.Context, token string, accessToken *utils.Claims) models.Response {
+	existingRefreshToken, err := service.authRepository.FindRefreshTokenByTokenHash(ctx, utils.HashRefreshToken(token))
 	if err != nil {
-		service.log.Error("Failed to delete refresh token during logout",
-			logger.F("operation", "Logout - delete refresh token"),
-			logger.F("refresh_token", token),
+		service.log.Debug("Refresh token not found during logout",
 			logger.F("error", err),
 		)
-		return utils.InternalServerErrorResponse("Failed to logout", err, service.isDebug)
+		return utils.NotFoundResponse("Refresh token not exist", nil, service.isDebug)
 	}
-	if rowsAffected == 0 {
-		service.log.Debug("Refresh token not found during logout",
-			logger.F("refresh_token", token),
+	if existingRefreshToken.RotatedAt != nil {
+		service.logReuse(existingRefreshToken, "Logout")
+	}
+
+	err = service.revokeAccessToken(ctx, accessToken)
+	if err == nil {
+		err = service.revokeSession(ctx, existingRefreshToken.FamilyID)
+	}
+	if err != nil {
+		service.log.Error("Failed to revoke session during logout",
+			logger.F("operation", "Logout - revoke session"),
+			logger.F("user_id", existingRefreshToken.UserID.String()),
+			logger.F("family_id", existingRefreshToken.FamilyID.String()),
+			logger.F("error", err),
 		)
-		return utils.NotFoundResponse("Refresh token not exist", nil, service.isDebug)
+		return utils.InternalServerErrorResponse("Failed to logout", err, service.isDebug)
 	}
 
 	return utils.OkResponse("Success to logout", nil)
 }
 
+// ChangePassword replaces the password of the user after checking the current one
+// Every session of the user is signed out, including the one of the request, so the new password has to be used to log in again
+func (service AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, req ChangePasswordRequest, accessToken *utils.Claims) models.Response {
+	user, err := service.authRepository.FindUserByID(ctx, userID)
+	if err != nil {
+		service.log.Debug("User not found during change password",
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("User not found", err, service.isDebug)
+	}
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		service.log.Debug("Invalid password attempt during change password",
+			logger.F("user_id", userID.String()),
+		)
+		return utils.UnprocessableEntityResponse("Current password wrong", nil, service.isDebug)
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		service.log.Error("Failed to hash password",
+			logger.F("operation", "Hash password"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to hash password", err, service.isDebug)
+	}
+	err = service.authRepository.UpdateUserPassword(ctx, userID, hashedPassword)
+	if err != nil {
+		service.log.Error("Failed to update password",
+			logger.F("operation", "Change password"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to change password", err, service.isDebug)
+	}
+
+	err = service.revokeAccessToken(ctx, accessToken)
+	if err == nil {
+		_, err = service.revokeAllSessions(ctx, userID)
+	}
+	if err != nil {
+		service.log.Error("Failed to revoke sessions after password change",
+			logger.F("operation", "Change password - revoke sessions"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to change password", err, service.isDebug)
+	}
+
+	return utils.OkResponse("Success to change password", nil)
+}
+
 func (service AuthService) GetUserByID(ctx context.Context, userID uuid.UUID) models.Response {
 	user, err := service.authRepository.FindUserByID(ctx, userID)
 	if err != nil {
@@ -165,49 +251,55 @@ func (service AuthService) GetUserByID(ctx context.Context, userID uuid.UUID) mo
 	return utils.OkResponse("Success to get user", responseData)
 }
 
-func (service AuthService) RefreshToken(ctx context.Context, token string) models.Response {
+// RefreshToken rotates the refresh token into a new one of the same family and records the use on its session
+// Presenting a token that was already rotated means it leaked, so the whole family is revoked
+func (service AuthService) RefreshToken(ctx context.Context, token string, client ClientInfo) models.Response {
 	// Find refresh token in database
-	existingRefreshToken, err := service.authRepository.FindRefreshTokenByToken(ctx, token)
+	existingRefreshToken, err := service.authRepository.FindRefreshTokenByTokenHash(ctx, utils.HashRefreshToken(token))
 	if err != nil {
 		service.log.Debug("Refresh token not found during refresh token",
-			logger.F("refresh_token", token),
 			logger.F("error", err),
 		)
 		return utils.NotFoundResponse("Refresh token not exist or already used", nil, service.isDebug)
 	}
+	if existingRefreshToken.RotatedAt != nil {
+		return service.revokeReusedFamily(ctx, existingRefreshToken)
+	}
 
 	// Check if token is expired
 	if time.Now().After(existingRefreshToken.ExpiredAt) {
 		service.log.Debug("Refresh token expired",
-			logger.F("refresh_token", token),
+			logger.F("user_id", existingRefreshToken.UserID.String()),
+			logger.F("family_id", existingRefreshToken.FamilyID.String()),
 			logger.F("expired_at", existingRefreshToken.ExpiredAt),
 		)
-		// Delete expired token
-		_, _ = service.authRepository.DeleteRefreshTokenByToken(ctx, token)
+		// The expired token is the newest of its family, so the session is over
+		_, _ = service.authRepository.DeleteSession(ctx, existingRefreshToken.FamilyID)
 		return utils.UnauthorizedResponse("Refresh token expired", nil, service.isDebug)
 	}
 
 	userID := existingRefreshToken.UserID
+	sessionID := existingRefreshToken.FamilyID
 
-	// Delete old refresh token
-	rowsAffected, err := service.authRepository.DeleteRefreshTokenByToken(ctx, token)
+	// Mark old refresh token as rotated
+	rowsAffected, err := service.authRepository.RotateRefreshToken(ctx, existingRefreshToken, time.Now())
 	if err != nil {
-		service.log.Error("Failed to delete refresh token during refresh token",
-			logger.F("operation", "Refresh Token - delete refresh token"),
-			logger.F("refresh_token", token),
+		service.log.Error("Failed to rotate refresh token during refresh token",
+			logger.F("operation", "Refresh Token - rotate refresh token"),
+			logger.F("user_id", userID.String()),
+			logger.F("family_id", existingRefreshToken.FamilyID.String()),
 			logger.F("error", err),
 		)
 		return utils.InternalServerErrorResponse("Failed to refresh token", err, service.isDebug)
 	}
 	if rowsAffected == 0 {
-		service.log.Debug("Refresh token already deleted",
-			logger.F("refresh_token", token),
-		)
-		return utils.NotFoundResponse("Refresh token not exist", nil, service.isDebug)
+		// A concurrent request rotated the same token first
+		return service.revokeReusedFamily(ctx, existingRefreshToken)
 	}
 
 	// Create new access token
-	accessToken, err := service.jwtUtils.CreateJWT(userID.String())
+	claims := service.jwtUtils.NewClaims(userID.String(), sessionID.String())
+	accessToken, err := service.jwtUtils.SignJWT(claims)
 	if err != nil {
 		service.log.Error("Failed to create access token",
 			logger.F("operation", "Create access token"),
@@ -217,31 +309,120 @@ func (service AuthService) RefreshToken(ctx context.Context, token string) model
 		return utils.InternalServerErrorResponse("Failed to create access token", err, service.isDebug)
 	}
 
-	// Create new refresh token
-	refreshToken, err := utils.CreateRefreshToken()
+	// Create new refresh token in the same family
+	expiredAt := claims.ExpiresAt.Time
+	refreshToken, err := service.issueRefreshToken(ctx, userID, sessionID, expiredAt)
+	if err != nil {
+		return utils.InternalServerErrorResponse("Failed to create refresh token", err, service.isDebug)
+	}
+
+	// The session follows the client it was last used from
+	err = service.authRepository.UpdateSession(ctx, &Session{
+		Base:          models.Base{ID: sessionID},
+		UserAgent:     client.UserAgent,
+		IPAddress:     client.IPAddress,
+		LastUsedAt:    time.Now(),
+		AccessTokenID: claims.ID,
+		ExpiredAt:     expiredAt,
+	})
+	if err != nil {
+		service.log.Error("Failed to update session",
+			logger.F("operation", "Refresh Token - update session"),
+			logger.F("user_id", userID.String()),
+			logger.F("session_id", sessionID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to refresh token", err, service.isDebug)
+	}
+
+	responseData := RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}
+	return utils.OkResponse("Success to refresh token", responseData)
+}
+
+// issueRefreshToken generates a refresh token in the family and saves its hash, the token itself is only handed to the client
+func (service AuthService) issueRefreshToken(ctx context.Context, userID uuid.UUID, familyID uuid.UUID, expiredAt time.Time) (string, error) {
+	token, err := utils.CreateRefreshToken()
 	if err != nil {
 		service.log.Error("Failed to create refresh token",
 			logger.F("operation", "Create refresh token"),
 			logger.F("user_id", userID.String()),
 			logger.F("error", err),
 		)
-		return utils.InternalServerErrorResponse("Failed to create refresh token", err, service.isDebug)
+		return "", err
 	}
 
-	// Save new refresh token
-	_, err = service.authRepository.CreateRefreshToken(ctx, refreshToken, userID, service.jwtUtils.GetJWTTTL())
+	err = service.authRepository.CreateRefreshToken(ctx, &RefreshToken{
+		TokenHash: utils.HashRefreshToken(token),
+		FamilyID:  familyID,
+		UserID:    userID,
+		ExpiredAt: expiredAt,
+	})
 	if err != nil {
 		service.log.Error("Failed to save refresh token",
 			logger.F("operation", "Save refresh token"),
 			logger.F("user_id", userID.String()),
+			logger.F("family_id", familyID.String()),
 			logger.F("error", err),
 		)
-		return utils.InternalServerErrorResponse("Failed to save refresh token", err, service.isDebug)
+		return "", err
 	}
+	return token, nil
+}
 
-	responseData := RefreshTokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+// revokeReusedFamily revokes the session after one of its rotated tokens was presented again
+// Either the client or an attacker holds a stolen token, and the two can't be told apart
+func (service AuthService) revokeReusedFamily(ctx context.Context, refreshToken RefreshToken) models.Response {
+	service.logReuse(refreshToken, "Refresh Token")
+
+	err := service.revokeSession(ctx, refreshToken.FamilyID)
+	if err != nil {
+		service.log.Error("Failed to revoke refresh token family",
+			logger.F("operation", "Refresh Token - revoke family"),
+			logger.F("user_id", refreshToken.UserID.String()),
+			logger.F("family_id", refreshToken.FamilyID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to refresh token", err, service.isDebug)
 	}
-	return utils.OkResponse("Success to refresh token", responseData)
+	return utils.UnauthorizedResponse("Refresh token already used", nil, service.isDebug)
+}
+
+// logReuse reports a rotated refresh token that was presented again, identifying it without the token itself
+func (service AuthService) logReuse(refreshToken RefreshToken, operation string) {
+	service.log.Warn("Refresh token reuse detected, revoking token family",
+		logger.F("operation", operation),
+		logger.F("event", "refresh_token_reuse"),
+		logger.F("user_id", refreshToken.UserID.String()),
+		logger.F("family_id", refreshToken.FamilyID.String()),
+		logger.F("refresh_token_id", refreshToken.ID.String()),
+		logger.F("rotated_at", *refreshToken.RotatedAt),
+	)
+}
+
+// revokeSession deletes the session with its refresh tokens and denylists its newest access token
+// Older access tokens of the session are rejected because the session is gone
+func (service AuthService) revokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	session, err := service.authRepository.FindSessionByID(ctx, sessionID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && session.AccessTokenID != "" {
+		if err := service.denylist.Add(ctx, session.AccessTokenID, session.ExpiredAt); err != nil {
+			return err
+		}
+	}
+
+	_, err = service.authRepository.DeleteSession(ctx, sessionID)
+	return err
+}
+
+// revokeAccessToken denylists the access token until it expires
+func (service AuthService) revokeAccessToken(ctx context.Context, claims *utils.Claims) error {
+	if claims == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	return service.denylist.Add(ctx, claims.ID, claims.ExpiresAt.Time)
 }
@@ -0,0 +1,470 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/Alfian57/golang-todo/pkg/denylist"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func newTestJWTUtils() *utils.JWTUtils {
+	jwtUtils, err := utils.NewJWTUtils(&config.Config{
+		App: config.AppConfig{Name: "golang-todo-test"},
+		JWT: config.JWTConfig{Secret: []byte("test-secret"), TTL: time.Hour, Algorithm: config.AlgorithmHS256},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return jwtUtils
+}
+
+var testClient = ClientInfo{UserAgent: "test-agent", IPAddress: "192.0.2.1"}
+
+func newTestService() (AuthService, *MemoryAuthRepository) {
+	repository := NewMemoryAuthRepository()
+	return NewAuthService(repository, newTestJWTUtils(), denylist.NewMemoryDenylist(), logger.NewNopLogger(), true), repository
+}
+
+// findRefreshToken looks up the stored refresh token the way the service does, by the hash of the token
+func findRefreshToken(repository *MemoryAuthRepository, token string) (RefreshToken, error) {
+	return repository.FindRefreshTokenByTokenHash(context.Background(), utils.HashRefreshToken(token))
+}
+
+// seedUser registers a user through the service and returns it
+func seedUser(t *testing.T, service AuthService, username string, password string) UserResponse {
+	t.Helper()
+
+	response := service.Register(context.Background(), RegisterRequest{
+		Username:             username,
+		Password:             password,
+		PasswordConfirmation: password,
+	})
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to seed user: %d %s", response.StatusCode, response.Message)
+	}
+	return response.Data.(RegisterResponse).User
+}
+
+func TestAuthServiceRegister(t *testing.T) {
+	tests := []struct {
+		name       string
+		username   string
+		wantStatus int
+	}{
+		{name: "registers a new user", username: "bob", wantStatus: http.StatusCreated},
+		{name: "rejects a taken username", username: "alice", wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repository := newTestService()
+			seedUser(t, service, "alice", "secret")
+
+			response := service.Register(context.Background(), RegisterRequest{
+				Username:             tt.username,
+				Password:             "password",
+				PasswordConfirmation: "password",
+			})
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+
+			user, err := repository.FindUserByUsername(context.Background(), tt.username)
+			if err != nil {
+				t.Fatalf("user not stored: %v", err)
+			}
+			if user.Password == "password" || !utils.CheckPasswordHash("password", user.Password) {
+				t.Errorf("password is not stored as a valid hash")
+			}
+		})
+	}
+}
+
+func TestAuthServiceLogin(t *testing.T) {
+	tests := []struct {
+		name       string
+		username   string
+		password   string
+		wantStatus int
+	}{
+		{name: "logs in with valid credentials", username: "alice", password: "secret", wantStatus: http.StatusOK},
+		{name: "rejects a wrong password", username: "alice", password: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "rejects an unknown user", username: "mallory", password: "secret", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repository := newTestService()
+			user := seedUser(t, service, "alice", "secret")
+
+			response := service.Login(context.Background(), LoginRequest{Username: tt.username, Password: tt.password}, testClient)
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			data := response.Data.(LoginResponse)
+			claims, err := newTestJWTUtils().ParseJWT(data.AccessToken)
+			if err != nil {
+				t.Fatalf("invalid access token: %v", err)
+			}
+			if claims.Subject != user.ID {
+				t.Errorf("subject = %s, want %s", claims.Subject, user.ID)
+			}
+			session, err := repository.FindSessionByID(context.Background(), uuid.MustParse(claims.SessionID))
+			if err != nil || session.UserAgent != testClient.UserAgent || session.IPAddress != testClient.IPAddress {
+				t.Errorf("session = %+v (%v), want one for the test client", session, err)
+			}
+			refreshToken, err := findRefreshToken(repository, data.RefreshToken)
+			if err != nil {
+				t.Fatalf("refresh token not stored: %v", err)
+			}
+			if refreshToken.TokenHash == data.RefreshToken {
+				t.Errorf("refresh token is stored in plain text")
+			}
+		})
+	}
+}
+
+func TestAuthServiceLogout(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      func(issued string) string
+		wantStatus int
+	}{
+		{name: "revokes the refresh token", token: func(issued string) string { return issued }, wantStatus: http.StatusOK},
+		{name: "reports an unknown refresh token", token: func(string) string { return "unknown" }, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repository := newTestService()
+			seedUser(t, service, "alice", "secret")
+			login := service.Login(context.Background(), LoginRequest{Username: "alice", Password: "secret"}, testClient).Data.(LoginResponse)
+
+			response := service.Logout(context.Background(), tt.token(login.RefreshToken), nil)
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+
+			_, err := findRefreshToken(repository, login.RefreshToken)
+			if revoked := err != nil; revoked != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("refresh token revoked = %v", revoked)
+			}
+		})
+	}
+}
+
+func TestAuthServiceRefreshToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, repository *MemoryAuthRepository, userID uuid.UUID, issued string) string
+		wantStatus int
+	}{
+		{
+			name: "rotates a valid refresh token",
+			setup: func(t *testing.T, repository *MemoryAuthRepository, userID uuid.UUID, issued string) string {
+				return issued
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "rejects an unknown refresh token",
+			setup: func(t *testing.T, repository *MemoryAuthRepository, userID uuid.UUID, issued string) string {
+				return "unknown"
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "rejects an expired refresh token",
+			setup: func(t *testing.T, repository *MemoryAuthRepository, userID uuid.UUID, issued string) string {
+				err := repository.CreateRefreshToken(context.Background(), &RefreshToken{
+					TokenHash: utils.HashRefreshToken("expired"),
+					FamilyID:  uuid.New(),
+					UserID:    userID,
+					ExpiredAt: time.Now().Add(-time.Minute),
+				})
+				if err != nil {
+					t.Fatalf("failed to seed refresh token: %v", err)
+				}
+				return "expired"
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repository := newTestService()
+			user := seedUser(t, service, "alice", "secret")
+			login := service.Login(context.Background(), LoginRequest{Username: "alice", Password: "secret"}, testClient).Data.(LoginResponse)
+
+			token := tt.setup(t, repository, uuid.MustParse(user.ID), login.RefreshToken)
+			response := service.RefreshToken(context.Background(), token, testClient)
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			// The presented token is rotated into a new one of the same family
+			data := response.Data.(RefreshTokenResponse)
+			if data.RefreshToken == token {
+				t.Errorf("refresh token was not rotated")
+			}
+			old, err := findRefreshToken(repository, token)
+			if err != nil || old.RotatedAt == nil {
+				t.Errorf("old refresh token = %+v (%v), want it kept as rotated", old, err)
+			}
+			rotated, err := findRefreshToken(repository, data.RefreshToken)
+			if err != nil || rotated.FamilyID != old.FamilyID {
+				t.Errorf("new refresh token = %+v (%v), want it in family %s", rotated, err, old.FamilyID)
+			}
+		})
+	}
+}
+
+// Presenting a rotated refresh token again revokes every token of its family, following the OAuth 2.0 security BCP
+func TestAuthServiceRefreshTokenReuse(t *testing.T) {
+	ctx := context.Background()
+	service, repository := newTestService()
+	seedUser(t, service, "alice", "secret")
+	login := service.Login(ctx, LoginRequest{Username: "alice", Password: "secret"}, testClient).Data.(LoginResponse)
+	other := service.Login(ctx, LoginRequest{Username: "alice", Password: "secret"}, testClient).Data.(LoginResponse)
+
+	rotated := service.RefreshToken(ctx, login.RefreshToken, testClient).Data.(RefreshTokenResponse)
+	if response := service.RefreshToken(ctx, login.RefreshToken, testClient); response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("reused token status = %d, want %d", response.StatusCode, http.StatusUnauthorized)
+	}
+	if response := service.RefreshToken(ctx, rotated.RefreshToken, testClient); response.StatusCode != http.StatusNotFound {
+		t.Errorf("token rotated from the reused one status = %d, want %d", response.StatusCode, http.StatusNotFound)
+	}
+	if _, err := findRefreshToken(repository, login.RefreshToken); err == nil {
+		t.Errorf("reused refresh token still stored")
+	}
+
+	// Other logins of the user are separate families and stay signed in
+	if response := service.RefreshToken(ctx, other.RefreshToken, testClient); response.StatusCode != http.StatusOK {
+		t.Errorf("other login status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+}
+
+// Logging out revokes the tokens rotated from the same login as well
+func TestAuthServiceLogoutRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	service, repository := newTestService()
+	seedUser(t, service, "alice", "secret")
+	login := service.Login(ctx, LoginRequest{Username: "alice", Password: "secret"}, testClient).Data.(LoginResponse)
+	rotated := service.RefreshToken(ctx, login.RefreshToken, testClient).Data.(RefreshTokenResponse)
+
+	if response := service.Logout(ctx, rotated.RefreshToken, nil); response.StatusCode != http.StatusOK {
+		t.Fatalf("logout status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+	for _, token := range []string{login.RefreshToken, rotated.RefreshToken} {
+		if _, err := findRefreshToken(repository, token); err == nil {
+			t.Errorf("refresh token of the logged out family still stored")
+		}
+	}
+}
+
+func TestAuthServiceSessions(t *testing.T) {
+	ctx := context.Background()
+	service, repository := newTestService()
+	user := seedUser(t, service, "alice", "secret")
+	userID := uuid.MustParse(user.ID)
+	phone := service.Login(ctx, LoginRequest{Username: "alice", Password: "secret", DeviceName: "Phone"}, testClient).Data.(LoginResponse)
+	laptop := service.Login(ctx, LoginRequest{Username: "alice", Password: "secret", DeviceName: "Laptop"}, testClient).Data.(LoginResponse)
+	sessionOf := func(accessToken string) uuid.UUID {
+		t.Helper()
+		claims, err := newTestJWTUtils().ParseJWT(accessToken)
+		if err != nil {
+			t.Fatalf("invalid access token: %v", err)
+		}
+		return uuid.MustParse(claims.SessionID)
+	}
+	validator := NewAccessTokenValidator(repository, service.denylist)
+
+	// Refreshing moves the session to the client it was used from
+	refreshed := service.RefreshToken(ctx, phone.RefreshToken, ClientInfo{UserAgent: "other-agent", IPAddress: "192.0.2.2"}).Data.(RefreshTokenResponse)
+	if sessionOf(refreshed.AccessToken) != sessionOf(phone.AccessToken) {
+		t.Errorf("refreshed access token belongs to another session")
+	}
+
+	sessions := service.GetSessions(ctx, userID, sessionOf(laptop.AccessToken)).Data.([]SessionResponse)
+	if len(sessions) != 2 {
+		t.Fatalf("sessions = %+v, want 2", sessions)
+	}
+	for _, session := range sessions {
+		if current := session.DeviceName == "Laptop"; session.Current != current {
+			t.Errorf("session %s current = %v, want %v", session.DeviceName, session.Current, current)
+		}
+		if session.DeviceName == "Phone" && session.IPAddress != "192.0.2.2" {
+			t.Errorf("refreshed session ip = %s, want 192.0.2.2", session.IPAddress)
+		}
+	}
+
+	// Revoking a session invalidates its tokens, but leaves the other session alone
+	if response := service.RevokeSession(ctx, sessionOf(phone.AccessToken), uuid.New()); response.StatusCode != http.StatusNotFound {
+		t.Errorf("revoke by another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+	}
+	if response := service.RevokeSession(ctx, sessionOf(phone.AccessToken), userID); response.StatusCode != http.StatusOK {
+		t.Fatalf("revoke status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+	claims, _ := newTestJWTUtils().ParseJWT(refreshed.AccessToken)
+	if err := validator.ValidateToken(ctx, claims); err == nil {
+		t.Errorf("access token of the revoked session is still valid")
+	}
+	if response := service.RefreshToken(ctx, refreshed.RefreshToken, testClient); response.StatusCode != http.StatusNotFound {
+		t.Errorf("refresh of the revoked session status = %d, want %d", response.StatusCode, http.StatusNotFound)
+	}
+	claims, _ = newTestJWTUtils().ParseJWT(laptop.AccessToken)
+	if err := validator.ValidateToken(ctx, claims); err != nil {
+		t.Errorf("access token of the other session = %v, want valid", err)
+	}
+
+	// Logging out everywhere ends the remaining sessions
+	response := service.LogoutAll(ctx, userID)
+	if response.StatusCode != http.StatusOK || response.Data.(LogoutAllResponse).RevokedSessions != 1 {
+		t.Fatalf("logout all = %d %+v, want 1 revoked session", response.StatusCode, response.Data)
+	}
+	if err := validator.ValidateToken(ctx, claims); err == nil {
+		t.Errorf("access token is still valid after logging out everywhere")
+	}
+	if sessions := service.GetSessions(ctx, userID, uuid.Nil).Data.([]SessionResponse); len(sessions) != 0 {
+		t.Errorf("sessions after logging out everywhere = %+v, want none", sessions)
+	}
+}
+
+// Logging out denylists the access token of the request, which is rejected before its session is even looked up
+func TestAuthServiceLogoutRevokesAccessToken(t *testing.T) {
+	ctx := context.Background()
+	service, repository := newTestService()
+	seedUser(t, service, "alice", "secret")
+	login := service.Login(ctx, LoginRequest{Username: "alice", Password: "secret"}, testClient).Data.(LoginResponse)
+	claims, err := newTestJWTUtils().ParseJWT(login.AccessToken)
+	if err != nil {
+		t.Fatalf("invalid access token: %v", err)
+	}
+	if claims.ID == "" {
+		t.Fatalf("access token has no jti")
+	}
+
+	if response := service.Logout(ctx, login.RefreshToken, claims); response.StatusCode != http.StatusOK {
+		t.Fatalf("logout status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+	if revoked, _ := service.denylist.Contains(ctx, claims.ID); !revoked {
+		t.Errorf("access token is not denylisted after logout")
+	}
+	if err := NewAccessTokenValidator(repository, service.denylist).ValidateToken(ctx, claims); err != ErrTokenRevoked {
+		t.Errorf("validate logged out access token = %v, want %v", err, ErrTokenRevoked)
+	}
+}
+
+func TestAuthServiceChangePassword(t *testing.T) {
+	ctx := context.Background()
+	service, repository := newTestService()
+	user := seedUser(t, service, "alice", "secret")
+	userID := uuid.MustParse(user.ID)
+	current := service.Login(ctx, LoginRequest{Username: "alice", Password: "secret"}, testClient).Data.(LoginResponse)
+	other := service.Login(ctx, LoginRequest{Username: "alice", Password: "secret"}, testClient).Data.(LoginResponse)
+	currentClaims, _ := newTestJWTUtils().ParseJWT(current.AccessToken)
+	otherClaims, _ := newTestJWTUtils().ParseJWT(other.AccessToken)
+
+	req := ChangePasswordRequest{CurrentPassword: "wrong", Password: "new-secret", PasswordConfirmation: "new-secret"}
+	if response := service.ChangePassword(ctx, userID, req, currentClaims); response.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("wrong current password status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+	}
+
+	req.CurrentPassword = "secret"
+	if response := service.ChangePassword(ctx, userID, req, currentClaims); response.StatusCode != http.StatusOK {
+		t.Fatalf("change password status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+	if response := service.Login(ctx, LoginRequest{Username: "alice", Password: "secret"}, testClient); response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("login with the old password status = %d, want %d", response.StatusCode, http.StatusUnauthorized)
+	}
+
+	// Every session is signed out and the newest access token of each is denylisted
+	for _, claims := range []*utils.Claims{currentClaims, otherClaims} {
+		if revoked, _ := service.denylist.Contains(ctx, claims.ID); !revoked {
+			t.Errorf("access token of session %s is not denylisted", claims.SessionID)
+		}
+	}
+	if response := service.RefreshToken(ctx, other.RefreshToken, testClient); response.StatusCode != http.StatusNotFound {
+		t.Errorf("refresh after password change status = %d, want %d", response.StatusCode, http.StatusNotFound)
+	}
+	if sessions, _ := repository.FindSessionsByUserID(ctx, userID); len(sessions) != 0 {
+		t.Errorf("sessions after password change = %+v, want none", sessions)
+	}
+	if response := service.Login(ctx, LoginRequest{Username: "alice", Password: "new-secret"}, testClient); response.StatusCode != http.StatusOK {
+		t.Errorf("login with the new password status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+}
+
+func TestAuthServiceGetUserByID(t *testing.T) {
+	service, _ := newTestService()
+	user := seedUser(t, service, "alice", "secret")
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		wantStatus int
+	}{
+		{name: "returns an existing user", userID: uuid.MustParse(user.ID), wantStatus: http.StatusOK},
+		{name: "reports an unknown user", userID: uuid.New(), wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := service.GetUserByID(context.Background(), tt.userID)
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// softDeleteUser marks the user as deleted the way gorm's soft delete does
+func softDeleteUser(repository *MemoryAuthRepository, userID uuid.UUID, deletedAt time.Time) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	user := repository.users[userID]
+	user.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+	repository.users[userID] = user
+}
+
+func TestAuthServiceIgnoresSoftDeletedUsers(t *testing.T) {
+	service, repository := newTestService()
+	user := seedUser(t, service, "alice", "secret")
+	softDeleteUser(repository, uuid.MustParse(user.ID), time.Now())
+
+	if response := service.Login(context.Background(), LoginRequest{Username: "alice", Password: "secret"}, testClient); response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("login status = %d, want %d", response.StatusCode, http.StatusUnauthorized)
+	}
+	if response := service.GetUserByID(context.Background(), uuid.MustParse(user.ID)); response.StatusCode != http.StatusNotFound {
+		t.Errorf("get user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+	}
+
+	// The username stays taken until the user is purged
+	response := service.Register(context.Background(), RegisterRequest{
+		Username:             "alice",
+		Password:             "password",
+		PasswordConfirmation: "password",
+	})
+	if response.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("register status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+	}
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/denylist"
+	"github.com/Alfian57/golang-todo/pkg/middleware"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSessionRevoked = errors.New("session has been revoked")
+	ErrTokenRevoked   = errors.New("access token has been revoked")
+)
+
+// Session is a login of a user, its ID is the family of the refresh tokens rotated from the login
+type Session struct {
+	models.Base
+	UserID     uuid.UUID
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+	LastUsedAt time.Time
+
+	// AccessTokenID is the newest access token of the session, which is denylisted when the session is revoked
+	// ExpiredAt follows it and the newest refresh token, which expire together
+	AccessTokenID string
+	ExpiredAt     time.Time
+}
+
+// ClientInfo describes the client a login or refresh came from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// AccessTokenValidator rejects access tokens that were denylisted, or whose session was revoked or has expired
+type AccessTokenValidator struct {
+	authRepository AuthRepository
+	denylist       denylist.Denylist
+}
+
+var _ middleware.TokenValidator = AccessTokenValidator{}
+
+func NewAccessTokenValidator(authRepository AuthRepository, denylist denylist.Denylist) AccessTokenValidator {
+	return AccessTokenValidator{
+		authRepository: authRepository,
+		denylist:       denylist,
+	}
+}
+
+func (validator AccessTokenValidator) ValidateToken(ctx context.Context, claims *utils.Claims) error {
+	if claims.ID == "" {
+		return utils.ErrInvalidTokenID
+	}
+	revoked, err := validator.denylist.Contains(ctx, claims.ID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return utils.ErrInvalidSessionID
+	}
+
+	session, err := validator.authRepository.FindSessionByID(ctx, sessionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if session.UserID.String() != claims.Subject || time.Now().After(session.ExpiredAt) {
+		return ErrSessionRevoked
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      List sessions
+// @Description  List the active sessions of the authenticated user, the most recently used first. The session of the access token is marked as current
+// @Tags         Auth
+// @Produce      json
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=[]auth.SessionResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /auth/sessions [get]
+func (handler AuthHandler) GetSessions(ctx *gin.Context) {
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get sessions"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get the session of the access token from JWT claims
+	sessionID, err := utils.GetSessionIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get session ID from context",
+			logger.F("operation", "Get sessions"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.authService.GetSessions(ctx, userID, sessionID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get sessions request failed",
+			logger.F("operation", "Get sessions"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Revoke a session
+// @Description  Sign out a session of the authenticated user. Its refresh token and outstanding access tokens stop working right away
+// @Tags         Auth
+// @Produce      json
+// @Param        id   path      string  true  "Session ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /auth/sessions/{id} [delete]
+func (handler AuthHandler) RevokeSession(ctx *gin.Context) {
+	// Get session ID from URL parameter
+	idStr := ctx.Param("id")
+	sessionID, err := uuid.Parse(idStr)
+	if err != nil {
+		handler.log.Warn("Invalid session ID",
+			logger.F("operation", "Revoke session"),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid session ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Revoke session"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.authService.RevokeSession(ctx, sessionID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Revoke session request failed",
+			logger.F("operation", "Revoke session"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("session_id", sessionID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Logout everywhere
+// @Description  Sign out every session of the authenticated user, including the current one
+// @Tags         Auth
+// @Produce      json
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=auth.LogoutAllResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /auth/logout-all [post]
+func (handler AuthHandler) LogoutAll(ctx *gin.Context) {
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Logout all"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.authService.LogoutAll(ctx, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Logout all request failed",
+			logger.F("operation", "Logout all"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
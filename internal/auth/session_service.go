@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetSessions lists the active sessions of the user, the most recently used first
+// The session the request was made from is marked as current
+func (service AuthService) GetSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) models.Response {
+	sessions, err := service.authRepository.FindSessionsByUserID(ctx, userID)
+	if err != nil {
+		service.log.Error("Failed to get sessions",
+			logger.F("operation", "Get sessions"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get sessions", err, service.isDebug)
+	}
+
+	responseData := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responseData = append(responseData, SessionResponse{
+			ID:         session.ID.String(),
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			Current:    session.ID == currentSessionID,
+			CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+			LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+		})
+	}
+	return utils.OkResponse("Success to get sessions", responseData)
+}
+
+// RevokeSession signs out a session of the user, its refresh tokens and outstanding access tokens stop working right away
+func (service AuthService) RevokeSession(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) models.Response {
+	session, err := service.authRepository.FindSessionByID(ctx, sessionID)
+	if err == nil && session.UserID != userID {
+		err = gorm.ErrRecordNotFound
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		service.log.Debug("Session not found during revoke",
+			logger.F("session_id", sessionID.String()),
+			logger.F("user_id", userID.String()),
+		)
+		return utils.NotFoundResponse("Session not found", err, service.isDebug)
+	}
+	if err != nil {
+		service.log.Error("Failed to find session",
+			logger.F("operation", "Revoke session"),
+			logger.F("session_id", sessionID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to revoke session", err, service.isDebug)
+	}
+
+	err = service.revokeSession(ctx, sessionID)
+	if err != nil {
+		service.log.Error("Failed to revoke session",
+			logger.F("operation", "Revoke session"),
+			logger.F("session_id", sessionID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to revoke session", err, service.isDebug)
+	}
+
+	return utils.OkResponse("Success to revoke session", nil)
+}
+
+// LogoutAll signs out every session of the user, including the one the request was made from
+func (service AuthService) LogoutAll(ctx context.Context, userID uuid.UUID) models.Response {
+	revoked, err := service.revokeAllSessions(ctx, userID)
+	if err != nil {
+		service.log.Error("Failed to revoke sessions",
+			logger.F("operation", "Logout all"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to logout", err, service.isDebug)
+	}
+
+	responseData := LogoutAllResponse{
+		RevokedSessions: revoked,
+	}
+	return utils.OkResponse("Success to logout from all sessions", responseData)
+}
+
+// revokeAllSessions deletes every session of the user and denylists their newest access tokens
+func (service AuthService) revokeAllSessions(ctx context.Context, userID uuid.UUID) (int, error) {
+	sessions, err := service.authRepository.FindSessionsByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for _, session := range sessions {
+		if session.AccessTokenID == "" {
+			continue
+		}
+		if err := service.denylist.Add(ctx, session.AccessTokenID, session.ExpiredAt); err != nil {
+			return 0, err
+		}
+	}
+
+	return service.authRepository.DeleteSessionsByUserID(ctx, userID)
+}
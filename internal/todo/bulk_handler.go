@@ -0,0 +1,50 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Bulk todo operations
+// @Description  Run a list of create, update, complete, delete and move operations on the authenticated user's todos in one transaction and report the result of each. In atomic mode (the default) a failed operation rolls back all of them and the request fails with 422, in best_effort mode only the failed operations are left out. The operations that were applied can be reverted together with the undo token of the response
+// @Tags         Todo
+// @Accept       json
+// @Produce      json
+// @Param        body  body      BulkTodoRequest  true  "Bulk Todo Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.BulkTodoResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response{data=todo.BulkTodoResponse}
+// @Failure      500  {object}  models.Response
+// @Router       /todo/bulk [post]
+func (handler TodoHandler) Bulk(ctx *gin.Context) {
+	var req BulkTodoRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Bulk todos"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.Bulk(ctx, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Bulk todos request failed",
+			logger.F("operation", "Bulk todos"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
@@ -0,0 +1,165 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/jsonpatch"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// Modes of a bulk request
+const (
+	BulkAtomic     = "atomic"
+	BulkBestEffort = "best_effort"
+)
+
+// errBulkOperationFailed rolls back the changes of an operation whose response is an error
+var errBulkOperationFailed = errors.New("bulk operation failed")
+
+// Bulk runs a list of operations on the todos of a user in one transaction, reporting the result of each
+// In atomic mode the first failed operation rolls back the whole list, in best effort mode only its own changes
+// The operations go through the same service methods as their endpoints, so every ID is checked against the user
+// The operations that were applied can be undone together with a single undo token
+func (service TodoService) Bulk(ctx context.Context, req BulkTodoRequest, userID uuid.UUID) models.Response {
+	return service.undoable(ctx, userID, "Bulk todos", func(service TodoService) models.Response {
+		return service.bulk(ctx, req, userID)
+	})
+}
+
+func (service TodoService) bulk(ctx context.Context, req BulkTodoRequest, userID uuid.UUID) models.Response {
+	mode := req.Mode
+	if mode == "" {
+		mode = BulkAtomic
+	}
+
+	results := make([]BulkTodoResult, 0, len(req.Operations))
+	err := service.todoRepository.Transaction(ctx, func(repository TodoRepository) error {
+		for i, operation := range req.Operations {
+			var response models.Response
+			// Each operation runs in its own savepoint, so a failed one leaves nothing half done
+			err := repository.Transaction(ctx, func(repository TodoRepository) error {
+				operationService := service
+				operationService.todoRepository = repository
+				operationService.undo = service.undo.scratch()
+				response = operationService.bulkOperation(ctx, operation, userID)
+				if response.StatusCode >= http.StatusMultipleChoices {
+					return errBulkOperationFailed
+				}
+				service.undo.merge(operationService.undo)
+				return nil
+			})
+			if err != nil && !errors.Is(err, errBulkOperationFailed) {
+				return err
+			}
+			results = append(results, newBulkTodoResult(i, operation, response))
+			if err != nil && mode == BulkAtomic {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkOperationFailed) {
+		service.log.Error("Failed to run bulk operations",
+			logger.F("operation", "Bulk todos"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to run bulk operations", err, service.isDebug)
+	}
+
+	responseData := BulkTodoResponse{
+		Mode:      mode,
+		Committed: err == nil,
+		Results:   results,
+	}
+	if !responseData.Committed {
+		// Nothing was kept, so the operations that succeeded are reported as rolled back and the rest as never run
+		for i := range responseData.Results[:len(responseData.Results)-1] {
+			responseData.Results[i].StatusCode = http.StatusFailedDependency
+			responseData.Results[i].Message = "Rolled back"
+			responseData.Results[i].Todo = nil
+		}
+		for i := len(responseData.Results); i < len(req.Operations); i++ {
+			responseData.Results = append(responseData.Results, BulkTodoResult{
+				Index:      i,
+				Op:         req.Operations[i].Op,
+				ID:         req.Operations[i].ID,
+				StatusCode: http.StatusFailedDependency,
+				Message:    "Not executed",
+			})
+		}
+	}
+	for _, result := range responseData.Results {
+		if result.StatusCode < http.StatusMultipleChoices {
+			responseData.Succeeded++
+		} else {
+			responseData.Failed++
+		}
+	}
+
+	if !responseData.Committed {
+		return utils.SuccessResponse(http.StatusUnprocessableEntity, "Bulk operations rolled back", responseData)
+	}
+	return utils.OkResponse("Bulk operations applied", responseData)
+}
+
+func (service TodoService) bulkOperation(ctx context.Context, operation BulkTodoOperation, userID uuid.UUID) models.Response {
+	switch operation.Op {
+	case "create":
+		return service.Create(ctx, *operation.Create, userID)
+	case "update":
+		patch, err := jsonpatch.ParseMergePatch(operation.Fields)
+		if err != nil {
+			return utils.UnprocessableEntityResponse("Invalid patch", err, service.isDebug)
+		}
+		return service.Patch(ctx, *operation.ID, patch, operation.IfMatch, userID)
+	case "complete":
+		patch, err := jsonpatch.ParseMergePatch([]byte(`{"completed": true}`))
+		if err != nil {
+			return utils.InternalServerErrorResponse("Failed to complete todo", err, service.isDebug)
+		}
+		return service.Patch(ctx, *operation.ID, patch, operation.IfMatch, userID)
+	case "delete":
+		return service.Delete(ctx, *operation.ID, DeleteTodoRequest{Subtasks: operation.Subtasks, IfMatch: operation.IfMatch}, userID)
+	case "move":
+		// Moving has no If-Match of its own, a todo that isn't found is left for MoveTodo to report
+		if operation.IfMatch != "" {
+			todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, *operation.ID, userID)
+			if err == nil && !ifMatches(todo, operation.IfMatch) {
+				return utils.PreconditionFailedResponse("Todo has been changed", ErrVersionConflict, service.isDebug)
+			}
+		}
+		return service.MoveTodo(ctx, *operation.ID, MoveTodoRequest{ProjectID: operation.ProjectID}, userID)
+	}
+	return utils.UnprocessableEntityResponse("Unknown operation", nil, service.isDebug)
+}
+
+func newBulkTodoResult(index int, operation BulkTodoOperation, response models.Response) BulkTodoResult {
+	result := BulkTodoResult{
+		Index:      index,
+		Op:         operation.Op,
+		ID:         operation.ID,
+		StatusCode: response.StatusCode,
+		Message:    response.Message,
+	}
+
+	var todo TodoResponse
+	switch data := response.Data.(type) {
+	case CreateTodoResponse:
+		todo = data.Todo
+	case UpdateTodoResponse:
+		todo = data.Todo
+	case MoveTodoResponse:
+		todo = data.Todo
+	default:
+		return result
+	}
+	result.ID = &todo.Id
+	result.Todo = &todo
+	return result
+}
@@ -0,0 +1,105 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func bulkStatuses(response BulkTodoResponse) []int {
+	statuses := make([]int, 0, len(response.Results))
+	for _, result := range response.Results {
+		statuses = append(statuses, result.StatusCode)
+	}
+	return statuses
+}
+
+func TestTodoServiceBulk(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			work := createProject(t, service, userID, "Work")
+			todos := seedTodos(t, repository, userID, "Walk dog", "Fix sink", "Call boss")
+			walk, fix, call := todos[0], todos[1], todos[2]
+			foreign := seedTodos(t, repository, otherUserID, "Their todo")[0]
+
+			bulk := func(req BulkTodoRequest) (BulkTodoResponse, int) {
+				t.Helper()
+				response := service.Bulk(ctx, req, userID)
+				data, _ := response.Data.(BulkTodoResponse)
+				return data, response.StatusCode
+			}
+
+			result, status := bulk(BulkTodoRequest{Operations: []BulkTodoOperation{
+				{Op: "create", Create: &CreateTodoRequest{Title: "Buy milk"}},
+				{Op: "update", ID: &walk.ID, Fields: []byte(`{"title": "Walk the dog", "priority": "P0"}`)},
+				{Op: "complete", ID: &call.ID},
+				{Op: "move", ID: &fix.ID, ProjectID: &work},
+				{Op: "delete", ID: &walk.ID, IfMatch: `"1"`},
+			}})
+			if status != http.StatusUnprocessableEntity || result.Committed {
+				t.Fatalf("stale If-Match status = %d committed %v, want %d rolled back", status, result.Committed, http.StatusUnprocessableEntity)
+			}
+			want := []int{http.StatusFailedDependency, http.StatusFailedDependency, http.StatusFailedDependency, http.StatusFailedDependency, http.StatusPreconditionFailed}
+			if got := bulkStatuses(result); !slices.Equal(got, want) {
+				t.Errorf("rolled back statuses = %v, want %v", got, want)
+			}
+			if result.Succeeded != 0 || result.Failed != 5 {
+				t.Errorf("succeeded %d failed %d, want 0 and 5", result.Succeeded, result.Failed)
+			}
+			if todo, err := repository.FindTodoByIDAndUserID(ctx, walk.ID, userID); err != nil || todo.Title != "Walk dog" {
+				t.Errorf("rolled back todo = %+v (%v), want the title unchanged", todo, err)
+			}
+			if todo, err := repository.FindTodoByIDAndUserID(ctx, fix.ID, userID); err != nil || todo.ProjectID != nil {
+				t.Errorf("rolled back move = %+v (%v), want the todo in the inbox", todo, err)
+			}
+
+			result, status = bulk(BulkTodoRequest{Mode: BulkBestEffort, Operations: []BulkTodoOperation{
+				{Op: "create", Create: &CreateTodoRequest{Title: "Buy milk"}},
+				{Op: "complete", ID: &foreign.ID},
+				{Op: "update", ID: &walk.ID, Fields: []byte(`{"title": "Walk the dog", "priority": "P0"}`)},
+				{Op: "update", ID: &walk.ID, Fields: []byte(`{"title": ""}`)},
+				{Op: "complete", ID: &call.ID},
+				{Op: "move", ID: &fix.ID, ProjectID: &work},
+				{Op: "delete", ID: &fix.ID},
+			}})
+			if status != http.StatusOK || !result.Committed {
+				t.Fatalf("best effort status = %d committed %v, want %d committed", status, result.Committed, http.StatusOK)
+			}
+			want = []int{http.StatusCreated, http.StatusNotFound, http.StatusOK, http.StatusUnprocessableEntity, http.StatusOK, http.StatusOK, http.StatusOK}
+			if got := bulkStatuses(result); !slices.Equal(got, want) {
+				t.Errorf("best effort statuses = %v, want %v", got, want)
+			}
+			if result.Succeeded != 5 || result.Failed != 2 {
+				t.Errorf("succeeded %d failed %d, want 5 and 2", result.Succeeded, result.Failed)
+			}
+			if created := result.Results[0]; created.ID == nil || created.Todo == nil || created.Todo.Title != "Buy milk" {
+				t.Errorf("create result = %+v, want the created todo", created)
+			} else if _, err := repository.FindTodoByIDAndUserID(ctx, *created.ID, userID); err != nil {
+				t.Errorf("created todo not found: %v", err)
+			}
+
+			if todo, err := repository.FindTodoByIDAndUserID(ctx, walk.ID, userID); err != nil || todo.Title != "Walk the dog" || todo.Priority != 0 {
+				t.Errorf("updated todo = %+v (%v), want the update kept and the failed one dropped", todo, err)
+			}
+			if todo, err := repository.FindTodoByIDAndUserID(ctx, call.ID, userID); err != nil || !todo.Completed {
+				t.Errorf("completed todo = %+v (%v), want it completed", todo, err)
+			}
+			if _, err := repository.FindTodoByIDAndUserID(ctx, fix.ID, userID); err == nil {
+				t.Error("deleted todo still found")
+			}
+			if todo, err := repository.FindTodoByIDAndUserID(ctx, foreign.ID, otherUserID); err != nil || todo.Completed {
+				t.Errorf("todo of another user = %+v (%v), want it untouched", todo, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      Get checklist
+// @Description  Get the checklist of a todo of the authenticated user in its order
+// @Tags         Checklist
+// @Produce      json
+// @Param        id   path      string  true  "Todo ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetChecklistResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/checklist [get]
+func (handler TodoHandler) GetChecklist(ctx *gin.Context) {
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Get checklist"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get checklist"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetChecklist(ctx, todoID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get checklist request failed",
+			logger.F("operation", "Get checklist"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Create checklist item
+// @Description  Add an item at the end of the checklist of a todo of the authenticated user
+// @Tags         Checklist
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                      true  "Todo ID"
+// @Param        body  body      CreateChecklistItemRequest  true  "Create Checklist Item Request"
+// @Security	 BearerAuth
+// @Success      201  {object}  models.Response{data=todo.CreateChecklistItemResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/checklist [post]
+func (handler TodoHandler) CreateChecklistItem(ctx *gin.Context) {
+	var req CreateChecklistItemRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Create checklist item"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Create checklist item"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.CreateChecklistItem(ctx, todoID, req, userID)
+	if response.StatusCode != 201 {
+		handler.log.Warn("Create checklist item request failed",
+			logger.F("operation", "Create checklist item"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Reorder checklist
+// @Description  Move the given checklist items to the top of the checklist in the given order, the other items keep their order after them
+// @Tags         Checklist
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                   true  "Todo ID"
+// @Param        body  body      ReorderChecklistRequest  true  "Reorder Checklist Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetChecklistResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/checklist/order [put]
+func (handler TodoHandler) ReorderChecklist(ctx *gin.Context) {
+	var req ReorderChecklistRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Reorder checklist"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Reorder checklist"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.ReorderChecklist(ctx, todoID, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Reorder checklist request failed",
+			logger.F("operation", "Reorder checklist"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Update checklist item
+// @Description  Update the text and done state of a checklist item of a todo of the authenticated user
+// @Tags         Checklist
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                      true  "Todo ID"
+// @Param        item_id  path      string                      true  "Checklist Item ID"
+// @Param        body     body      UpdateChecklistItemRequest  true  "Update Checklist Item Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.UpdateChecklistItemResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/checklist/{item_id} [put]
+func (handler TodoHandler) UpdateChecklistItem(ctx *gin.Context) {
+	var req UpdateChecklistItemRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Update checklist item"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get checklist item ID from URL parameter
+	itemIDStr := ctx.Param("item_id")
+	itemID, err := uuid.Parse(itemIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid checklist item ID",
+			logger.F("operation", "Update checklist item"),
+			logger.F("item_id", itemIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid checklist item ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Update checklist item"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.UpdateChecklistItem(ctx, todoID, itemID, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Update checklist item request failed",
+			logger.F("operation", "Update checklist item"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("item_id", itemID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Toggle checklist item
+// @Description  Flip whether a checklist item of a todo of the authenticated user is done
+// @Tags         Checklist
+// @Produce      json
+// @Param        id       path      string  true  "Todo ID"
+// @Param        item_id  path      string  true  "Checklist Item ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.UpdateChecklistItemResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/checklist/{item_id}/toggle [post]
+func (handler TodoHandler) ToggleChecklistItem(ctx *gin.Context) {
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Toggle checklist item"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get checklist item ID from URL parameter
+	itemIDStr := ctx.Param("item_id")
+	itemID, err := uuid.Parse(itemIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid checklist item ID",
+			logger.F("operation", "Toggle checklist item"),
+			logger.F("item_id", itemIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid checklist item ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Toggle checklist item"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.ToggleChecklistItem(ctx, todoID, itemID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Toggle checklist item request failed",
+			logger.F("operation", "Toggle checklist item"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("item_id", itemID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Delete checklist item
+// @Description  Permanently delete a checklist item of a todo of the authenticated user
+// @Tags         Checklist
+// @Produce      json
+// @Param        id       path      string  true  "Todo ID"
+// @Param        item_id  path      string  true  "Checklist Item ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/checklist/{item_id} [delete]
+func (handler TodoHandler) DeleteChecklistItem(ctx *gin.Context) {
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Delete checklist item"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get checklist item ID from URL parameter
+	itemIDStr := ctx.Param("item_id")
+	itemID, err := uuid.Parse(itemIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid checklist item ID",
+			logger.F("operation", "Delete checklist item"),
+			logger.F("item_id", itemIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid checklist item ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Delete checklist item"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.DeleteChecklistItem(ctx, todoID, itemID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Delete checklist item request failed",
+			logger.F("operation", "Delete checklist item"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("item_id", itemID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
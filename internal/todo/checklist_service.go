@@ -0,0 +1,239 @@
+package todo
+
+import (
+	"context"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// checklistTodo finds the todo that owns a checklist
+// It returns the error response to send when the user has no such todo
+func (service TodoService) checklistTodo(ctx context.Context, todoID uuid.UUID, userID uuid.UUID, operation string) (models.Response, bool) {
+	_, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find todo",
+			logger.F("operation", operation),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found", err, service.isDebug), false
+	}
+	return models.Response{}, true
+}
+
+// checklistItem finds an item on the checklist of a todo of the user
+// It returns the error response to send when there is no such item
+func (service TodoService) checklistItem(ctx context.Context, todoID uuid.UUID, itemID uuid.UUID, userID uuid.UUID, operation string) (*ChecklistItem, models.Response, bool) {
+	if response, ok := service.checklistTodo(ctx, todoID, userID, operation); !ok {
+		return nil, response, false
+	}
+
+	item, err := service.todoRepository.FindChecklistItemByIDAndTodoID(ctx, itemID, todoID)
+	if err != nil {
+		service.log.Error("Failed to find checklist item",
+			logger.F("operation", operation),
+			logger.F("todo_id", todoID.String()),
+			logger.F("item_id", itemID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return nil, utils.NotFoundResponse("Checklist item not found", err, service.isDebug), false
+	}
+	return item, models.Response{}, true
+}
+
+func (service TodoService) GetChecklist(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) models.Response {
+	if response, ok := service.checklistTodo(ctx, todoID, userID, "Get checklist"); !ok {
+		return response
+	}
+
+	responseData, err := service.checklist(ctx, todoID)
+	if err != nil {
+		service.log.Error("Failed to get checklist",
+			logger.F("operation", "Get checklist"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get checklist", err, service.isDebug)
+	}
+	return utils.OkResponse("Checklist retrieved successfully", responseData)
+}
+
+func (service TodoService) checklist(ctx context.Context, todoID uuid.UUID) (GetChecklistResponse, error) {
+	items, err := service.todoRepository.FindChecklistItemsByTodoIDs(ctx, []uuid.UUID{todoID})
+	if err != nil {
+		return GetChecklistResponse{}, err
+	}
+
+	responseData := GetChecklistResponse{
+		Items: make([]ChecklistItemResponse, 0, len(items)),
+	}
+	for _, item := range items {
+		responseData.Items = append(responseData.Items, NewChecklistItemResponse(item))
+	}
+	return responseData, nil
+}
+
+// CreateChecklistItem adds an item at the end of the todo's checklist
+func (service TodoService) CreateChecklistItem(ctx context.Context, todoID uuid.UUID, req CreateChecklistItemRequest, userID uuid.UUID) models.Response {
+	if response, ok := service.checklistTodo(ctx, todoID, userID, "Create checklist item"); !ok {
+		return response
+	}
+
+	item := ChecklistItem{
+		TodoID: todoID,
+		Text:   req.Text,
+	}
+	err := service.todoRepository.CreateChecklistItem(ctx, &item)
+	if err != nil {
+		service.log.Error("Failed to create checklist item",
+			logger.F("operation", "Create checklist item"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to create checklist item", err, service.isDebug)
+	}
+
+	responseData := CreateChecklistItemResponse{
+		Item: NewChecklistItemResponse(item),
+	}
+	return utils.CreatedResponse("Success to create checklist item", responseData)
+}
+
+func (service TodoService) UpdateChecklistItem(ctx context.Context, todoID uuid.UUID, itemID uuid.UUID, req UpdateChecklistItemRequest, userID uuid.UUID) models.Response {
+	item, response, ok := service.checklistItem(ctx, todoID, itemID, userID, "Update checklist item")
+	if !ok {
+		return response
+	}
+
+	item.Text = req.Text
+	item.Done = req.Done
+	return service.saveChecklistItem(ctx, item, userID, "Update checklist item", "Checklist item updated successfully")
+}
+
+// ToggleChecklistItem flips whether the item is done
+func (service TodoService) ToggleChecklistItem(ctx context.Context, todoID uuid.UUID, itemID uuid.UUID, userID uuid.UUID) models.Response {
+	item, response, ok := service.checklistItem(ctx, todoID, itemID, userID, "Toggle checklist item")
+	if !ok {
+		return response
+	}
+
+	item.Done = !item.Done
+	return service.saveChecklistItem(ctx, item, userID, "Toggle checklist item", "Checklist item toggled successfully")
+}
+
+func (service TodoService) saveChecklistItem(ctx context.Context, item *ChecklistItem, userID uuid.UUID, operation string, message string) models.Response {
+	err := service.todoRepository.UpdateChecklistItem(ctx, item)
+	if err != nil {
+		service.log.Error("Failed to save checklist item",
+			logger.F("operation", operation),
+			logger.F("todo_id", item.TodoID.String()),
+			logger.F("item_id", item.ID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to update checklist item", err, service.isDebug)
+	}
+
+	responseData := UpdateChecklistItemResponse{
+		Item: NewChecklistItemResponse(*item),
+	}
+	return utils.OkResponse(message, responseData)
+}
+
+// DeleteChecklistItem permanently deletes the item
+func (service TodoService) DeleteChecklistItem(ctx context.Context, todoID uuid.UUID, itemID uuid.UUID, userID uuid.UUID) models.Response {
+	item, response, ok := service.checklistItem(ctx, todoID, itemID, userID, "Delete checklist item")
+	if !ok {
+		return response
+	}
+
+	err := service.todoRepository.DeleteChecklistItem(ctx, item)
+	if err != nil {
+		service.log.Error("Failed to delete checklist item",
+			logger.F("operation", "Delete checklist item"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("item_id", itemID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to delete checklist item", err, service.isDebug)
+	}
+
+	return utils.OkResponse("Checklist item deleted successfully", nil)
+}
+
+// ReorderChecklist moves the given items to the top of the checklist in the given order
+// Items that are left out keep their relative order after them
+func (service TodoService) ReorderChecklist(ctx context.Context, todoID uuid.UUID, req ReorderChecklistRequest, userID uuid.UUID) models.Response {
+	if response, ok := service.checklistTodo(ctx, todoID, userID, "Reorder checklist"); !ok {
+		return response
+	}
+
+	items, err := service.todoRepository.FindChecklistItemsByTodoIDs(ctx, []uuid.UUID{todoID})
+	if err != nil {
+		service.log.Error("Failed to get checklist",
+			logger.F("operation", "Reorder checklist"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to reorder checklist", err, service.isDebug)
+	}
+
+	byID := make(map[uuid.UUID]ChecklistItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	ordered := make([]ChecklistItem, 0, len(items))
+	placed := make(map[uuid.UUID]bool, len(req.ItemIDs))
+	for _, id := range req.ItemIDs {
+		item, exists := byID[id]
+		if !exists {
+			return utils.UnprocessableEntityResponse("Unknown checklist item", ErrUnknownChecklistItem, service.isDebug)
+		}
+		if placed[id] {
+			continue
+		}
+		placed[id] = true
+		ordered = append(ordered, item)
+	}
+	for _, item := range items {
+		if !placed[item.ID] {
+			ordered = append(ordered, item)
+		}
+	}
+	for i := range ordered {
+		ordered[i].Position = i
+	}
+
+	err = service.todoRepository.UpdateChecklistItemPositions(ctx, ordered)
+	if err != nil {
+		service.log.Error("Failed to update checklist item positions",
+			logger.F("operation", "Reorder checklist"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to reorder checklist", err, service.isDebug)
+	}
+
+	responseData, err := service.checklist(ctx, todoID)
+	if err != nil {
+		service.log.Error("Failed to get checklist",
+			logger.F("operation", "Reorder checklist"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to reorder checklist", err, service.isDebug)
+	}
+	return utils.OkResponse("Checklist reordered successfully", responseData)
+}
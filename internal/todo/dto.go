@@ -1,27 +1,171 @@
 package todo
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/google/uuid"
 )
 
 // General TodoResponse
 type TodoResponse struct {
-	Id          uuid.UUID `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   string    `json:"created_at"`
+	Id           uuid.UUID     `json:"id"`
+	Title        string        `json:"title"`
+	Description  string        `json:"description"`
+	Completed    bool          `json:"completed"`
+	CreatedAt    string        `json:"created_at"`
+	UpdatedAt    string        `json:"updated_at"`
+	DeletedAt    *string       `json:"deleted_at,omitempty"`
+	DueAt        *string       `json:"due_at"`
+	AllDay       bool          `json:"all_day"`
+	RemindAt     *string       `json:"remind_at"`
+	Priority     string        `json:"priority"`
+	Status       string        `json:"status"`
+	Tags         []TagResponse `json:"tags"`
+	ProjectID    *uuid.UUID    `json:"project_id"`
+	Position     string        `json:"position"`
+	ParentID     *uuid.UUID    `json:"parent_id"`
+	AutoComplete bool          `json:"auto_complete"`
+	Recurrence   string        `json:"recurrence"`
+	Timezone     string        `json:"timezone"`
+	SeriesID     *uuid.UUID    `json:"series_id"`
+	SkippedDates []string      `json:"skipped_dates"`
+	Version      int64         `json:"version"`
+}
+
+func NewTodoResponse(todo Todo) TodoResponse {
+	response := TodoResponse{
+		Id:           todo.ID,
+		Title:        todo.Title,
+		Description:  todo.Description,
+		Completed:    todo.Completed,
+		CreatedAt:    todo.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    todo.UpdatedAt.Format(time.RFC3339),
+		DueAt:        formatTime(todo.DueAt),
+		AllDay:       todo.AllDay,
+		RemindAt:     formatTime(todo.RemindAt),
+		Priority:     PriorityLabel(todo.Priority),
+		Status:       todo.Status,
+		Tags:         make([]TagResponse, 0, len(todo.Tags)),
+		ProjectID:    todo.ProjectID,
+		Position:     todo.Position,
+		ParentID:     todo.ParentID,
+		AutoComplete: todo.AutoComplete,
+		Recurrence:   todo.Recurrence,
+		Timezone:     todo.Timezone,
+		SeriesID:     todo.SeriesID,
+		SkippedDates: append([]string{}, todo.SkippedDates...),
+		Version:      todo.Version,
+	}
+	for _, tag := range todo.Tags {
+		response.Tags = append(response.Tags, NewTagResponse(tag))
+	}
+	if todo.DeletedAt.Valid {
+		deletedAt := todo.DeletedAt.Time.Format(time.RFC3339)
+		response.DeletedAt = &deletedAt
+	}
+	return response
+}
+
+func formatTime(value *time.Time) *string {
+	if value == nil {
+		return nil
+	}
+	formatted := value.Format(time.RFC3339)
+	return &formatted
 }
 
 // List Todos
+type GetTodosRequest struct {
+	Cursor      string     `form:"cursor"`
+	Limit       int        `form:"limit" validate:"omitempty,min=1,max=100"`
+	Sort        string     `form:"sort" validate:"omitempty,oneof=position -position created_at -created_at updated_at -updated_at title -title priority -priority status -status"`
+	Completed   *bool      `form:"completed"`
+	Title       string     `form:"title" validate:"max=255"`
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	UpdatedFrom *time.Time `form:"updated_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	UpdatedTo   *time.Time `form:"updated_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Overdue     bool       `form:"overdue"`
+	DueToday    bool       `form:"due_today"`
+	DueWithin   string     `form:"due_within" validate:"max=16"`
+	Timezone    string     `form:"tz" validate:"omitempty,timezone"`
+	Priority    []string   `form:"priority" validate:"max=4,dive,oneof=P0 P1 P2 P3"`
+	Status      []string   `form:"status" validate:"max=20,dive,max=50"`
+	Tag         []string   `form:"tag" validate:"max=20,dive,max=50"`
+	TagMode     string     `form:"tag_mode" validate:"omitempty,oneof=any all"`
+	Project     string     `form:"project" validate:"max=36"`
+}
+type PaginationMeta struct {
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+	Limit      int    `json:"limit"`
+	Total      int64  `json:"total"`
+}
 type GetTodosResponse struct {
 	Todos []TodoResponse `json:"todos"`
+	Meta  PaginationMeta `json:"meta"`
+}
+
+// List Upcoming Todos
+type GetUpcomingTodosRequest struct {
+	Days     int    `form:"days" validate:"omitempty,min=1,max=31"`
+	Timezone string `form:"tz" validate:"omitempty,timezone"`
+}
+type UpcomingDay struct {
+	Date  string         `json:"date"`
+	Todos []TodoResponse `json:"todos"`
+}
+type GetUpcomingTodosResponse struct {
+	Timezone string        `json:"timezone"`
+	Days     []UpcomingDay `json:"days"`
+}
+
+// Search Todos
+type SearchTodosRequest struct {
+	Query     string   `form:"q" validate:"required,max=255"`
+	Limit     int      `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset    int      `form:"offset" validate:"omitempty,min=0,max=10000"`
+	Completed *bool    `form:"completed"`
+	Priority  []string `form:"priority" validate:"max=4,dive,oneof=P0 P1 P2 P3"`
+	Status    []string `form:"status" validate:"max=20,dive,max=50"`
+	Tag       []string `form:"tag" validate:"max=20,dive,max=50"`
+	TagMode   string   `form:"tag_mode" validate:"omitempty,oneof=any all"`
+	Project   string   `form:"project" validate:"max=36"`
+}
+type TodoSearchResult struct {
+	Todo TodoResponse `json:"todo"`
+	Rank float64      `json:"rank"`
+	// TitleHighlight and Snippet are HTML escaped with the matches wrapped in <mark> tags
+	TitleHighlight string `json:"title_highlight"`
+	Snippet        string `json:"snippet"`
+}
+type SearchMeta struct {
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	HasMore bool `json:"has_more"`
+}
+type SearchTodosResponse struct {
+	Results []TodoSearchResult `json:"results"`
+	Meta    SearchMeta         `json:"meta"`
 }
 
 // Create Todo
 type CreateTodoRequest struct {
-	Title       string `json:"title" validate:"required,max=255,min=1"`
-	Description string `json:"description" validate:"max=1000"`
+	Title        string      `json:"title" validate:"required,max=255,min=1"`
+	Description  string      `json:"description" validate:"max=1000"`
+	DueAt        *time.Time  `json:"due_at"`
+	AllDay       bool        `json:"all_day" validate:"excluded_without=DueAt"`
+	RemindAt     *time.Time  `json:"remind_at"`
+	Priority     string      `json:"priority" validate:"omitempty,oneof=P0 P1 P2 P3"`
+	Status       string      `json:"status" validate:"max=50"`
+	TagIDs       []uuid.UUID `json:"tag_ids" validate:"max=20"`
+	TagNames     []string    `json:"tag_names" validate:"max=20,dive,max=50"`
+	ProjectID    *uuid.UUID  `json:"project_id"`
+	ParentID     *uuid.UUID  `json:"parent_id"`
+	AutoComplete bool        `json:"auto_complete"`
+	Recurrence   string      `json:"recurrence" validate:"max=255"`
+	Timezone     string      `json:"timezone" validate:"omitempty,timezone"`
 }
 type CreateTodoResponse struct {
 	Todo TodoResponse `json:"todo"`
@@ -29,10 +173,465 @@ type CreateTodoResponse struct {
 
 // Update Todo
 type UpdateTodoRequest struct {
-	Title       string `json:"title" validate:"required,max=255,min=1"`
-	Description string `json:"description" validate:"max=1000"`
-	Completed   bool   `json:"completed"`
+	Title        string      `json:"title" validate:"required,max=255,min=1"`
+	Description  string      `json:"description" validate:"max=1000"`
+	Completed    bool        `json:"completed"`
+	DueAt        *time.Time  `json:"due_at"`
+	AllDay       bool        `json:"all_day" validate:"excluded_without=DueAt"`
+	RemindAt     *time.Time  `json:"remind_at"`
+	Priority     string      `json:"priority" validate:"omitempty,oneof=P0 P1 P2 P3"`
+	Status       string      `json:"status" validate:"max=50"`
+	TagIDs       []uuid.UUID `json:"tag_ids" validate:"max=20"`
+	TagNames     []string    `json:"tag_names" validate:"max=20,dive,max=50"`
+	AutoComplete bool        `json:"auto_complete"`
+	Recurrence   *string     `json:"recurrence" validate:"omitempty,max=255"`
+	Timezone     *string     `json:"timezone" validate:"omitempty,timezone"`
+
+	// IfMatch is the If-Match header, the update only goes through while the todo has one of its ETags
+	IfMatch string `json:"-" swaggerignore:"true"`
 }
 type UpdateTodoResponse struct {
 	Todo TodoResponse `json:"todo"`
+	// Next is the instance created when completing an occurrence of a recurring todo
+	Next *TodoResponse      `json:"next,omitempty"`
+	Undo *UndoTokenResponse `json:"undo,omitempty"`
+}
+
+// Patch Todo
+// PatchTodoRequest is the document that a merge patch or JSON patch is applied to
+// Only the fields that the patch changes are validated
+type PatchTodoRequest struct {
+	Title        string      `json:"title" validate:"required,max=255,min=1"`
+	Description  string      `json:"description" validate:"max=1000"`
+	Completed    bool        `json:"completed"`
+	DueAt        *time.Time  `json:"due_at"`
+	AllDay       bool        `json:"all_day" validate:"excluded_without=DueAt"`
+	RemindAt     *time.Time  `json:"remind_at"`
+	Priority     string      `json:"priority" validate:"oneof=P0 P1 P2 P3"`
+	Status       string      `json:"status" validate:"required,max=50"`
+	TagIDs       []uuid.UUID `json:"tag_ids" validate:"max=20"`
+	AutoComplete bool        `json:"auto_complete"`
+	Recurrence   string      `json:"recurrence" validate:"max=255"`
+	Timezone     string      `json:"timezone" validate:"omitempty,timezone"`
+}
+
+func NewPatchTodoRequest(todo Todo) PatchTodoRequest {
+	return PatchTodoRequest{
+		Title:        todo.Title,
+		Description:  todo.Description,
+		Completed:    todo.Completed,
+		DueAt:        todo.DueAt,
+		AllDay:       todo.AllDay,
+		RemindAt:     todo.RemindAt,
+		Priority:     PriorityLabel(todo.Priority),
+		Status:       todo.Status,
+		TagIDs:       tagIDs(todo.Tags),
+		AutoComplete: todo.AutoComplete,
+		Recurrence:   todo.Recurrence,
+		Timezone:     todo.Timezone,
+	}
+}
+
+// Move Todo
+type MoveTodoRequest struct {
+	ProjectID *uuid.UUID `json:"project_id"`
+}
+type MoveTodoResponse struct {
+	Todo TodoResponse `json:"todo"`
+}
+
+// Reorder Todo
+// The todo is placed right after After or right before Before, with both it goes between them
+type ReorderTodoRequest struct {
+	After  *uuid.UUID `json:"after" validate:"required_without=Before"`
+	Before *uuid.UUID `json:"before" validate:"required_without=After"`
+}
+type ReorderTodoResponse struct {
+	Todo TodoResponse `json:"todo"`
+}
+
+// Get Todo
+type SubtaskProgressResponse struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+type TodoTreeResponse struct {
+	TodoResponse
+	// Progress counts the direct subtasks
+	Progress  SubtaskProgressResponse `json:"progress"`
+	Checklist []ChecklistItemResponse `json:"checklist"`
+	Subtasks  []TodoTreeResponse      `json:"subtasks"`
+}
+type GetTodoRequest struct {
+	Include string `form:"include" validate:"max=100"`
+}
+type TodoDetailResponse struct {
+	TodoTreeResponse
+	// History holds the newest changes, like GET /todo/{id}/history, and is only sent when requested with include
+	History *[]TodoHistoryResponse `json:"history,omitempty"`
+}
+type GetTodoResponse struct {
+	Todo TodoDetailResponse `json:"todo"`
+}
+
+// Recurring Todos
+type SkipOccurrenceRequest struct {
+	Date string `json:"date" validate:"omitempty,datetime=2006-01-02"`
+}
+type SkipOccurrenceResponse struct {
+	Todo TodoResponse `json:"todo"`
+}
+type CompleteSeriesResponse struct {
+	Todo TodoResponse `json:"todo"`
+}
+
+// Delete Todo
+type DeleteTodoRequest struct {
+	Subtasks string `form:"subtasks" validate:"omitempty,oneof=cascade reparent"`
+
+	// IfMatch is the If-Match header, the todo is only deleted while it has one of its ETags
+	IfMatch string `form:"-" swaggerignore:"true"`
+}
+type DeleteTodoResponse struct {
+	Undo *UndoTokenResponse `json:"undo,omitempty"`
+}
+
+// Set Todo Parent
+type SetParentRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+
+	// IfMatch is the If-Match header, the todo is only moved while it has one of its ETags
+	IfMatch string `json:"-" swaggerignore:"true"`
+}
+type SetParentResponse struct {
+	Todo TodoResponse `json:"todo"`
+}
+
+// Checklist
+type ChecklistItemResponse struct {
+	Id   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+	Done bool      `json:"done"`
+}
+
+func NewChecklistItemResponse(item ChecklistItem) ChecklistItemResponse {
+	return ChecklistItemResponse{
+		Id:   item.ID,
+		Text: item.Text,
+		Done: item.Done,
+	}
+}
+
+type GetChecklistResponse struct {
+	Items []ChecklistItemResponse `json:"items"`
+}
+type CreateChecklistItemRequest struct {
+	Text string `json:"text" validate:"required,max=255"`
+}
+type CreateChecklistItemResponse struct {
+	Item ChecklistItemResponse `json:"item"`
+}
+type UpdateChecklistItemRequest struct {
+	Text string `json:"text" validate:"required,max=255"`
+	Done bool   `json:"done"`
+}
+type UpdateChecklistItemResponse struct {
+	Item ChecklistItemResponse `json:"item"`
+}
+type ReorderChecklistRequest struct {
+	ItemIDs []uuid.UUID `json:"item_ids" validate:"required,min=1,max=200"`
+}
+
+// Restore Todo
+type RestoreTodoResponse struct {
+	Todo TodoResponse `json:"todo"`
+}
+
+// Bulk Todos
+type BulkTodoRequest struct {
+	// Mode is atomic to roll every operation back when one fails, or best_effort to keep the ones that succeed
+	Mode       string              `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Operations []BulkTodoOperation `json:"operations" validate:"required,min=1,max=100,dive"`
+}
+type BulkTodoOperation struct {
+	Op     string             `json:"op" validate:"required,oneof=create update complete delete move"`
+	ID     *uuid.UUID         `json:"id" validate:"required_unless=Op create"`
+	Create *CreateTodoRequest `json:"create" validate:"required_if=Op create"`
+	// Fields is a merge patch of the todo for update
+	Fields json.RawMessage `json:"fields" swaggertype:"object" validate:"required_if=Op update"`
+	// ProjectID is the project to move to, none moves the todo to the inbox
+	ProjectID *uuid.UUID `json:"project_id"`
+	Subtasks  string     `json:"subtasks" validate:"omitempty,oneof=cascade reparent"`
+	IfMatch   string     `json:"if_match"`
+}
+type BulkTodoResult struct {
+	Index      int           `json:"index"`
+	Op         string        `json:"op"`
+	ID         *uuid.UUID    `json:"id,omitempty"`
+	StatusCode int           `json:"status_code"`
+	Message    string        `json:"message"`
+	Todo       *TodoResponse `json:"todo,omitempty"`
+}
+type BulkTodoResponse struct {
+	Mode      string           `json:"mode"`
+	Committed bool             `json:"committed"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkTodoResult `json:"results"`
+	// Undo reverts the operations that were applied, it is only handed out once something changed
+	Undo *UndoTokenResponse `json:"undo,omitempty"`
+}
+
+// Workflow
+type WorkflowStatusRequest struct {
+	Key  string `json:"key" validate:"required,max=50"`
+	Name string `json:"name" validate:"required,max=100"`
+	Done bool   `json:"done"`
+}
+type WorkflowTransitionRequest struct {
+	From string `json:"from" validate:"required,max=50"`
+	To   string `json:"to" validate:"required,max=50"`
+}
+type UpdateWorkflowRequest struct {
+	Statuses    []WorkflowStatusRequest     `json:"statuses" validate:"required,min=2,max=20,dive"`
+	Transitions []WorkflowTransitionRequest `json:"transitions" validate:"max=400,dive"`
+}
+type WorkflowStatusResponse struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Done bool   `json:"done"`
+}
+type WorkflowTransitionResponse struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+type WorkflowResponse struct {
+	Statuses    []WorkflowStatusResponse     `json:"statuses"`
+	Transitions []WorkflowTransitionResponse `json:"transitions"`
+}
+
+func NewWorkflowStatusResponse(status WorkflowStatus) WorkflowStatusResponse {
+	return WorkflowStatusResponse{
+		Key:  status.Key,
+		Name: status.Name,
+		Done: status.Done,
+	}
+}
+
+func NewWorkflowResponse(workflow Workflow) WorkflowResponse {
+	response := WorkflowResponse{
+		Statuses:    make([]WorkflowStatusResponse, 0, len(workflow.Statuses)),
+		Transitions: make([]WorkflowTransitionResponse, 0, len(workflow.Transitions)),
+	}
+	for _, status := range workflow.Statuses {
+		response.Statuses = append(response.Statuses, NewWorkflowStatusResponse(status))
+	}
+	for _, transition := range workflow.Transitions {
+		response.Transitions = append(response.Transitions, WorkflowTransitionResponse{
+			From: transition.FromStatus,
+			To:   transition.ToStatus,
+		})
+	}
+	return response
+}
+
+// Board
+type GetBoardRequest struct {
+	Limit    int      `form:"limit" validate:"omitempty,min=1,max=100"`
+	Title    string   `form:"title" validate:"max=255"`
+	Priority []string `form:"priority" validate:"max=4,dive,oneof=P0 P1 P2 P3"`
+}
+type BoardColumn struct {
+	Status  WorkflowStatusResponse `json:"status"`
+	Todos   []TodoResponse         `json:"todos"`
+	Total   int64                  `json:"total"`
+	HasMore bool                   `json:"has_more"`
+}
+type GetBoardResponse struct {
+	Columns []BoardColumn `json:"columns"`
+}
+
+// Todo Transitions
+type TodoTransitionResponse struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	CreatedAt string `json:"created_at"`
+}
+
+func NewTodoTransitionResponse(transition TodoTransition) TodoTransitionResponse {
+	return TodoTransitionResponse{
+		From:      transition.FromStatus,
+		To:        transition.ToStatus,
+		CreatedAt: transition.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+type GetTodoTransitionsResponse struct {
+	Transitions []TodoTransitionResponse `json:"transitions"`
+}
+
+// Todo History
+type GetHistoryRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+type TodoHistoryResponse struct {
+	Id      uuid.UUID `json:"id"`
+	TodoID  uuid.UUID `json:"todo_id"`
+	Title   string    `json:"title"`
+	ActorID uuid.UUID `json:"actor_id"`
+	Action  string    `json:"action"`
+	// Changes lists the fields that changed with their old and new values, for a created todo the fields it was created with
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt string        `json:"created_at"`
+}
+
+func NewTodoHistoryResponse(history TodoHistory) TodoHistoryResponse {
+	return TodoHistoryResponse{
+		Id:        history.ID,
+		TodoID:    history.TodoID,
+		Title:     history.Title,
+		ActorID:   history.ActorID,
+		Action:    history.Action,
+		Changes:   append([]FieldChange{}, history.Changes...),
+		CreatedAt: history.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+type HistoryMeta struct {
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+	Limit      int    `json:"limit"`
+}
+type GetHistoryResponse struct {
+	History []TodoHistoryResponse `json:"history"`
+	Meta    HistoryMeta           `json:"meta"`
+}
+
+// Undo
+type UndoTokenResponse struct {
+	Token     uuid.UUID `json:"token"`
+	ExpiresAt string    `json:"expires_at"`
+}
+
+func NewUndoTokenResponse(undo TodoUndo) UndoTokenResponse {
+	return UndoTokenResponse{
+		Token:     undo.ID,
+		ExpiresAt: undo.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+type UndoResponse struct {
+	// Todos are the todos as the undo left them, todos the change created are gone
+	Todos []TodoResponse `json:"todos"`
+}
+
+// Tags
+type TagResponse struct {
+	Id    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Color string    `json:"color"`
+}
+
+func NewTagResponse(tag Tag) TagResponse {
+	return TagResponse{
+		Id:    tag.ID,
+		Name:  tag.Name,
+		Color: tag.Color,
+	}
+}
+
+type GetTagsResponse struct {
+	Tags []TagResponse `json:"tags"`
+}
+type GetTagResponse struct {
+	Tag TagResponse `json:"tag"`
+}
+
+// Create Tag
+type CreateTagRequest struct {
+	Name  string `json:"name" validate:"required,max=50"`
+	Color string `json:"color" validate:"omitempty,hexcolor"`
+}
+type CreateTagResponse struct {
+	Tag TagResponse `json:"tag"`
+}
+
+// Update Tag
+type UpdateTagRequest struct {
+	Name  string `json:"name" validate:"required,max=50"`
+	Color string `json:"color" validate:"omitempty,hexcolor"`
+}
+type UpdateTagResponse struct {
+	Tag TagResponse `json:"tag"`
+}
+
+// Projects
+type ProjectResponse struct {
+	Id             uuid.UUID `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Color          string    `json:"color"`
+	Archived       bool      `json:"archived"`
+	Position       int       `json:"position"`
+	OpenCount      int64     `json:"open_count"`
+	CompletedCount int64     `json:"completed_count"`
+	CreatedAt      string    `json:"created_at"`
+	UpdatedAt      string    `json:"updated_at"`
+}
+
+func NewProjectResponse(project Project, counts TodoCounts) ProjectResponse {
+	return ProjectResponse{
+		Id:             project.ID,
+		Name:           project.Name,
+		Description:    project.Description,
+		Color:          project.Color,
+		Archived:       project.Archived,
+		Position:       project.Position,
+		OpenCount:      counts.Open,
+		CompletedCount: counts.Completed,
+		CreatedAt:      project.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      project.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// List Projects
+type GetProjectsRequest struct {
+	Archived bool `form:"archived"`
+}
+type InboxResponse struct {
+	OpenCount      int64 `json:"open_count"`
+	CompletedCount int64 `json:"completed_count"`
+}
+type GetProjectsResponse struct {
+	Inbox    InboxResponse     `json:"inbox"`
+	Projects []ProjectResponse `json:"projects"`
+}
+type GetProjectResponse struct {
+	Project ProjectResponse `json:"project"`
+}
+
+// Create Project
+type CreateProjectRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+	Color       string `json:"color" validate:"omitempty,hexcolor"`
+}
+type CreateProjectResponse struct {
+	Project ProjectResponse `json:"project"`
+}
+
+// Update Project
+type UpdateProjectRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+	Color       string `json:"color" validate:"omitempty,hexcolor"`
+}
+type UpdateProjectResponse struct {
+	Project ProjectResponse `json:"project"`
+}
+
+// Reorder Projects
+type ReorderProjectsRequest struct {
+	ProjectIDs []uuid.UUID `json:"project_ids" validate:"required,min=1,max=200"`
 }
@@ -0,0 +1,206 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var jakarta = time.FixedZone("WIB", 7*60*60)
+
+// dueTestNow is Monday 2025-03-10 10:00 in Jakarta, which is still Sunday evening in New York
+var dueTestNow = time.Date(2025, 3, 10, 10, 0, 0, 0, jakarta)
+
+// seedDueTodos creates a set of todos with due dates around dueTestNow
+func seedDueTodos(t *testing.T, repository TodoRepository, userID uuid.UUID) {
+	t.Helper()
+
+	todos := []struct {
+		title     string
+		dueAt     *time.Time
+		allDay    bool
+		completed bool
+	}{
+		{title: "Past timed", dueAt: timePtr(time.Date(2025, 3, 10, 9, 0, 0, 0, jakarta))},
+		{title: "Later today", dueAt: timePtr(time.Date(2025, 3, 10, 18, 0, 0, 0, jakarta))},
+		{title: "Today all-day", dueAt: timePtr(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)), allDay: true},
+		{title: "Yesterday all-day", dueAt: timePtr(time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)), allDay: true},
+		{title: "Done yesterday", dueAt: timePtr(time.Date(2025, 3, 9, 20, 0, 0, 0, jakarta)), completed: true},
+		{title: "Tomorrow late", dueAt: timePtr(time.Date(2025, 3, 11, 23, 30, 0, 0, jakarta))},
+		{title: "Next week", dueAt: timePtr(time.Date(2025, 3, 16, 12, 0, 0, 0, jakarta))},
+		{title: "Far away", dueAt: timePtr(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)), allDay: true},
+		{title: "No due date"},
+	}
+
+	for _, seed := range todos {
+		todo := Todo{
+			Title:     seed.title,
+			UserID:    userID,
+			Completed: seed.completed,
+			DueAt:     normalizeDue(seed.dueAt, seed.allDay),
+			AllDay:    seed.allDay,
+			Priority:  defaultPriority,
+			Status:    "backlog",
+		}
+		if todo.Completed {
+			todo.Status = "done"
+		}
+		if err := repository.CreateTodo(context.Background(), &todo); err != nil {
+			t.Fatalf("failed to seed todo: %v", err)
+		}
+	}
+}
+
+func TestTodoServiceDueFilters(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        GetTodosRequest
+		wantStatus int
+		wantTitles []string
+	}{
+		{
+			name:       "overdue skips completed and today's all-day todos",
+			req:        GetTodosRequest{Overdue: true, Timezone: "Asia/Jakarta"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Past timed", "Yesterday all-day"},
+		},
+		{
+			name:       "due today in the request time zone",
+			req:        GetTodosRequest{DueToday: true, Timezone: "Asia/Jakarta"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Later today", "Past timed", "Today all-day"},
+		},
+		{
+			name:       "due today depends on the time zone",
+			req:        GetTodosRequest{DueToday: true, Timezone: "America/New_York"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Done yesterday", "Past timed", "Yesterday all-day"},
+		},
+		{
+			name:       "due within a number of days",
+			req:        GetTodosRequest{DueWithin: "7d", Timezone: "Asia/Jakarta"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Later today", "Next week", "Today all-day", "Tomorrow late"},
+		},
+		{
+			name:       "due within a duration",
+			req:        GetTodosRequest{DueWithin: "12h", Timezone: "Asia/Jakarta"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Later today", "Today all-day"},
+		},
+		{
+			name:       "filters combine",
+			req:        GetTodosRequest{Overdue: true, DueToday: true, Timezone: "Asia/Jakarta"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Past timed"},
+		},
+		{
+			name:       "rejects a malformed due_within",
+			req:        GetTodosRequest{DueWithin: "soon"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects a negative due_within",
+			req:        GetTodosRequest{DueWithin: "-1d"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for name, newRepository := range testRepositories() {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				repository := newRepository(t)
+				service := newTestService(repository)
+				service.now = func() time.Time { return dueTestNow }
+				userID := uuid.New()
+				seedDueTodos(t, repository, userID)
+
+				tt.req.Sort = "title"
+				response := service.GetAll(context.Background(), userID, tt.req)
+				if response.StatusCode != tt.wantStatus {
+					t.Fatalf("status = %d, want %d (%s)", response.StatusCode, tt.wantStatus, response.Message)
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				data := response.Data.(GetTodosResponse)
+				if got := titlesOf(data.Todos); !slices.Equal(got, tt.wantTitles) {
+					t.Errorf("titles = %v, want %v", got, tt.wantTitles)
+				}
+			})
+		}
+	}
+}
+
+func TestTodoServiceUpcoming(t *testing.T) {
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			repository := newRepository(t)
+			service := newTestService(repository)
+			service.now = func() time.Time { return dueTestNow }
+			userID := uuid.New()
+			seedDueTodos(t, repository, userID)
+
+			response := service.Upcoming(context.Background(), userID, GetUpcomingTodosRequest{Days: 3, Timezone: "Asia/Jakarta"})
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d (%s)", response.StatusCode, response.Message)
+			}
+
+			data := response.Data.(GetUpcomingTodosResponse)
+			want := []struct {
+				date   string
+				titles []string
+			}{
+				{date: "2025-03-10", titles: []string{"Today all-day", "Past timed", "Later today"}},
+				{date: "2025-03-11", titles: []string{"Tomorrow late"}},
+				{date: "2025-03-12", titles: []string{}},
+			}
+			if len(data.Days) != len(want) {
+				t.Fatalf("days = %+v, want %d days", data.Days, len(want))
+			}
+			for i, day := range data.Days {
+				if day.Date != want[i].date || !slices.Equal(titlesOf(day.Todos), want[i].titles) {
+					t.Errorf("day %d = %s %v, want %s %v", i, day.Date, titlesOf(day.Todos), want[i].date, want[i].titles)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoServiceCreateNormalizesDueDates(t *testing.T) {
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			repository := newRepository(t)
+			service := newTestService(repository)
+			userID := uuid.New()
+
+			// An all-day todo keeps the calendar date it was sent with, whatever the offset
+			response := service.Create(context.Background(), CreateTodoRequest{
+				Title:    "Pay rent",
+				DueAt:    timePtr(time.Date(2025, 3, 1, 23, 0, 0, 0, jakarta)),
+				AllDay:   true,
+				RemindAt: timePtr(time.Date(2025, 3, 1, 8, 0, 0, 0, jakarta)),
+			}, userID)
+			if response.StatusCode != http.StatusCreated {
+				t.Fatalf("status = %d (%s)", response.StatusCode, response.Message)
+			}
+
+			created := response.Data.(CreateTodoResponse).Todo
+			stored, err := repository.FindTodoByIDAndUserID(context.Background(), created.Id, userID)
+			if err != nil {
+				t.Fatalf("created todo not stored: %v", err)
+			}
+			if !stored.AllDay || !stored.DueAt.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("due_at = %v (all_day %v), want 2025-03-01 all day", stored.DueAt, stored.AllDay)
+			}
+			if !stored.RemindAt.Equal(time.Date(2025, 3, 1, 1, 0, 0, 0, time.UTC)) {
+				t.Errorf("remind_at = %v, want 2025-03-01T01:00:00Z", stored.RemindAt)
+			}
+		})
+	}
+}
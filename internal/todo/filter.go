@@ -0,0 +1,390 @@
+package todo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTodoLimit = 20
+	defaultTodoSort  = "position"
+
+	defaultUpcomingDays = 7
+)
+
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidDueWithin = errors.New("invalid due_within, expected a positive duration such as 7d or 12h")
+)
+
+// sortColumns maps the public sort keys to their database columns
+var sortColumns = map[string]string{
+	"position":   "position",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "title",
+	"priority":   "priority",
+	"status":     "status",
+}
+
+// TodoSort describes the ordering of a todo listing
+type TodoSort struct {
+	Field      string
+	Descending bool
+}
+
+// ParseTodoSort parses a sort parameter such as "title" or "-created_at"
+func ParseTodoSort(value string) TodoSort {
+	if value == "" {
+		value = defaultTodoSort
+	}
+
+	sort := TodoSort{Field: strings.TrimPrefix(value, "-")}
+	sort.Descending = strings.HasPrefix(value, "-")
+	return sort
+}
+
+// String returns the sort in its query parameter form
+func (sort TodoSort) String() string {
+	if sort.Descending {
+		return "-" + sort.Field
+	}
+	return sort.Field
+}
+
+// Column returns the database column backing the sort field
+func (sort TodoSort) Column() string {
+	return sortColumns[sort.Field]
+}
+
+// TodoCursor is the keyset position of the last todo returned in a page
+type TodoCursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// TodoFilter holds the filters, ordering and page window of a todo listing
+type TodoFilter struct {
+	Completed   *bool
+	Title       string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	Priorities  []int
+	Statuses    []string
+	Sort        TodoSort
+	Cursor      *TodoCursor
+	Limit       int
+
+	// Project is the project to list, uuid.Nil lists the inbox
+	Project *uuid.UUID
+
+	// Tags are lowercased tag names, a todo matches with any of them or, with AllTags, all of them
+	Tags    []string
+	AllTags bool
+
+	// Overdue keeps the incomplete todos whose due date has passed
+	Overdue bool
+	Due     *DueWindow
+
+	// Trashed lists the todos in the trash instead of the active ones
+	Trashed bool
+}
+
+// DueWindow is a range of due dates
+// Timed todos are due at an instant, while all-day todos are due on a calendar date that is
+// stored as midnight UTC, so each kind has its own bounds
+// Lower bounds are inclusive and upper bounds exclusive, a nil bound is open
+type DueWindow struct {
+	From     *time.Time
+	To       *time.Time
+	FromDate *time.Time
+	ToDate   *time.Time
+}
+
+// Narrow restricts the window to its intersection with other
+func (window DueWindow) Narrow(other DueWindow) DueWindow {
+	return DueWindow{
+		From:     later(window.From, other.From),
+		To:       earlier(window.To, other.To),
+		FromDate: later(window.FromDate, other.FromDate),
+		ToDate:   earlier(window.ToDate, other.ToDate),
+	}
+}
+
+// Contains reports whether a todo due at dueAt falls within the window
+func (window DueWindow) Contains(dueAt *time.Time, allDay bool) bool {
+	if dueAt == nil {
+		return false
+	}
+	from, to := window.From, window.To
+	if allDay {
+		from, to = window.FromDate, window.ToDate
+	}
+	if from != nil && dueAt.Before(*from) {
+		return false
+	}
+	if to != nil && !dueAt.Before(*to) {
+		return false
+	}
+	return true
+}
+
+// DaysWindow covers the given number of days starting today in loc
+func DaysWindow(now time.Time, loc *time.Location, days int) DueWindow {
+	// Adding days in loc keeps the window aligned to local midnight across DST changes
+	start := startOfDay(now, loc)
+	end := start.AddDate(0, 0, days).UTC()
+	start = start.UTC()
+	today := calendarDate(now, loc)
+	last := today.AddDate(0, 0, days)
+	return DueWindow{From: &start, To: &end, FromDate: &today, ToDate: &last}
+}
+
+// NewTodoFilter builds a TodoFilter from a list request, decoding its cursor
+// Relative due date filters are resolved against now in the request's time zone
+func NewTodoFilter(req GetTodosRequest, now time.Time) (TodoFilter, error) {
+	filter := TodoFilter{
+		Completed:   req.Completed,
+		Title:       req.Title,
+		CreatedFrom: toUTC(req.CreatedFrom),
+		CreatedTo:   toUTC(req.CreatedTo),
+		UpdatedFrom: toUTC(req.UpdatedFrom),
+		UpdatedTo:   toUTC(req.UpdatedTo),
+		Sort:        ParseTodoSort(req.Sort),
+		Limit:       req.Limit,
+		Overdue:     req.Overdue,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultTodoLimit
+	}
+
+	// SQLite compares timestamps as text, so every bound is in UTC
+	now = now.UTC()
+	for _, label := range req.Priority {
+		priority, err := ParsePriority(label)
+		if err != nil {
+			return filter, err
+		}
+		filter.Priorities = append(filter.Priorities, priority)
+	}
+	filter.Statuses = req.Status
+	for _, name := range normalizeTagNames(req.Tag) {
+		filter.Tags = append(filter.Tags, strings.ToLower(name))
+	}
+	filter.AllTags = req.TagMode == TagMatchAll
+	if req.Project != "" {
+		project, err := ParseProject(req.Project)
+		if err != nil {
+			return filter, err
+		}
+		filter.Project = &project
+	}
+
+	loc := LoadLocation(req.Timezone)
+	today := calendarDate(now, loc)
+	if req.Overdue {
+		filter.narrowDue(DueWindow{To: &now, ToDate: &today})
+	}
+	if req.DueToday {
+		filter.narrowDue(DaysWindow(now, loc, 1))
+	}
+	if req.DueWithin != "" {
+		within, err := ParseDueWithin(req.DueWithin)
+		if err != nil {
+			return filter, err
+		}
+		end := now.Add(within)
+		lastDate := calendarDate(end, loc).AddDate(0, 0, 1)
+		filter.narrowDue(DueWindow{From: &now, To: &end, FromDate: &today, ToDate: &lastDate})
+	}
+
+	if req.Cursor != "" {
+		cursor, err := DecodeTodoCursor(req.Cursor)
+		if err != nil {
+			return filter, err
+		}
+		// A cursor only makes sense for the ordering it was issued for
+		if cursor.Sort != filter.Sort.String() {
+			return filter, ErrInvalidCursor
+		}
+		filter.Cursor = &cursor
+	}
+
+	return filter, nil
+}
+
+func (filter *TodoFilter) narrowDue(window DueWindow) {
+	if filter.Due == nil {
+		filter.Due = &window
+		return
+	}
+	narrowed := filter.Due.Narrow(window)
+	filter.Due = &narrowed
+}
+
+// ParseDueWithin parses a due_within value, a Go duration that also accepts whole days such as "7d"
+func ParseDueWithin(value string) (time.Duration, error) {
+	var within time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, ErrInvalidDueWithin
+		}
+		within = time.Duration(count) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, ErrInvalidDueWithin
+		}
+		within = parsed
+	}
+
+	if within <= 0 {
+		return 0, ErrInvalidDueWithin
+	}
+	return within, nil
+}
+
+// LoadLocation returns the named time zone, falling back to UTC for an empty or unknown name
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// NewTodoCursor builds the cursor pointing right after the given todo
+func NewTodoCursor(todo Todo, sort TodoSort) TodoCursor {
+	return TodoCursor{
+		Sort:  sort.String(),
+		Value: sortValue(todo, sort.Field),
+		ID:    todo.ID,
+	}
+}
+
+// Encode serializes the cursor into an opaque URL-safe string
+func (cursor TodoCursor) Encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeTodoCursor parses a cursor produced by TodoCursor.Encode
+func DecodeTodoCursor(value string) (TodoCursor, error) {
+	var cursor TodoCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if ParseTodoSort(cursor.Sort).Column() == "" || cursor.ID == uuid.Nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// sortArg converts a cursor value back into the type stored in the sort column
+func sortArg(field string, value string) (any, error) {
+	switch field {
+	case "created_at", "updated_at":
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return parsed, nil
+	case "priority":
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return parsed, nil
+	default:
+		return value, nil
+	}
+}
+
+func sortValue(todo Todo, field string) string {
+	switch field {
+	case "created_at":
+		return todo.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case "updated_at":
+		return todo.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case "priority":
+		return strconv.Itoa(todo.Priority)
+	case "status":
+		return todo.Status
+	case "position":
+		return todo.Position
+	default:
+		return todo.Title
+	}
+}
+
+// toUTC normalizes a filter bound, since SQLite compares timestamps as text
+func toUTC(value *time.Time) *time.Time {
+	if value == nil {
+		return nil
+	}
+	utc := value.UTC()
+	return &utc
+}
+
+// normalizeDue stores timed due dates in UTC and all-day ones as the calendar date at midnight UTC
+// The calendar date of an all-day todo is read in the offset it was sent with
+func normalizeDue(dueAt *time.Time, allDay bool) *time.Time {
+	if dueAt == nil {
+		return nil
+	}
+	if allDay {
+		date := time.Date(dueAt.Year(), dueAt.Month(), dueAt.Day(), 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	return toUTC(dueAt)
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// calendarDate returns the date of t in loc, as all-day due dates store it
+func calendarDate(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func later(a *time.Time, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
+
+func earlier(a *time.Time, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+	return a
+}
+
+// escapeLike escapes the LIKE wildcards in a user supplied substring
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
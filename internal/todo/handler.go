@@ -20,15 +20,42 @@ func NewTodoHandler(todoService TodoService, log logger.Logger) TodoHandler {
 }
 
 // @Summary      Get all todos
-// @Description  Get all todos for the authenticated user
+// @Description  Get a page of todos for the authenticated user, using cursor based pagination
 // @Tags         Todo
 // @Produce      json
+// @Param        cursor        query     string  false  "Cursor from the previous page's next_cursor"
+// @Param        limit         query     int     false  "Page size (1-100, default 20)"
+// @Param        sort          query     string  false  "Sort field, prefix with - for descending (default position, the manual order)"  Enums(position, -position, created_at, -created_at, updated_at, -updated_at, title, -title, priority, -priority, status, -status)
+// @Param        completed     query     bool    false  "Filter by completion"
+// @Param        title         query     string  false  "Case-insensitive title substring"
+// @Param        created_from  query     string  false  "Created at or after (RFC3339)"
+// @Param        created_to    query     string  false  "Created at or before (RFC3339)"
+// @Param        updated_from  query     string  false  "Updated at or after (RFC3339)"
+// @Param        updated_to    query     string  false  "Updated at or before (RFC3339)"
+// @Param        overdue       query     bool    false  "Only incomplete todos past their due date"
+// @Param        due_today     query     bool    false  "Only todos due today"
+// @Param        due_within    query     string  false  "Only todos due from now within a duration such as 7d or 12h"
+// @Param        tz            query     string  false  "IANA time zone that due dates are resolved in (default UTC)"
+// @Param        priority      query     []string  false  "Only these priorities"  collectionFormat(multi)  Enums(P0, P1, P2, P3)
+// @Param        status        query     []string  false  "Only these workflow statuses"  collectionFormat(multi)
+// @Param        tag           query     []string  false  "Only todos with these tag names"  collectionFormat(multi)
+// @Param        tag_mode      query     string    false  "Match any or all of the tags (default any)"  Enums(any, all)
+// @Param        project       query     string    false  "Only todos of this project id, or inbox for todos without a project"
+// @Param        If-None-Match  header  string  false  "ETag of a previous response, answered with 304 while the list is unchanged"
 // @Security	 BearerAuth
 // @Success      200  {object}  models.Response{data=todo.GetTodosResponse}
+// @Header       200  {string}  ETag  "Weak ETag of the response"
+// @Success      304  "List is unchanged"
 // @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
 // @Failure      500  {object}  models.Response
 // @Router       /todo [get]
 func (handler TodoHandler) GetAll(ctx *gin.Context) {
+	var req GetTodosRequest
+	if !utils.ValidateQuery(ctx, &req) {
+		return
+	}
+
 	// Get user ID from JWT claims
 	userID, err := utils.GetUserIDFromContext(ctx)
 	if err != nil {
@@ -41,7 +68,7 @@ func (handler TodoHandler) GetAll(ctx *gin.Context) {
 		return
 	}
 
-	response := handler.todoService.GetAll(ctx, userID)
+	response := handler.todoService.GetAll(ctx, userID, req)
 	if response.StatusCode != 200 {
 		handler.log.Warn("Get all todos request failed",
 			logger.F("operation", "Get all todos"),
@@ -51,6 +78,177 @@ func (handler TodoHandler) GetAll(ctx *gin.Context) {
 		)
 	}
 
+	utils.JSONWithETag(ctx, response)
+}
+
+// @Summary      Get trashed todos
+// @Description  Get a page of the todos in the authenticated user's trash, using cursor based pagination
+// @Tags         Todo
+// @Produce      json
+// @Param        cursor        query     string  false  "Cursor from the previous page's next_cursor"
+// @Param        limit         query     int     false  "Page size (1-100, default 20)"
+// @Param        sort          query     string  false  "Sort field, prefix with - for descending (default position, the manual order)"  Enums(position, -position, created_at, -created_at, updated_at, -updated_at, title, -title, priority, -priority, status, -status)
+// @Param        completed     query     bool    false  "Filter by completion"
+// @Param        title         query     string  false  "Case-insensitive title substring"
+// @Param        created_from  query     string  false  "Created at or after (RFC3339)"
+// @Param        created_to    query     string  false  "Created at or before (RFC3339)"
+// @Param        updated_from  query     string  false  "Updated at or after (RFC3339)"
+// @Param        updated_to    query     string  false  "Updated at or before (RFC3339)"
+// @Param        overdue       query     bool    false  "Only incomplete todos past their due date"
+// @Param        due_today     query     bool    false  "Only todos due today"
+// @Param        due_within    query     string  false  "Only todos due from now within a duration such as 7d or 12h"
+// @Param        tz            query     string  false  "IANA time zone that due dates are resolved in (default UTC)"
+// @Param        priority      query     []string  false  "Only these priorities"  collectionFormat(multi)  Enums(P0, P1, P2, P3)
+// @Param        status        query     []string  false  "Only these workflow statuses"  collectionFormat(multi)
+// @Param        tag           query     []string  false  "Only todos with these tag names"  collectionFormat(multi)
+// @Param        tag_mode      query     string    false  "Match any or all of the tags (default any)"  Enums(any, all)
+// @Param        project       query     string    false  "Only todos of this project id, or inbox for todos without a project"
+// @Param        If-None-Match  header  string  false  "ETag of a previous response, answered with 304 while the list is unchanged"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetTodosResponse}
+// @Header       200  {string}  ETag  "Weak ETag of the response"
+// @Success      304  "List is unchanged"
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/trash [get]
+func (handler TodoHandler) GetTrash(ctx *gin.Context) {
+	var req GetTodosRequest
+	if !utils.ValidateQuery(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get trashed todos"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetTrash(ctx, userID, req)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get trashed todos request failed",
+			logger.F("operation", "Get trashed todos"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	utils.JSONWithETag(ctx, response)
+}
+
+// @Summary      Get upcoming todos
+// @Description  Get the incomplete todos of the authenticated user that are due in the next days, grouped by day
+// @Tags         Todo
+// @Produce      json
+// @Param        days  query     int     false  "Number of days starting today (1-31, default 7)"
+// @Param        tz    query     string  false  "IANA time zone that days are computed in (default UTC)"
+// @Param        If-None-Match  header  string  false  "ETag of a previous response, answered with 304 while the list is unchanged"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetUpcomingTodosResponse}
+// @Header       200  {string}  ETag  "Weak ETag of the response"
+// @Success      304  "List is unchanged"
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/upcoming [get]
+func (handler TodoHandler) Upcoming(ctx *gin.Context) {
+	var req GetUpcomingTodosRequest
+	if !utils.ValidateQuery(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get upcoming todos"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.Upcoming(ctx, userID, req)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get upcoming todos request failed",
+			logger.F("operation", "Get upcoming todos"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	utils.JSONWithETag(ctx, response)
+}
+
+// @Summary      Get todo
+// @Description  Get a todo of the authenticated user with its checklist and the tree of its subtasks, its tags and optionally its newest changes
+// @Tags         Todo
+// @Produce      json
+// @Param        id       path      string  true   "Todo ID"
+// @Param        include  query     string  false  "Comma separated expansions: subtasks, tags, history"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetTodoResponse}
+// @Header       200  {string}  ETag  "ETag of the todo"
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id} [get]
+func (handler TodoHandler) Get(ctx *gin.Context) {
+	var req GetTodoRequest
+	if !utils.ValidateQuery(ctx, &req) {
+		return
+	}
+
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Get todo"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get todo"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.Get(ctx, todoID, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get todo request failed",
+			logger.F("operation", "Get todo"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	if data, ok := response.Data.(GetTodoResponse); ok {
+		ctx.Header("ETag", utils.VersionETag(data.Todo.Version))
+	}
 	ctx.JSON(response.StatusCode, response)
 }
 
@@ -84,7 +282,7 @@ func (handler TodoHandler) Create(ctx *gin.Context) {
 		return
 	}
 
-	response := handler.todoService.Create(ctx, req.Title, req.Description, userID)
+	response := handler.todoService.Create(ctx, req, userID)
 	if response.StatusCode != 201 {
 		handler.log.Warn("Create todo request failed",
 			logger.F("operation", "Create todo"),
@@ -98,16 +296,19 @@ func (handler TodoHandler) Create(ctx *gin.Context) {
 }
 
 // @Summary      Update todo
-// @Description  Update an existing todo for the authenticated user
+// @Description  Update an existing todo for the authenticated user. The response carries an undo token that reverts the update with POST /undo/{token}
 // @Tags         Todo
 // @Accept       json
 // @Produce      json
 // @Param        id    path      string             true  "Todo ID"
 // @Param        body  body      UpdateTodoRequest  true  "Update Todo Request"
+// @Param        If-Match  header  string  false  "ETag of the todo, the request fails with 412 once the todo has changed"
 // @Security	 BearerAuth
 // @Success      200  {object}  models.Response{data=todo.UpdateTodoResponse}
+// @Header       200  {string}  ETag  "ETag of the todo"
 // @Failure      401  {object}  models.Response
 // @Failure      404  {object}  models.Response
+// @Failure      412  {object}  models.Response
 // @Failure      422  {object}  models.Response
 // @Failure      500  {object}  models.Response
 // @Router       /todo/{id} [put]
@@ -116,6 +317,7 @@ func (handler TodoHandler) Update(ctx *gin.Context) {
 	if !utils.ValidateRequest(ctx, &req) {
 		return
 	}
+	req.IfMatch = ctx.GetHeader("If-Match")
 
 	// Get todo ID from URL parameter
 	todoIDStr := ctx.Param("id")
@@ -143,7 +345,7 @@ func (handler TodoHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	response := handler.todoService.Update(ctx, todoID, req.Title, req.Description, req.Completed, userID)
+	response := handler.todoService.Update(ctx, todoID, req, userID)
 	if response.StatusCode != 200 {
 		handler.log.Warn("Update todo request failed",
 			logger.F("operation", "Update todo"),
@@ -154,22 +356,34 @@ func (handler TodoHandler) Update(ctx *gin.Context) {
 		)
 	}
 
+	if data, ok := response.Data.(UpdateTodoResponse); ok {
+		ctx.Header("ETag", utils.VersionETag(data.Todo.Version))
+	}
 	ctx.JSON(response.StatusCode, response)
 }
 
 // @Summary      Delete todo
-// @Description  Delete an existing todo for the authenticated user
+// @Description  Move an existing todo of the authenticated user to the trash, along with its subtasks unless they are reparented. The response carries an undo token that reverts the delete with POST /undo/{token}
 // @Tags         Todo
 // @Produce      json
-// @Param        id   path      string  true  "Todo ID"
+// @Param        id        path      string  true   "Todo ID"
+// @Param        subtasks  query     string  false  "Trash the subtasks too, or move them up to the todo's parent (default cascade)"  Enums(cascade, reparent)
+// @Param        If-Match  header  string  false  "ETag of the todo, the request fails with 412 once the todo has changed"
 // @Security	 BearerAuth
-// @Success      200  {object}  models.Response
+// @Success      200  {object}  models.Response{data=todo.DeleteTodoResponse}
 // @Failure      401  {object}  models.Response
 // @Failure      404  {object}  models.Response
+// @Failure      412  {object}  models.Response
 // @Failure      422  {object}  models.Response
 // @Failure      500  {object}  models.Response
 // @Router       /todo/{id} [delete]
 func (handler TodoHandler) Delete(ctx *gin.Context) {
+	var req DeleteTodoRequest
+	if !utils.ValidateQuery(ctx, &req) {
+		return
+	}
+	req.IfMatch = ctx.GetHeader("If-Match")
+
 	// Get todo ID from URL parameter
 	todoIDStr := ctx.Param("id")
 	todoID, err := uuid.Parse(todoIDStr)
@@ -196,7 +410,7 @@ func (handler TodoHandler) Delete(ctx *gin.Context) {
 		return
 	}
 
-	response := handler.todoService.Delete(ctx, todoID, userID)
+	response := handler.todoService.Delete(ctx, todoID, req, userID)
 	if response.StatusCode != 200 {
 		handler.log.Warn("Delete todo request failed",
 			logger.F("operation", "Delete todo"),
@@ -209,3 +423,109 @@ func (handler TodoHandler) Delete(ctx *gin.Context) {
 
 	ctx.JSON(response.StatusCode, response)
 }
+
+// @Summary      Restore todo
+// @Description  Restore a todo from the authenticated user's trash
+// @Tags         Todo
+// @Produce      json
+// @Param        id   path      string  true  "Todo ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.RestoreTodoResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/restore [post]
+func (handler TodoHandler) Restore(ctx *gin.Context) {
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Restore todo"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Restore todo"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.Restore(ctx, todoID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Restore todo request failed",
+			logger.F("operation", "Restore todo"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Permanently delete todo
+// @Description  Permanently delete a todo from the authenticated user's trash
+// @Tags         Todo
+// @Produce      json
+// @Param        id   path      string  true  "Todo ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/trash/{id} [delete]
+func (handler TodoHandler) Purge(ctx *gin.Context) {
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Purge todo"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Purge todo"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.Purge(ctx, todoID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Purge todo request failed",
+			logger.F("operation", "Purge todo"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
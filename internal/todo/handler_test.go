@@ -0,0 +1,963 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Alfian57/golang-todo/pkg/jsonpatch"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// newTestRouter mounts the todo handlers behind a stub that authenticates every request as userID
+func newTestRouter(repository TodoRepository, userID uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	log := logger.NewNopLogger()
+	handler := NewTodoHandler(NewTodoService(repository, testTodoConfig, log, true), log)
+
+	r := gin.New()
+	authenticate := func(ctx *gin.Context) {
+		if userID != uuid.Nil {
+			ctx.Set("claims", &utils.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: userID.String()}})
+		}
+		ctx.Next()
+	}
+	todoGroup := r.Group("/todo", authenticate)
+	todoGroup.GET("/", handler.GetAll)
+	todoGroup.POST("/", handler.Create)
+	todoGroup.PUT("/:id", handler.Update)
+	todoGroup.PATCH("/:id", handler.Patch)
+	todoGroup.DELETE("/:id", handler.Delete)
+	todoGroup.POST("/bulk", handler.Bulk)
+	todoGroup.GET("/search", handler.Search)
+	todoGroup.GET("/upcoming", handler.Upcoming)
+	todoGroup.GET("/activity", handler.GetActivity)
+	todoGroup.GET("/board", handler.GetBoard)
+	todoGroup.GET("/workflow", handler.GetWorkflow)
+	todoGroup.PUT("/workflow", handler.UpdateWorkflow)
+	todoGroup.GET("/:id/transitions", handler.GetTransitions)
+	todoGroup.GET("/:id/history", handler.GetHistory)
+	todoGroup.PUT("/:id/project", handler.MoveTodo)
+	todoGroup.POST("/:id/move", handler.ReorderTodo)
+	todoGroup.GET("/:id", handler.Get)
+	todoGroup.PUT("/:id/parent", handler.SetParent)
+	todoGroup.POST("/:id/skip", handler.SkipOccurrence)
+	todoGroup.POST("/:id/complete-series", handler.CompleteSeries)
+	todoGroup.GET("/:id/checklist", handler.GetChecklist)
+	todoGroup.POST("/:id/checklist", handler.CreateChecklistItem)
+	todoGroup.PUT("/:id/checklist/order", handler.ReorderChecklist)
+	todoGroup.PUT("/:id/checklist/:item_id", handler.UpdateChecklistItem)
+	todoGroup.POST("/:id/checklist/:item_id/toggle", handler.ToggleChecklistItem)
+	todoGroup.DELETE("/:id/checklist/:item_id", handler.DeleteChecklistItem)
+	todoGroup.GET("/trash", handler.GetTrash)
+	todoGroup.POST("/:id/restore", handler.Restore)
+	todoGroup.DELETE("/trash/:id", handler.Purge)
+
+	undoGroup := r.Group("/undo", authenticate)
+	undoGroup.POST("/:token", handler.Undo)
+
+	tagGroup := r.Group("/tags", authenticate)
+	tagGroup.GET("", handler.GetTags)
+	tagGroup.POST("", handler.CreateTag)
+	tagGroup.GET("/:id", handler.GetTag)
+	tagGroup.PUT("/:id", handler.UpdateTag)
+	tagGroup.DELETE("/:id", handler.DeleteTag)
+
+	projectGroup := r.Group("/projects", authenticate)
+	projectGroup.GET("", handler.GetProjects)
+	projectGroup.POST("", handler.CreateProject)
+	projectGroup.PUT("/order", handler.ReorderProjects)
+	projectGroup.GET("/:id", handler.GetProject)
+	projectGroup.PUT("/:id", handler.UpdateProject)
+	projectGroup.POST("/:id/archive", handler.ArchiveProject)
+	projectGroup.POST("/:id/unarchive", handler.UnarchiveProject)
+	return r
+}
+
+func doRequest(r http.Handler, method string, path string, body any) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestTodoHandler(t *testing.T) {
+	userID := uuid.New()
+	missingID := uuid.New()
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		method     string
+		path       func(todo Todo) string
+		body       any
+		wantStatus int
+	}{
+		{
+			name:       "lists todos",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?limit=10&sort=title&completed=false" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects an out of range limit",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?limit=1000" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects an unknown sort",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?sort=password" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects a malformed date filter",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?created_from=yesterday" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "filters by due date in a time zone",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?overdue=true&due_within=7d&tz=Asia/Jakarta" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects an unknown time zone",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?due_today=true&tz=Mars/Olympus" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "lists upcoming todos",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/upcoming?days=14&tz=Europe/Berlin" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects too many upcoming days",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/upcoming?days=365" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "searches todos",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/search?q=walk*&completed=false&limit=5" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects a search without a query",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/search?limit=5" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "requires authentication",
+			userID:     uuid.Nil,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/" },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "creates a todo",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/todo/" },
+			body:       CreateTodoRequest{Title: "Buy milk"},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "creates a todo with a due date",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/todo/" },
+			body:       map[string]any{"title": "Pay rent", "due_at": "2025-03-01T00:00:00+07:00", "all_day": true},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "rejects all day without a due date",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/todo/" },
+			body:       map[string]any{"title": "Pay rent", "all_day": true},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "validates the create body",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/todo/" },
+			body:       CreateTodoRequest{Title: ""},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "updates a todo",
+			userID:     userID,
+			method:     http.MethodPut,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() },
+			body:       UpdateTodoRequest{Title: "Walk cat", Completed: true},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects a malformed todo id",
+			userID:     userID,
+			method:     http.MethodPut,
+			path:       func(Todo) string { return "/todo/42" },
+			body:       UpdateTodoRequest{Title: "Walk cat"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "hides todos of other users on delete",
+			userID:     uuid.New(),
+			method:     http.MethodDelete,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "deletes a todo",
+			userID:     userID,
+			method:     http.MethodDelete,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "filters by priority and status",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?priority=P0&priority=P2&status=backlog&sort=-priority" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects an unknown priority",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?priority=P7" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "moves a todo to another status",
+			userID:     userID,
+			method:     http.MethodPut,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() },
+			body:       UpdateTodoRequest{Title: "Walk dog", Status: "in_progress", Priority: "P1"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects a status outside the workflow",
+			userID:     userID,
+			method:     http.MethodPut,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() },
+			body:       UpdateTodoRequest{Title: "Walk dog", Status: "archived"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "lists the transitions of a todo",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/transitions" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "shows the board",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/board?limit=5&priority=P2" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "shows the workflow",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/workflow" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "rejects a workflow with a single status",
+			userID: userID,
+			method: http.MethodPut,
+			path:   func(Todo) string { return "/todo/workflow" },
+			body: UpdateWorkflowRequest{Statuses: []WorkflowStatusRequest{
+				{Key: "done", Name: "Done", Done: true},
+			}},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "updates the workflow",
+			userID: userID,
+			method: http.MethodPut,
+			path:   func(Todo) string { return "/todo/workflow" },
+			body: UpdateWorkflowRequest{Statuses: []WorkflowStatusRequest{
+				{Key: "backlog", Name: "Backlog"},
+				{Key: "done", Name: "Done", Done: true},
+			}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "filters by tags",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?tag=work&tag=home&tag_mode=all" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects an unknown tag mode",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?tag=work&tag_mode=some" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "tags a todo by name",
+			userID:     userID,
+			method:     http.MethodPut,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() },
+			body:       UpdateTodoRequest{Title: "Walk dog", TagNames: []string{"pets"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects an unknown tag id",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/todo/" },
+			body:       CreateTodoRequest{Title: "Walk dog", TagIDs: []uuid.UUID{uuid.New()}},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "lists tags",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/tags" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "creates a tag",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/tags" },
+			body:       CreateTagRequest{Name: "pets", Color: "#a0b1c2"},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "rejects a malformed tag color",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/tags" },
+			body:       CreateTagRequest{Name: "pets", Color: "blue"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "reports unknown tags",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/tags/" + uuid.NewString() },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rejects a malformed tag id",
+			userID:     userID,
+			method:     http.MethodDelete,
+			path:       func(Todo) string { return "/tags/42" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "filters by the inbox",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?project=inbox" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects a malformed project filter",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/?project=someday" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "moves a todo to the inbox",
+			userID:     userID,
+			method:     http.MethodPut,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/project" },
+			body:       MoveTodoRequest{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects moving a todo to an unknown project",
+			userID:     userID,
+			method:     http.MethodPut,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/project" },
+			body:       MoveTodoRequest{ProjectID: func() *uuid.UUID { id := uuid.New(); return &id }()},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "lists projects",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/projects?archived=true" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "creates a project",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/projects" },
+			body:       CreateProjectRequest{Name: "Work", Color: "#a0b1c2"},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "rejects a malformed project color",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/projects" },
+			body:       CreateProjectRequest{Name: "Work", Color: "blue"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "reports unknown projects",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/projects/" + uuid.NewString() + "/archive" },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rejects reordering unknown projects",
+			userID:     userID,
+			method:     http.MethodPut,
+			path:       func(Todo) string { return "/projects/order" },
+			body:       ReorderProjectsRequest{ProjectIDs: []uuid.UUID{uuid.New()}},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "lists the trash",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/trash?sort=title" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "only restores trashed todos",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/restore" },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "only purges trashed todos",
+			userID:     userID,
+			method:     http.MethodDelete,
+			path:       func(todo Todo) string { return "/todo/trash/" + todo.ID.String() },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rejects a malformed todo id on purge",
+			userID:     userID,
+			method:     http.MethodDelete,
+			path:       func(Todo) string { return "/todo/trash/42" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "gets a todo with its subtasks",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "gets a todo with its subtasks, tags and history",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "?include=subtasks,tags,history" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects an unknown include",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "?include=password" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "hides todos of other users",
+			userID:     uuid.New(),
+			method:     http.MethodGet,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rejects an unknown subtask mode on delete",
+			userID:     userID,
+			method:     http.MethodDelete,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "?subtasks=orphan" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "moves a todo to the top level",
+			userID:     userID,
+			method:     http.MethodPut,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/parent" },
+			body:       SetParentRequest{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects nesting a todo below itself",
+			userID:     userID,
+			method:     http.MethodPut,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/parent" },
+			body:       map[string]any{"parent_id": "self"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects skipping an occurrence of a todo that does not recur",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/skip" },
+			body:       SkipOccurrenceRequest{},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects a malformed occurrence date",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/skip" },
+			body:       SkipOccurrenceRequest{Date: "next monday"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects completing the series of a todo that does not recur",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/complete-series" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects an unknown recurrence time zone",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/todo/" },
+			body:       map[string]any{"title": "Pay rent", "due_at": "2025-03-01T09:00:00Z", "recurrence": "monthly", "timezone": "Mars/Olympus"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "creates a recurring todo",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/todo/" },
+			body:       map[string]any{"title": "Pay rent", "due_at": "2025-03-01T09:00:00Z", "recurrence": "monthly-on-day-1", "timezone": "Asia/Jakarta"},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "adds a checklist item",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/checklist" },
+			body:       CreateChecklistItemRequest{Text: "Leash"},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "rejects an empty checklist item",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/checklist" },
+			body:       CreateChecklistItemRequest{},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "lists the checklist",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/checklist" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "reports unknown checklist items",
+			userID: userID,
+			method: http.MethodPost,
+			path: func(todo Todo) string {
+				return "/todo/" + todo.ID.String() + "/checklist/" + uuid.NewString() + "/toggle"
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rejects a reorder without neighbours",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/move" },
+			body:       ReorderTodoRequest{},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "reports unknown neighbours",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/move" },
+			body:       ReorderTodoRequest{After: &missingID},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rejects a malformed checklist item id",
+			userID:     userID,
+			method:     http.MethodDelete,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/checklist/42" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "runs bulk operations",
+			userID: userID,
+			method: http.MethodPost,
+			path:   func(Todo) string { return "/todo/bulk" },
+			body: BulkTodoRequest{Operations: []BulkTodoOperation{
+				{Op: "create", Create: &CreateTodoRequest{Title: "Buy milk"}},
+			}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "reports rolled back bulk operations",
+			userID: userID,
+			method: http.MethodPost,
+			path:   func(Todo) string { return "/todo/bulk" },
+			body: BulkTodoRequest{Operations: []BulkTodoOperation{
+				{Op: "create", Create: &CreateTodoRequest{Title: "Buy milk"}},
+				{Op: "complete", ID: &missingID},
+			}},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects an empty bulk request",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/todo/bulk" },
+			body:       BulkTodoRequest{},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "rejects a bulk update without an id",
+			userID: userID,
+			method: http.MethodPost,
+			path:   func(Todo) string { return "/todo/bulk" },
+			body: BulkTodoRequest{Operations: []BulkTodoOperation{
+				{Op: "update", Fields: []byte(`{"title": "Buy milk"}`)},
+			}},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "gets the history of a todo",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/history?limit=5" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "reports the history of an unknown todo",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/" + missingID.String() + "/history" },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "rejects a malformed history cursor",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(todo Todo) string { return "/todo/" + todo.ID.String() + "/history?cursor=nope" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "gets the activity feed",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/activity?limit=10" },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects an out of range activity limit",
+			userID:     userID,
+			method:     http.MethodGet,
+			path:       func(Todo) string { return "/todo/activity?limit=500" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "rejects a malformed undo token",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/undo/42" },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "reports unknown undo tokens",
+			userID:     userID,
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/undo/" + missingID.String() },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "requires authentication to undo",
+			method:     http.MethodPost,
+			path:       func(Todo) string { return "/undo/" + missingID.String() },
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := NewMemoryTodoRepository()
+			todo := seedTodos(t, repository, userID, "Walk dog")[0]
+			r := newTestRouter(repository, tt.userID)
+
+			recorder := doRequest(r, tt.method, tt.path(todo), tt.body)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestTodoHandlerPatch(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		contentType   string
+		body          string
+		wantStatus    int
+		wantTitle     string
+		wantCompleted bool
+	}{
+		{
+			name:          "completes a todo with a merge patch",
+			contentType:   jsonpatch.MergePatchType,
+			body:          `{"completed": true}`,
+			wantStatus:    http.StatusOK,
+			wantTitle:     "Walk dog",
+			wantCompleted: true,
+		},
+		{
+			name:        "renames a todo with a JSON patch",
+			contentType: jsonpatch.JSONPatchType,
+			body:        `[{"op": "test", "path": "/title", "value": "Walk dog"}, {"op": "replace", "path": "/title", "value": "Walk cat"}]`,
+			wantStatus:  http.StatusOK,
+			wantTitle:   "Walk cat",
+		},
+		{
+			name:        "reads plain JSON as a merge patch",
+			contentType: "application/json",
+			body:        `{"description": "Around the park"}`,
+			wantStatus:  http.StatusOK,
+			wantTitle:   "Walk dog",
+		},
+		{
+			name:        "rejects an unsupported media type",
+			contentType: "text/plain",
+			body:        `{"completed": true}`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "rejects a malformed patch",
+			contentType: jsonpatch.JSONPatchType,
+			body:        `{"op": "replace"}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "rejects a patch that fails validation",
+			contentType: jsonpatch.MergePatchType,
+			body:        `{"title": null}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "rejects unknown fields",
+			contentType: jsonpatch.MergePatchType,
+			body:        `{"user_id": "` + uuid.NewString() + `"}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "reports a failed test",
+			contentType: jsonpatch.JSONPatchType,
+			body:        `[{"op": "test", "path": "/title", "value": "Walk cat"}]`,
+			wantStatus:  http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := NewMemoryTodoRepository()
+			todo := seedTodos(t, repository, userID, "Walk dog")[0]
+			r := newTestRouter(repository, userID)
+
+			req := httptest.NewRequest(http.MethodPatch, "/todo/"+todo.ID.String(), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, req)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data UpdateTodoResponse `json:"data"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid response body: %v", err)
+			}
+			if body.Data.Todo.Title != tt.wantTitle || body.Data.Todo.Completed != tt.wantCompleted {
+				t.Errorf("todo = %q completed %v, want %q completed %v", body.Data.Todo.Title, body.Data.Todo.Completed, tt.wantTitle, tt.wantCompleted)
+			}
+		})
+	}
+}
+
+func TestTodoHandlerETags(t *testing.T) {
+	userID := uuid.New()
+	repository := NewMemoryTodoRepository()
+	todo := seedTodos(t, repository, userID, "Walk dog")[0]
+	r := newTestRouter(repository, userID)
+
+	request := func(method string, path string, body any, header string, value string) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, req)
+		return recorder
+	}
+	path := "/todo/" + todo.ID.String()
+
+	recorder := request(http.MethodGet, path, nil, "", "")
+	if etag := recorder.Header().Get("ETag"); recorder.Code != http.StatusOK || etag != `"1"` {
+		t.Fatalf("get = %d with ETag %s, want 200 with \"1\"", recorder.Code, etag)
+	}
+
+	list := request(http.MethodGet, "/todo/", nil, "", "")
+	listETag := list.Header().Get("ETag")
+	if list.Code != http.StatusOK || listETag == "" {
+		t.Fatalf("list = %d with ETag %q, want 200 with an ETag", list.Code, listETag)
+	}
+	recorder = request(http.MethodGet, "/todo/", nil, "If-None-Match", listETag)
+	if recorder.Code != http.StatusNotModified || recorder.Body.Len() != 0 {
+		t.Errorf("unchanged list = %d with %d bytes, want 304 without a body", recorder.Code, recorder.Body.Len())
+	}
+
+	recorder = request(http.MethodPut, path, UpdateTodoRequest{Title: "Walk cat"}, "If-Match", `"1"`)
+	if etag := recorder.Header().Get("ETag"); recorder.Code != http.StatusOK || etag != `"2"` {
+		t.Fatalf("update = %d with ETag %s, want 200 with \"2\"", recorder.Code, etag)
+	}
+	recorder = request(http.MethodPut, path, UpdateTodoRequest{Title: "Walk bird"}, "If-Match", `"1"`)
+	if recorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale update = %d, want %d", recorder.Code, http.StatusPreconditionFailed)
+	}
+	recorder = request(http.MethodDelete, path, nil, "If-Match", `"1"`)
+	if recorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale delete = %d, want %d", recorder.Code, http.StatusPreconditionFailed)
+	}
+	recorder = request(http.MethodPut, path+"/parent", SetParentRequest{}, "If-Match", `"1"`)
+	if recorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("stale set parent = %d, want %d", recorder.Code, http.StatusPreconditionFailed)
+	}
+	recorder = request(http.MethodPut, path+"/parent", SetParentRequest{}, "If-Match", `"2"`)
+	if etag := recorder.Header().Get("ETag"); recorder.Code != http.StatusOK || etag != `"3"` {
+		t.Errorf("set parent = %d with ETag %s, want 200 with \"3\"", recorder.Code, etag)
+	}
+
+	recorder = request(http.MethodGet, "/todo/", nil, "If-None-Match", listETag)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("ETag") == listETag {
+		t.Errorf("changed list = %d with ETag %s, want 200 with a new ETag", recorder.Code, recorder.Header().Get("ETag"))
+	}
+}
+
+func TestTodoHandlerGetAllResponseShape(t *testing.T) {
+	userID := uuid.New()
+	repository := NewMemoryTodoRepository()
+	seedTodos(t, repository, userID, "a", "b", "c")
+	r := newTestRouter(repository, userID)
+
+	recorder := doRequest(r, http.MethodGet, "/todo/?limit=2", nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var body struct {
+		Data GetTodosResponse `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+	if len(body.Data.Todos) != 2 || body.Data.Meta.Total != 3 || body.Data.Meta.NextCursor == "" {
+		t.Errorf("unexpected page %+v", body.Data)
+	}
+}
+
+// A single todo sends the same keys as the todos of every other response, include only adds to them
+func TestTodoHandlerGetResponseShape(t *testing.T) {
+	userID := uuid.New()
+	repository := NewMemoryTodoRepository()
+	todo := seedTodos(t, repository, userID, "a")[0]
+	r := newTestRouter(repository, userID)
+
+	tests := []struct {
+		include     string
+		wantHistory bool
+	}{
+		{include: "", wantHistory: false},
+		{include: "tags", wantHistory: false},
+		{include: "subtasks,tags,history", wantHistory: true},
+	}
+
+	for _, tt := range tests {
+		recorder := doRequest(r, http.MethodGet, "/todo/"+todo.ID.String()+"?include="+tt.include, nil)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("include %q status = %d: %s", tt.include, recorder.Code, recorder.Body.String())
+		}
+
+		var body struct {
+			Data struct {
+				Todo map[string]json.RawMessage `json:"todo"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid response body: %v", err)
+		}
+		for _, key := range []string{"id", "title", "tags", "subtasks", "checklist", "progress"} {
+			if _, ok := body.Data.Todo[key]; !ok {
+				t.Errorf("include %q: todo has no %q key: %s", tt.include, key, recorder.Body.String())
+			}
+		}
+		if string(body.Data.Todo["tags"]) != "[]" {
+			t.Errorf("include %q: tags = %s, want []", tt.include, body.Data.Todo["tags"])
+		}
+		if _, ok := body.Data.Todo["history"]; ok != tt.wantHistory {
+			t.Errorf("include %q: history sent %v, want %v", tt.include, ok, tt.wantHistory)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package todo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Actions recorded in the history of a todo
+const (
+	HistoryCreate  = "create"
+	HistoryUpdate  = "update"
+	HistoryDelete  = "delete"
+	HistoryRestore = "restore"
+	HistoryPurge   = "purge"
+)
+
+const defaultHistoryLimit = 20
+
+var ErrInvalidHistoryCursor = errors.New("invalid history cursor")
+
+// TodoHistory is an immutable record of a change made to a todo
+// It outlives the todo, so Title keeps the title the todo had after the change
+type TodoHistory struct {
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	TodoID uuid.UUID
+
+	// UserID owns the todo, ActorID made the change
+	UserID  uuid.UUID
+	ActorID uuid.UUID
+
+	Action    string
+	Title     string
+	Changes   []FieldChange `gorm:"serializer:json"`
+	CreatedAt time.Time
+}
+
+func (TodoHistory) TableName() string {
+	return "todo_history"
+}
+
+func (history *TodoHistory) BeforeCreate(tx *gorm.DB) error {
+	if history.ID == uuid.Nil {
+		history.ID = uuid.New()
+	}
+	return nil
+}
+
+// FieldChange is the value of a field before and after a change, formatted like the field of a TodoResponse
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// NewTodoHistory records the fields in which the todo differs from before
+// A created todo is compared with an empty one, so only the fields it sets are recorded
+func NewTodoHistory(action string, before Todo, todo Todo, actorID uuid.UUID) TodoHistory {
+	history := TodoHistory{
+		TodoID:  todo.ID,
+		UserID:  todo.UserID,
+		ActorID: actorID,
+		Action:  action,
+		Title:   todo.Title,
+	}
+	for _, field := range changedColumns(before, todo) {
+		history.Changes = append(history.Changes, FieldChange{
+			Field: field,
+			From:  historyValue(before, field),
+			To:    historyValue(todo, field),
+		})
+	}
+	return history
+}
+
+// historyValue returns a field of the todo named after its column
+func historyValue(todo Todo, field string) any {
+	switch field {
+	case "title":
+		return todo.Title
+	case "description":
+		return todo.Description
+	case "completed":
+		return todo.Completed
+	case "project_id":
+		return todo.ProjectID
+	case "position":
+		return todo.Position
+	case "parent_id":
+		return todo.ParentID
+	case "auto_complete":
+		return todo.AutoComplete
+	case "due_at":
+		return formatTime(todo.DueAt)
+	case "all_day":
+		return todo.AllDay
+	case "remind_at":
+		return formatTime(todo.RemindAt)
+	case "priority":
+		return PriorityLabel(todo.Priority)
+	case "status":
+		return todo.Status
+	case "recurrence":
+		return todo.Recurrence
+	case "timezone":
+		return todo.Timezone
+	case "series_id":
+		return todo.SeriesID
+	case "series_start":
+		return formatTime(todo.SeriesStart)
+	case "skipped_dates":
+		return append([]string{}, todo.SkippedDates...)
+	case tagsColumn:
+		names := make([]string, 0, len(todo.Tags))
+		for _, tag := range todo.Tags {
+			names = append(names, tag.Name)
+		}
+		return names
+	}
+	return nil
+}
+
+// HistoryFilter selects a page of a user's history, newest first, optionally of a single todo
+type HistoryFilter struct {
+	TodoID *uuid.UUID
+	Cursor *HistoryCursor
+	Limit  int
+}
+
+// HistoryCursor is the keyset position of the last history record returned in a page
+type HistoryCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// NewHistoryCursor builds the cursor pointing right after the given record
+func NewHistoryCursor(history TodoHistory) HistoryCursor {
+	return HistoryCursor{
+		CreatedAt: history.CreatedAt,
+		ID:        history.ID,
+	}
+}
+
+// Encode serializes the cursor into an opaque URL-safe string
+func (cursor HistoryCursor) Encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeHistoryCursor parses a cursor produced by HistoryCursor.Encode
+func DecodeHistoryCursor(value string) (HistoryCursor, error) {
+	var cursor HistoryCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, ErrInvalidHistoryCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, ErrInvalidHistoryCursor
+	}
+	if cursor.CreatedAt.IsZero() || cursor.ID == uuid.Nil {
+		return cursor, ErrInvalidHistoryCursor
+	}
+
+	return cursor, nil
+}
+
+// isAfter reports whether the record comes after the cursor, newest first
+func (cursor HistoryCursor) isAfter(history TodoHistory) bool {
+	if !history.CreatedAt.Equal(cursor.CreatedAt) {
+		return history.CreatedAt.Before(cursor.CreatedAt)
+	}
+	return history.ID.String() < cursor.ID.String()
+}
@@ -0,0 +1,111 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      Get todo history
+// @Description  Get the changes made to a todo of the authenticated user, newest first, with who made them and the old and new value of every changed field. Todos in the trash keep their history
+// @Tags         Todo
+// @Produce      json
+// @Param        id      path      string  true   "Todo ID"
+// @Param        cursor  query     string  false  "Cursor from the next_cursor of a previous page"
+// @Param        limit   query     int     false  "Page size (1-100, default 20)"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetHistoryResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/history [get]
+func (handler TodoHandler) GetHistory(ctx *gin.Context) {
+	var req GetHistoryRequest
+	if !utils.ValidateQuery(ctx, &req) {
+		return
+	}
+
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Get todo history"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get todo history"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetHistory(ctx, todoID, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get todo history request failed",
+			logger.F("operation", "Get todo history"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Get activity
+// @Description  Get the changes made to all todos of the authenticated user, newest first, including todos that have since been purged
+// @Tags         Todo
+// @Produce      json
+// @Param        cursor  query     string  false  "Cursor from the next_cursor of a previous page"
+// @Param        limit   query     int     false  "Page size (1-100, default 20)"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetHistoryResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/activity [get]
+func (handler TodoHandler) GetActivity(ctx *gin.Context) {
+	var req GetHistoryRequest
+	if !utils.ValidateQuery(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get activity"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetActivity(ctx, userID, req)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get activity request failed",
+			logger.F("operation", "Get activity"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
@@ -0,0 +1,112 @@
+package todo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// inTransaction runs fn with a copy of the service whose repository is bound to a transaction,
+// so a change and its history are saved or rolled back together
+func (service TodoService) inTransaction(ctx context.Context, fn func(service TodoService) error) error {
+	return service.todoRepository.Transaction(ctx, func(repository TodoRepository) error {
+		transactional := service
+		transactional.todoRepository = repository
+		return fn(transactional)
+	})
+}
+
+// recordHistory appends the change the actor made to a todo to its history, an update that changed nothing isn't recorded
+func (service TodoService) recordHistory(ctx context.Context, action string, before Todo, todo Todo, actorID uuid.UUID) error {
+	history := NewTodoHistory(action, before, todo, actorID)
+	if action == HistoryUpdate && len(history.Changes) == 0 {
+		return nil
+	}
+	return service.todoRepository.AppendTodoHistory(ctx, &history)
+}
+
+// recordSubtasksHistory records the action for each of the subtasks it took along with their parent
+func (service TodoService) recordSubtasksHistory(ctx context.Context, action string, subtasks []Todo, actorID uuid.UUID) error {
+	for _, subtask := range subtasks {
+		if err := service.recordHistory(ctx, action, subtask, subtask, actorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHistory lists the changes made to a todo of the user, newest first
+// The history of a todo in the trash can be read as well
+func (service TodoService) GetHistory(ctx context.Context, todoID uuid.UUID, req GetHistoryRequest, userID uuid.UUID) models.Response {
+	_, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_, err = service.todoRepository.FindTrashedTodoByIDAndUserID(ctx, todoID, userID)
+	}
+	if err != nil {
+		service.log.Error("Failed to find todo",
+			logger.F("operation", "Get todo history"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found", err, service.isDebug)
+	}
+
+	return service.history(ctx, userID, &todoID, req, "Get todo history")
+}
+
+// GetActivity lists the changes made to all todos of the user, newest first, including todos purged since
+func (service TodoService) GetActivity(ctx context.Context, userID uuid.UUID, req GetHistoryRequest) models.Response {
+	return service.history(ctx, userID, nil, req, "Get activity")
+}
+
+func (service TodoService) history(ctx context.Context, userID uuid.UUID, todoID *uuid.UUID, req GetHistoryRequest, operation string) models.Response {
+	filter := HistoryFilter{
+		TodoID: todoID,
+		Limit:  req.Limit,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultHistoryLimit
+	}
+	if req.Cursor != "" {
+		cursor, err := DecodeHistoryCursor(req.Cursor)
+		if err != nil {
+			return utils.UnprocessableEntityResponse("Invalid cursor", err, service.isDebug)
+		}
+		filter.Cursor = &cursor
+	}
+
+	history, err := service.todoRepository.FindTodoHistoryByUserID(ctx, userID, filter)
+	if err != nil {
+		service.log.Error("Failed to get todo history",
+			logger.F("operation", operation),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get todo history", err, service.isDebug)
+	}
+
+	// The repository fetches one extra record to tell whether another page exists
+	meta := HistoryMeta{
+		Limit: filter.Limit,
+	}
+	if len(history) > filter.Limit {
+		history = history[:filter.Limit]
+		meta.HasMore = true
+		meta.NextCursor = NewHistoryCursor(history[len(history)-1]).Encode()
+	}
+
+	responseData := GetHistoryResponse{
+		History: make([]TodoHistoryResponse, 0, len(history)),
+		Meta:    meta,
+	}
+	for _, record := range history {
+		responseData.History = append(responseData.History, NewTodoHistoryResponse(record))
+	}
+	return utils.OkResponse("Todo history retrieved successfully", responseData)
+}
@@ -0,0 +1,216 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/Alfian57/golang-todo/pkg/jsonpatch"
+	"github.com/google/uuid"
+)
+
+func historyActions(history []TodoHistoryResponse) []string {
+	actions := make([]string, 0, len(history))
+	for _, record := range history {
+		actions = append(actions, record.Action+" "+record.Title)
+	}
+	return actions
+}
+
+func fieldChange(record TodoHistoryResponse, field string) (FieldChange, bool) {
+	for _, change := range record.Changes {
+		if change.Field == field {
+			return change, true
+		}
+	}
+	return FieldChange{}, false
+}
+
+func TestTodoServiceHistory(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			service := newTestService(newRepository(t))
+
+			getHistory := func(todoID uuid.UUID, req GetHistoryRequest) GetHistoryResponse {
+				t.Helper()
+				response := service.GetHistory(ctx, todoID, req, userID)
+				if response.StatusCode != http.StatusOK {
+					t.Fatalf("history status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+				}
+				return response.Data.(GetHistoryResponse)
+			}
+			getActivity := func() []TodoHistoryResponse {
+				t.Helper()
+				response := service.GetActivity(ctx, userID, GetHistoryRequest{Limit: 100})
+				if response.StatusCode != http.StatusOK {
+					t.Fatalf("activity status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+				}
+				return response.Data.(GetHistoryResponse).History
+			}
+
+			walk := createTodo(t, service, userID, CreateTodoRequest{Title: "Walk dog", TagNames: []string{"errand"}})
+			patch := mustParsePatch(t, jsonpatch.MergePatchType, `{"title": "Walk cat", "completed": true}`)
+			if response := service.Patch(ctx, walk.Id, patch, "", userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("patch status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			// Saving the same values again changes nothing, so it isn't recorded
+			patch = mustParsePatch(t, jsonpatch.MergePatchType, `{"title": "Walk cat"}`)
+			if response := service.Patch(ctx, walk.Id, patch, "", userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("unchanged patch status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if response := service.Delete(ctx, walk.Id, DeleteTodoRequest{}, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("delete status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			// A todo in the trash keeps its history
+			getHistory(walk.Id, GetHistoryRequest{})
+			if response := service.Restore(ctx, walk.Id, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("restore status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+
+			history := getHistory(walk.Id, GetHistoryRequest{})
+			want := []string{"restore Walk cat", "delete Walk cat", "update Walk cat", "create Walk dog"}
+			if got := historyActions(history.History); !slices.Equal(got, want) {
+				t.Fatalf("history = %v, want %v", got, want)
+			}
+			for _, record := range history.History {
+				if record.ActorID != userID || record.TodoID != walk.Id {
+					t.Errorf("%s record has actor %s todo %s, want %s %s", record.Action, record.ActorID, record.TodoID, userID, walk.Id)
+				}
+			}
+
+			update := history.History[2]
+			if change, ok := fieldChange(update, "title"); !ok || change.From != "Walk dog" || change.To != "Walk cat" {
+				t.Errorf("title change = %+v, want Walk dog to Walk cat", change)
+			}
+			if change, ok := fieldChange(update, "completed"); !ok || change.From != false || change.To != true {
+				t.Errorf("completed change = %+v, want false to true", change)
+			}
+			if _, ok := fieldChange(update, "description"); ok {
+				t.Errorf("unchanged description recorded in %+v", update.Changes)
+			}
+			create := history.History[3]
+			if change, ok := fieldChange(create, "tags"); !ok || fmt.Sprint(change.To) != "[errand]" {
+				t.Errorf("created tags = %+v, want [errand]", change)
+			}
+			if len(history.History[1].Changes) != 0 {
+				t.Errorf("delete changes = %+v, want none", history.History[1].Changes)
+			}
+
+			// Pages follow each other without gaps or repeats
+			page := getHistory(walk.Id, GetHistoryRequest{Limit: 3})
+			if !page.Meta.HasMore || len(page.History) != 3 {
+				t.Fatalf("first page has %d records, has more %v, want 3 and more", len(page.History), page.Meta.HasMore)
+			}
+			page = getHistory(walk.Id, GetHistoryRequest{Limit: 3, Cursor: page.Meta.NextCursor})
+			if got := historyActions(page.History); page.Meta.HasMore || !slices.Equal(got, want[3:]) {
+				t.Errorf("second page = %v has more %v, want %v and no more", got, page.Meta.HasMore, want[3:])
+			}
+
+			// A purged todo is gone, but its changes stay in the activity feed
+			milk := createTodo(t, service, userID, CreateTodoRequest{Title: "Buy milk"})
+			service.Delete(ctx, milk.Id, DeleteTodoRequest{}, userID)
+			if response := service.Purge(ctx, milk.Id, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("purge status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if response := service.GetHistory(ctx, milk.Id, GetHistoryRequest{}, userID); response.StatusCode != http.StatusNotFound {
+				t.Errorf("purged todo history status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+			activity := getActivity()
+			want = append([]string{"purge Buy milk", "delete Buy milk", "create Buy milk"}, want...)
+			if got := historyActions(activity); !slices.Equal(got, want) {
+				t.Errorf("activity = %v, want %v", got, want)
+			}
+
+			if response := service.GetHistory(ctx, walk.Id, GetHistoryRequest{}, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("history of another user's todo status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+			if response := service.GetActivity(ctx, uuid.New(), GetHistoryRequest{}); len(response.Data.(GetHistoryResponse).History) != 0 {
+				t.Errorf("activity of another user = %v, want none", response.Data)
+			}
+			if response := service.GetActivity(ctx, userID, GetHistoryRequest{Cursor: "nope"}); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("malformed cursor status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+		})
+	}
+}
+
+// Subtasks that go to the trash, come back or move up to the parent along with a todo get records of their own
+func TestTodoServiceSubtaskHistory(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			service := newTestService(newRepository(t))
+
+			getHistory := func(todoID uuid.UUID) []TodoHistoryResponse {
+				t.Helper()
+				response := service.GetHistory(ctx, todoID, GetHistoryRequest{}, userID)
+				if response.StatusCode != http.StatusOK {
+					t.Fatalf("history status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+				}
+				return response.Data.(GetHistoryResponse).History
+			}
+
+			report := createTodo(t, service, userID, CreateTodoRequest{Title: "Write report"})
+			draft := createTodo(t, service, userID, CreateTodoRequest{Title: "Draft", ParentID: &report.Id})
+			outline := createTodo(t, service, userID, CreateTodoRequest{Title: "Outline", ParentID: &draft.Id})
+			if response := service.Delete(ctx, report.Id, DeleteTodoRequest{}, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("delete status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if response := service.Restore(ctx, report.Id, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("restore status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			for _, subtask := range []TodoResponse{draft, outline} {
+				want := []string{"restore " + subtask.Title, "delete " + subtask.Title, "create " + subtask.Title}
+				if got := historyActions(getHistory(subtask.Id)); !slices.Equal(got, want) {
+					t.Errorf("history of %s = %v, want %v", subtask.Title, got, want)
+				}
+			}
+
+			// A reparented subtask records the move to its new parent
+			if response := service.Delete(ctx, draft.Id, DeleteTodoRequest{Subtasks: SubtasksReparent}, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("reparent delete status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			history := getHistory(outline.Id)
+			if got := historyActions(history); len(got) != 4 || got[0] != "update Outline" {
+				t.Fatalf("history of Outline = %v, want the reparenting update first", got)
+			}
+			change, ok := fieldChange(history[0], "parent_id")
+			if !ok || fmt.Sprint(change.From) != draft.Id.String() || fmt.Sprint(change.To) != report.Id.String() {
+				t.Errorf("parent change = %+v, want %s to %s", change, draft.Id, report.Id)
+			}
+		})
+	}
+}
+
+// History is written in the transaction of the change, so a change that is rolled back leaves no record behind
+func TestTodoServiceHistoryRollsBack(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			service := newTestService(newRepository(t))
+
+			missingID := uuid.New()
+			response := service.Bulk(ctx, BulkTodoRequest{Operations: []BulkTodoOperation{
+				{Op: "create", Create: &CreateTodoRequest{Title: "Buy milk"}},
+				{Op: "complete", ID: &missingID},
+			}}, userID)
+			if response.StatusCode != http.StatusUnprocessableEntity {
+				t.Fatalf("bulk status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+
+			activity := service.GetActivity(ctx, userID, GetHistoryRequest{}).Data.(GetHistoryResponse).History
+			if len(activity) != 0 {
+				t.Errorf("activity = %v, want none", historyActions(activity))
+			}
+		})
+	}
+}
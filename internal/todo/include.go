@@ -0,0 +1,36 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+)
+
+// Expansions of a single todo, requested with ?include=subtasks,tags,history
+// The tree of subtasks and the tags are always sent, asking for them is accepted and changes nothing
+const (
+	IncludeSubtasks = "subtasks"
+	IncludeTags     = "tags"
+	IncludeHistory  = "history"
+)
+
+var ErrUnknownInclude = errors.New("unknown include")
+
+// TodoIncludes are the expansions requested for a single todo
+type TodoIncludes struct {
+	History bool
+}
+
+// ParseTodoIncludes parses a comma separated include value, blank entries are ignored
+func ParseTodoIncludes(value string) (TodoIncludes, error) {
+	var includes TodoIncludes
+	for _, include := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(include)) {
+		case "", IncludeSubtasks, IncludeTags:
+		case IncludeHistory:
+			includes.History = true
+		default:
+			return TodoIncludes{}, ErrUnknownInclude
+		}
+	}
+	return includes, nil
+}
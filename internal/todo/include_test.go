@@ -0,0 +1,102 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseTodoIncludes(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    TodoIncludes
+		wantErr bool
+	}{
+		{value: "", want: TodoIncludes{}},
+		{value: "tags", want: TodoIncludes{}},
+		{value: "tags, History,,", want: TodoIncludes{History: true}},
+		{value: "subtasks,tags,history", want: TodoIncludes{History: true}},
+		{value: "subtasks", want: TodoIncludes{}},
+		{value: "tags,password", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseTodoIncludes(test.value)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseTodoIncludes(%q) error = %v, want error %v", test.value, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseTodoIncludes(%q) = %+v, want %+v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestTodoServiceGet(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			service := newTestService(newRepository(t))
+
+			report := createTodo(t, service, userID, CreateTodoRequest{Title: "Write report", TagNames: []string{"work"}})
+			createTodo(t, service, userID, CreateTodoRequest{Title: "Draft", ParentID: &report.Id})
+			response := service.Update(ctx, report.Id, UpdateTodoRequest{Title: report.Title, Status: "in_progress"}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("update status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+			}
+
+			response = service.Get(ctx, report.Id, GetTodoRequest{}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("get status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+			}
+			detail := response.Data.(GetTodoResponse).Todo
+			if detail.Title != "Write report" {
+				t.Errorf("title = %q, want %q", detail.Title, "Write report")
+			}
+			// The tree of subtasks comes without asking
+			if len(detail.Subtasks) != 1 || detail.Subtasks[0].Title != "Draft" {
+				t.Errorf("subtasks = %v, want [Draft]", detail.Subtasks)
+			}
+			if detail.Progress != (SubtaskProgressResponse{Done: 0, Total: 1}) {
+				t.Errorf("progress = %v, want 0 of 1 done", detail.Progress)
+			}
+			// The tags come without asking too, like in every other todo response
+			if len(detail.Tags) != 1 || detail.Tags[0].Name != "work" {
+				t.Errorf("tags = %v, want [work]", detail.Tags)
+			}
+			if detail.History != nil {
+				t.Errorf("history sent without include: %+v", detail.History)
+			}
+
+			response = service.Get(ctx, report.Id, GetTodoRequest{Include: "subtasks,tags,history"}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("get with includes status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+			}
+			detail = response.Data.(GetTodoResponse).Todo
+			if len(detail.Subtasks) != 1 || len(detail.Tags) != 1 {
+				t.Errorf("subtasks = %v tags = %v, want the same as without include", detail.Subtasks, detail.Tags)
+			}
+			if detail.History == nil || len(*detail.History) != 2 {
+				t.Fatalf("history = %v, want the update and the create", detail.History)
+			}
+			if update := (*detail.History)[0]; update.Action != HistoryUpdate || update.TodoID != report.Id {
+				t.Errorf("newest change = %+v, want the update of the report", update)
+			} else if change, ok := fieldChange(update, "status"); !ok || change.To != "in_progress" {
+				t.Errorf("status change = %+v, want to in_progress", change)
+			}
+
+			response = service.Get(ctx, report.Id, GetTodoRequest{Include: "password"}, userID)
+			if response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("unknown include status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+			response = service.Get(ctx, report.Id, GetTodoRequest{Include: "tags"}, uuid.New())
+			if response.StatusCode != http.StatusNotFound {
+				t.Errorf("todo of another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package todo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/module"
+)
+
+// NewPurgeTrashJob permanently deletes the todos that have been in the trash for longer than retention
+func NewPurgeTrashJob(todoRepository TodoRepository, retention time.Duration, interval time.Duration, log logger.Logger) module.Job {
+	return module.Job{
+		Name:     "purge-trash",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			purged, err := todoRepository.PurgeTodosDeletedBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return err
+			}
+			if purged > 0 {
+				log.Info("Purged trashed todos",
+					logger.F("operation", "Purge trash"),
+					logger.F("count", purged),
+				)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/google/uuid"
+)
+
+func TestPurgeTrashJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		retention  time.Duration
+		wantPurged bool
+	}{
+		{name: "keeps todos within the retention period", retention: time.Hour, wantPurged: false},
+		{name: "purges todos past the retention period", retention: 0, wantPurged: true},
+	}
+
+	for name, newRepository := range testRepositories() {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				repository := newRepository(t)
+				userID := uuid.New()
+				todos := seedTodos(t, repository, userID, "Walk dog", "Call mom")
+				if _, err := repository.DeleteTodo(ctx, &todos[0], SubtasksCascade); err != nil {
+					t.Fatalf("failed to trash todo: %v", err)
+				}
+
+				job := NewPurgeTrashJob(repository, tt.retention, time.Minute, logger.NewNopLogger())
+				if err := job.Run(ctx); err != nil {
+					t.Fatalf("job failed: %v", err)
+				}
+
+				_, err := repository.FindTrashedTodoByIDAndUserID(ctx, todos[0].ID, userID)
+				if purged := err != nil; purged != tt.wantPurged {
+					t.Errorf("trashed todo purged = %v, want %v", purged, tt.wantPurged)
+				}
+				if _, err := repository.FindTodoByIDAndUserID(ctx, todos[1].ID, userID); err != nil {
+					t.Errorf("active todo was purged: %v", err)
+				}
+			})
+		}
+	}
+}
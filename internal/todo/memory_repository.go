@@ -0,0 +1,1115 @@
+package todo
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// MemoryTodoRepository is a thread-safe in-memory TodoRepository
+// It mirrors the semantics of the database implementation and is meant for tests
+type MemoryTodoRepository struct {
+	mu          sync.RWMutex
+	todos       map[uuid.UUID]Todo
+	transitions []TodoTransition
+	history     []TodoHistory
+	undos       map[uuid.UUID]TodoUndo
+	workflows   map[uuid.UUID]Workflow
+	tags        map[uuid.UUID]Tag
+	todoTags    map[uuid.UUID][]uuid.UUID
+	projects    map[uuid.UUID]Project
+	checklist   map[uuid.UUID]ChecklistItem
+}
+
+var _ TodoRepository = (*MemoryTodoRepository)(nil)
+
+func NewMemoryTodoRepository() *MemoryTodoRepository {
+	return &MemoryTodoRepository{
+		todos:     make(map[uuid.UUID]Todo),
+		workflows: make(map[uuid.UUID]Workflow),
+		undos:     make(map[uuid.UUID]TodoUndo),
+		tags:      make(map[uuid.UUID]Tag),
+		todoTags:  make(map[uuid.UUID][]uuid.UUID),
+		projects:  make(map[uuid.UUID]Project),
+		checklist: make(map[uuid.UUID]ChecklistItem),
+	}
+}
+
+// Transaction takes a snapshot of the repository and restores it when fn fails
+// Unlike a database transaction it doesn't isolate fn, so changes made concurrently are rolled back as well
+func (repository *MemoryTodoRepository) Transaction(ctx context.Context, fn func(repository TodoRepository) error) error {
+	repository.mu.RLock()
+	snapshot := MemoryTodoRepository{
+		todos:       maps.Clone(repository.todos),
+		transitions: slices.Clone(repository.transitions),
+		history:     slices.Clone(repository.history),
+		undos:       maps.Clone(repository.undos),
+		workflows:   maps.Clone(repository.workflows),
+		tags:        maps.Clone(repository.tags),
+		todoTags:    maps.Clone(repository.todoTags),
+		projects:    maps.Clone(repository.projects),
+		checklist:   maps.Clone(repository.checklist),
+	}
+	repository.mu.RUnlock()
+
+	err := fn(repository)
+	if err != nil {
+		repository.mu.Lock()
+		repository.todos = snapshot.todos
+		repository.transitions = snapshot.transitions
+		repository.history = snapshot.history
+		repository.undos = snapshot.undos
+		repository.workflows = snapshot.workflows
+		repository.tags = snapshot.tags
+		repository.todoTags = snapshot.todoTags
+		repository.projects = snapshot.projects
+		repository.checklist = snapshot.checklist
+		repository.mu.Unlock()
+	}
+	return err
+}
+
+func (repository *MemoryTodoRepository) FindAllTodoByUserID(ctx context.Context, userID string, filter TodoFilter) ([]Todo, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	todos, err := repository.filter(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sortTodos(todos, filter.Sort)
+
+	if filter.Cursor != nil {
+		value, err := sortArg(filter.Sort.Field, filter.Cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		start := len(todos)
+		for i, todo := range todos {
+			if isAfterCursor(todo, filter.Sort, value, filter.Cursor.ID) {
+				start = i
+				break
+			}
+		}
+		todos = todos[start:]
+	}
+
+	if len(todos) > filter.Limit+1 {
+		todos = todos[:filter.Limit+1]
+	}
+	return todos, nil
+}
+
+func (repository *MemoryTodoRepository) CountTodoByUserID(ctx context.Context, userID string, filter TodoFilter) (int64, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	todos, err := repository.filter(userID, filter)
+	return int64(len(todos)), err
+}
+
+func (repository *MemoryTodoRepository) FindDueTodosByUserID(ctx context.Context, userID string, window DueWindow) ([]Todo, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	pending := false
+	todos, err := repository.filter(userID, TodoFilter{
+		Completed: &pending,
+		Due:       &window,
+		Sort:      ParseTodoSort(defaultTodoSort),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(todos, func(i, j int) bool {
+		if result := todos[i].DueAt.Compare(*todos[j].DueAt); result != 0 {
+			return result < 0
+		}
+		return todos[i].ID.String() < todos[j].ID.String()
+	})
+	return todos, nil
+}
+
+// SearchTodosByUserID matches substrings and ranks the todos like the LIKE fallback of the database repository
+func (repository *MemoryTodoRepository) SearchTodosByUserID(ctx context.Context, userID string, query SearchQuery, filter TodoFilter, offset int) ([]SearchHit, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	todos, err := repository.filter(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]SearchHit, 0)
+	for _, todo := range todos {
+		if rank := query.Rank(todo.Title, todo.Description); rank > 0 {
+			hits = append(hits, SearchHit{
+				Todo:    todo,
+				Rank:    rank,
+				Title:   query.Highlight(todo.Title),
+				Snippet: query.Snippet(todo.Description),
+			})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		if result := hits[i].Todo.UpdatedAt.Compare(hits[j].Todo.UpdatedAt); result != 0 {
+			return result > 0
+		}
+		return hits[i].Todo.ID.String() < hits[j].Todo.ID.String()
+	})
+
+	hits = hits[min(offset, len(hits)):]
+	if len(hits) > filter.Limit+1 {
+		hits = hits[:filter.Limit+1]
+	}
+	return hits, nil
+}
+
+func (repository *MemoryTodoRepository) CreateTodo(ctx context.Context, todo *Todo) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	_ = todo.BeforeCreate(nil)
+	if _, exists := repository.todos[todo.ID]; exists {
+		return gorm.ErrDuplicatedKey
+	}
+	if err := repository.assignPosition(todo); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = now
+	}
+	if todo.UpdatedAt.IsZero() {
+		todo.UpdatedAt = now
+	}
+
+	repository.todos[todo.ID] = *todo
+	repository.todoTags[todo.ID] = tagIDs(todo.Tags)
+	return nil
+}
+
+// assignPosition puts a todo without a position before the user's first todo, the caller must hold the lock
+func (repository *MemoryTodoRepository) assignPosition(todo *Todo) error {
+	if todo.Position != "" {
+		return nil
+	}
+
+	for rebalanced := false; ; rebalanced = true {
+		first := ""
+		if todos := repository.positioned(todo.UserID); len(todos) > 0 {
+			first = todos[0].Position
+		}
+		position, err := PositionBetween("", first)
+		if err == nil && !needsRebalance(position) {
+			todo.Position = position
+			return nil
+		}
+		if rebalanced {
+			return ErrNoPositionBetween
+		}
+		repository.rebalancePositions(todo.UserID)
+	}
+}
+
+// positioned returns the user's todos in manual order, the caller must hold the lock
+func (repository *MemoryTodoRepository) positioned(userID uuid.UUID) []Todo {
+	todos := make([]Todo, 0)
+	for _, todo := range repository.todos {
+		if todo.UserID == userID && !todo.DeletedAt.Valid {
+			todos = append(todos, todo)
+		}
+	}
+	sortTodos(todos, TodoSort{Field: "position"})
+	return todos
+}
+
+func (repository *MemoryTodoRepository) FindAdjacentTodoPosition(ctx context.Context, todo *Todo, previous bool, exclude uuid.UUID) (string, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	todos := repository.positioned(todo.UserID)
+	if previous {
+		slices.Reverse(todos)
+	}
+	for _, candidate := range todos {
+		if candidate.ID == exclude {
+			continue
+		}
+		result := compareTodos(candidate, *todo, "position")
+		if (previous && result < 0) || (!previous && result > 0) {
+			return candidate.Position, nil
+		}
+	}
+	return "", nil
+}
+
+func (repository *MemoryTodoRepository) RebalanceTodoPositions(ctx context.Context, userID uuid.UUID) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	repository.rebalancePositions(userID)
+	return nil
+}
+
+// rebalancePositions rewrites the positions of the user's todos without changing their order, the caller must hold the lock
+// The versions are left alone, as in the database
+func (repository *MemoryTodoRepository) rebalancePositions(userID uuid.UUID) {
+	todos := repository.positioned(userID)
+	for i, position := range balancedPositions(len(todos)) {
+		todo := todos[i]
+		todo.Position = position
+		repository.todos[todo.ID] = todo
+	}
+}
+
+func (repository *MemoryTodoRepository) FindTodoByIDAndUserID(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) (*Todo, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	todo, exists := repository.todos[todoID]
+	if !exists || todo.UserID != userID || todo.DeletedAt.Valid {
+		return &Todo{}, gorm.ErrRecordNotFound
+	}
+	todo = repository.withTags(todo)
+	return &todo, nil
+}
+
+func (repository *MemoryTodoRepository) UpdateTodo(ctx context.Context, todo *Todo, columns ...string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	return repository.saveColumns(todo, columns)
+}
+
+// save stores the todo like gorm's Save, inserting a todo that doesn't exist yet
+// The caller must hold the write lock
+func (repository *MemoryTodoRepository) save(todo *Todo) {
+	_ = todo.BeforeCreate(nil)
+	if existing, exists := repository.todos[todo.ID]; exists {
+		todo.CreatedAt = existing.CreatedAt
+	} else if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = time.Now()
+	}
+	todo.UpdatedAt = time.Now()
+
+	repository.todos[todo.ID] = *todo
+	repository.todoTags[todo.ID] = tagIDs(todo.Tags)
+}
+
+// saveColumns copies the given columns of the todo onto the stored one like gorm's Updates with Select
+// Without columns it saves the whole todo, either way only while the stored todo has the todo's version
+// The caller must hold the write lock
+func (repository *MemoryTodoRepository) saveColumns(todo *Todo, columns []string) error {
+	stored, exists := repository.todos[todo.ID]
+	if !exists || stored.DeletedAt.Valid || stored.Version != todo.Version {
+		return ErrVersionConflict
+	}
+	todo.Version++
+	if len(columns) == 0 {
+		repository.save(todo)
+		return nil
+	}
+
+	naming := schema.NamingStrategy{}
+	source := reflect.ValueOf(*todo)
+	target := reflect.ValueOf(&stored).Elem()
+	for i := range source.NumField() {
+		field := source.Type().Field(i)
+		if !field.Anonymous && slices.Contains(columns, naming.ColumnName("", field.Name)) {
+			target.Field(i).Set(source.Field(i))
+		}
+	}
+	stored.Version = todo.Version
+	stored.UpdatedAt = time.Now()
+	todo.UpdatedAt = stored.UpdatedAt
+
+	repository.todos[todo.ID] = stored
+	if slices.Contains(columns, tagsColumn) {
+		repository.todoTags[todo.ID] = tagIDs(todo.Tags)
+	}
+	return nil
+}
+
+func (repository *MemoryTodoRepository) FindSubtasksByParentIDs(ctx context.Context, userID uuid.UUID, parentIDs []uuid.UUID) ([]Todo, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	todos := make([]Todo, 0)
+	for _, todo := range repository.todos {
+		if todo.UserID == userID && !todo.DeletedAt.Valid && todo.ParentID != nil && slices.Contains(parentIDs, *todo.ParentID) {
+			todos = append(todos, repository.withTags(todo))
+		}
+	}
+	sortTodos(todos, TodoSort{Field: "created_at"})
+	return todos, nil
+}
+
+func (repository *MemoryTodoRepository) DeleteTodo(ctx context.Context, todo *Todo, subtasks string) ([]Todo, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	existing, exists := repository.todos[todo.ID]
+	if !exists || existing.DeletedAt.Valid || existing.Version != todo.Version {
+		return nil, ErrVersionConflict
+	}
+	existing.Version++
+	todo.Version = existing.Version
+
+	deletedAt := gorm.DeletedAt{Time: time.Now(), Valid: true}
+	var changed []Todo
+	if subtasks == SubtasksReparent {
+		for id, child := range repository.todos {
+			if !child.DeletedAt.Valid && child.ParentID != nil && *child.ParentID == todo.ID {
+				child.ParentID = existing.ParentID
+				child.Version++
+				repository.todos[id] = child
+				changed = append(changed, repository.withTags(child))
+			}
+		}
+	} else {
+		for _, id := range repository.subtaskIDs(todo.ID, false) {
+			deleted := repository.todos[id]
+			deleted.DeletedAt = deletedAt
+			repository.todos[id] = deleted
+			changed = append(changed, repository.withTags(deleted))
+		}
+	}
+
+	existing.DeletedAt = deletedAt
+	repository.todos[todo.ID] = existing
+	todo.DeletedAt = deletedAt
+	sortTodos(changed, TodoSort{Field: "created_at"})
+	return changed, nil
+}
+
+// subtaskIDs walks down the subtasks of the todo, following the trashed ones with trashed set and the others without
+// The caller must hold the lock
+func (repository *MemoryTodoRepository) subtaskIDs(todoID uuid.UUID, trashed bool) []uuid.UUID {
+	var ids []uuid.UUID
+	for parentIDs := []uuid.UUID{todoID}; len(parentIDs) > 0; {
+		var children []uuid.UUID
+		for id, todo := range repository.todos {
+			if todo.DeletedAt.Valid == trashed && todo.ParentID != nil && slices.Contains(parentIDs, *todo.ParentID) {
+				children = append(children, id)
+			}
+		}
+		ids = append(ids, children...)
+		parentIDs = children
+	}
+	return ids
+}
+
+func (repository *MemoryTodoRepository) FindTrashedTodoByIDAndUserID(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) (*Todo, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	todo, exists := repository.todos[todoID]
+	if !exists || todo.UserID != userID || !todo.DeletedAt.Valid {
+		return &Todo{}, gorm.ErrRecordNotFound
+	}
+	todo = repository.withTags(todo)
+	return &todo, nil
+}
+
+func (repository *MemoryTodoRepository) RestoreTodo(ctx context.Context, todo *Todo) ([]Todo, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	existing, exists := repository.todos[todo.ID]
+	if !exists {
+		return nil, nil
+	}
+
+	// Subtasks deleted with the todo share its deletion time, the ones deleted before it stay in the trash
+	var restored []Todo
+	for _, id := range repository.subtaskIDs(todo.ID, true) {
+		subtask := repository.todos[id]
+		if !subtask.DeletedAt.Time.Equal(existing.DeletedAt.Time) {
+			continue
+		}
+		subtask.DeletedAt = gorm.DeletedAt{}
+		repository.todos[id] = subtask
+		restored = append(restored, repository.withTags(subtask))
+	}
+
+	existing.DeletedAt = gorm.DeletedAt{}
+	existing.ParentID = todo.ParentID
+	existing.UpdatedAt = time.Now()
+	existing.Version++
+	repository.todos[todo.ID] = existing
+	todo.DeletedAt = existing.DeletedAt
+	todo.UpdatedAt = existing.UpdatedAt
+	todo.Version = existing.Version
+	sortTodos(restored, TodoSort{Field: "created_at"})
+	return restored, nil
+}
+
+func (repository *MemoryTodoRepository) PurgeTodo(ctx context.Context, todo *Todo) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	for _, id := range append(repository.subtaskIDs(todo.ID, true), todo.ID) {
+		repository.purge(id)
+	}
+	return nil
+}
+
+// purge permanently deletes the todo along with its tags and checklist
+// The caller must hold the write lock
+func (repository *MemoryTodoRepository) purge(todoID uuid.UUID) {
+	delete(repository.todos, todoID)
+	delete(repository.todoTags, todoID)
+	for id, item := range repository.checklist {
+		if item.TodoID == todoID {
+			delete(repository.checklist, id)
+		}
+	}
+}
+
+func (repository *MemoryTodoRepository) PurgeTodosDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	var purged int64
+	for id, todo := range repository.todos {
+		if todo.DeletedAt.Valid && todo.DeletedAt.Time.Before(before) {
+			repository.purge(id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (repository *MemoryTodoRepository) TransitionTodo(ctx context.Context, todo *Todo, transition *TodoTransition, columns ...string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if err := repository.saveColumns(todo, columns); err != nil {
+		return err
+	}
+
+	_ = transition.BeforeCreate(nil)
+	transition.CreatedAt = time.Now()
+	transition.UpdatedAt = transition.CreatedAt
+	repository.transitions = append(repository.transitions, *transition)
+	return nil
+}
+
+func (repository *MemoryTodoRepository) CompleteOccurrence(ctx context.Context, todo *Todo, transition *TodoTransition, next *Todo, columns ...string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if err := repository.saveColumns(todo, columns); err != nil {
+		return err
+	}
+
+	_ = transition.BeforeCreate(nil)
+	transition.CreatedAt = time.Now()
+	transition.UpdatedAt = transition.CreatedAt
+	repository.transitions = append(repository.transitions, *transition)
+
+	if err := repository.assignPosition(next); err != nil {
+		return err
+	}
+	repository.save(next)
+	return nil
+}
+
+func (repository *MemoryTodoRepository) FindTodoTransitionsByTodoID(ctx context.Context, todoID uuid.UUID) ([]TodoTransition, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	transitions := make([]TodoTransition, 0)
+	for _, transition := range repository.transitions {
+		if transition.TodoID == todoID {
+			transitions = append(transitions, transition)
+		}
+	}
+	return transitions, nil
+}
+
+func (repository *MemoryTodoRepository) AppendTodoHistory(ctx context.Context, history *TodoHistory) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	_ = history.BeforeCreate(nil)
+	if history.CreatedAt.IsZero() {
+		history.CreatedAt = time.Now()
+	}
+	repository.history = append(repository.history, *history)
+	return nil
+}
+
+func (repository *MemoryTodoRepository) FindTodoHistoryByUserID(ctx context.Context, userID uuid.UUID, filter HistoryFilter) ([]TodoHistory, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	history := make([]TodoHistory, 0)
+	for _, record := range repository.history {
+		if record.UserID != userID || (filter.TodoID != nil && record.TodoID != *filter.TodoID) {
+			continue
+		}
+		if filter.Cursor != nil && !filter.Cursor.isAfter(record) {
+			continue
+		}
+		history = append(history, record)
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return NewHistoryCursor(history[i]).isAfter(history[j])
+	})
+	if len(history) > filter.Limit+1 {
+		history = history[:filter.Limit+1]
+	}
+	return history, nil
+}
+
+func (repository *MemoryTodoRepository) CreateTodoUndo(ctx context.Context, undo *TodoUndo) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	now := time.Now()
+	for id, existing := range repository.undos {
+		if existing.UserID == undo.UserID && existing.ExpiresAt.Before(now) {
+			delete(repository.undos, id)
+		}
+	}
+
+	_ = undo.BeforeCreate(nil)
+	if _, exists := repository.undos[undo.ID]; exists {
+		return gorm.ErrDuplicatedKey
+	}
+	if undo.CreatedAt.IsZero() {
+		undo.CreatedAt = now
+	}
+	repository.undos[undo.ID] = *undo
+	return nil
+}
+
+func (repository *MemoryTodoRepository) FindTodoUndoByIDAndUserID(ctx context.Context, undoID uuid.UUID, userID uuid.UUID) (*TodoUndo, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	undo, ok := repository.undos[undoID]
+	if !ok || undo.UserID != userID {
+		return &TodoUndo{}, gorm.ErrRecordNotFound
+	}
+	return &undo, nil
+}
+
+func (repository *MemoryTodoRepository) DeleteTodoUndo(ctx context.Context, undo *TodoUndo) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	delete(repository.undos, undo.ID)
+	return nil
+}
+
+func (repository *MemoryTodoRepository) FindWorkflowByUserID(ctx context.Context, userID uuid.UUID) (Workflow, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	workflow := repository.workflows[userID]
+	return Workflow{
+		Statuses:    slices.Clone(workflow.Statuses),
+		Transitions: slices.Clone(workflow.Transitions),
+	}, nil
+}
+
+func (repository *MemoryTodoRepository) SaveWorkflow(ctx context.Context, userID uuid.UUID, workflow *Workflow) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	now := time.Now()
+	for i := range workflow.Statuses {
+		status := &workflow.Statuses[i]
+		_ = status.BeforeCreate(nil)
+		status.UserID = userID
+		status.Position = i
+		status.CreatedAt, status.UpdatedAt = now, now
+	}
+	for i := range workflow.Transitions {
+		transition := &workflow.Transitions[i]
+		_ = transition.BeforeCreate(nil)
+		transition.UserID = userID
+		transition.CreatedAt, transition.UpdatedAt = now, now
+	}
+
+	repository.workflows[userID] = Workflow{
+		Statuses:    slices.Clone(workflow.Statuses),
+		Transitions: slices.Clone(workflow.Transitions),
+	}
+
+	doneKeys := workflow.doneKeys()
+	for id, todo := range repository.todos {
+		if completed := slices.Contains(doneKeys, todo.Status); todo.UserID == userID && todo.Completed != completed {
+			todo.Completed = completed
+			todo.Version++
+			repository.todos[id] = todo
+		}
+	}
+	return nil
+}
+
+func (repository *MemoryTodoRepository) FindUsedStatusesByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	statuses := make([]string, 0)
+	for _, todo := range repository.todos {
+		if todo.UserID == userID && !slices.Contains(statuses, todo.Status) {
+			statuses = append(statuses, todo.Status)
+		}
+	}
+	return statuses, nil
+}
+
+// filter returns copies of the user's todos that match the filter, ignoring the cursor
+func (repository *MemoryTodoRepository) filter(userID string, filter TodoFilter) ([]Todo, error) {
+	if filter.Sort.Column() == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	title := strings.ToLower(filter.Title)
+
+	todos := make([]Todo, 0)
+	for _, todo := range repository.todos {
+		if todo.UserID.String() != userID {
+			continue
+		}
+		if todo.DeletedAt.Valid != filter.Trashed {
+			continue
+		}
+		if filter.Completed != nil && todo.Completed != *filter.Completed {
+			continue
+		}
+		if title != "" && !strings.Contains(strings.ToLower(todo.Title), title) {
+			continue
+		}
+		if !inRange(todo.CreatedAt, filter.CreatedFrom, filter.CreatedTo) {
+			continue
+		}
+		if !inRange(todo.UpdatedAt, filter.UpdatedFrom, filter.UpdatedTo) {
+			continue
+		}
+		if len(filter.Priorities) > 0 && !slices.Contains(filter.Priorities, todo.Priority) {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, todo.Status) {
+			continue
+		}
+		if filter.Project != nil && !inProject(todo, *filter.Project) {
+			continue
+		}
+		if len(filter.Tags) > 0 && !repository.hasTags(todo.ID, filter.Tags, filter.AllTags) {
+			continue
+		}
+		if filter.Overdue && todo.Completed {
+			continue
+		}
+		if filter.Due != nil && !filter.Due.Contains(todo.DueAt, todo.AllDay) {
+			continue
+		}
+		todos = append(todos, repository.withTags(todo))
+	}
+
+	return todos, nil
+}
+
+// withTags returns the todo with its tags filled in
+// The caller must hold the lock
+func (repository *MemoryTodoRepository) withTags(todo Todo) Todo {
+	todo.Tags = make([]Tag, 0, len(repository.todoTags[todo.ID]))
+	for _, id := range repository.todoTags[todo.ID] {
+		if tag, exists := repository.tags[id]; exists {
+			todo.Tags = append(todo.Tags, tag)
+		}
+	}
+	sortTags(todo.Tags)
+	return todo
+}
+
+// hasTags reports whether the todo has any, or with all set every one, of the lowercased tag names
+// The caller must hold the lock
+func (repository *MemoryTodoRepository) hasTags(todoID uuid.UUID, names []string, all bool) bool {
+	matched := 0
+	for _, id := range repository.todoTags[todoID] {
+		if tag, exists := repository.tags[id]; exists && slices.Contains(names, strings.ToLower(tag.Name)) {
+			matched++
+		}
+	}
+	if all {
+		return matched == len(names)
+	}
+	return matched > 0
+}
+
+func (repository *MemoryTodoRepository) FindTagsByUserID(ctx context.Context, userID uuid.UUID) ([]Tag, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	return repository.findTags(func(tag Tag) bool {
+		return tag.UserID == userID
+	}), nil
+}
+
+func (repository *MemoryTodoRepository) FindTagByIDAndUserID(ctx context.Context, tagID uuid.UUID, userID uuid.UUID) (*Tag, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	tag, exists := repository.tags[tagID]
+	if !exists || tag.UserID != userID {
+		return &Tag{}, gorm.ErrRecordNotFound
+	}
+	return &tag, nil
+}
+
+func (repository *MemoryTodoRepository) FindTagsByIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]Tag, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	return repository.findTags(func(tag Tag) bool {
+		return tag.UserID == userID && slices.Contains(tagIDs, tag.ID)
+	}), nil
+}
+
+func (repository *MemoryTodoRepository) FindTagsByNames(ctx context.Context, userID uuid.UUID, names []string) ([]Tag, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	return repository.findTags(func(tag Tag) bool {
+		return tag.UserID == userID && slices.ContainsFunc(names, func(name string) bool {
+			return strings.EqualFold(name, tag.Name)
+		})
+	}), nil
+}
+
+// findTags returns the matching tags ordered by name
+// The caller must hold the lock
+func (repository *MemoryTodoRepository) findTags(match func(tag Tag) bool) []Tag {
+	tags := make([]Tag, 0)
+	for _, tag := range repository.tags {
+		if match(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	sortTags(tags)
+	return tags
+}
+
+func (repository *MemoryTodoRepository) CreateTag(ctx context.Context, tag *Tag) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	_ = tag.BeforeCreate(nil)
+	if repository.tagNameTaken(*tag) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	now := time.Now()
+	tag.CreatedAt, tag.UpdatedAt = now, now
+	repository.tags[tag.ID] = *tag
+	return nil
+}
+
+func (repository *MemoryTodoRepository) UpdateTag(ctx context.Context, tag *Tag) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if repository.tagNameTaken(*tag) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	tag.UpdatedAt = time.Now()
+	repository.tags[tag.ID] = *tag
+	return nil
+}
+
+// tagNameTaken reports whether another tag of the user has the same name, ignoring case
+// The caller must hold the lock
+func (repository *MemoryTodoRepository) tagNameTaken(tag Tag) bool {
+	for _, existing := range repository.tags {
+		if existing.ID != tag.ID && existing.UserID == tag.UserID && strings.EqualFold(existing.Name, tag.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (repository *MemoryTodoRepository) DeleteTag(ctx context.Context, tag *Tag) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	delete(repository.tags, tag.ID)
+	for todoID, ids := range repository.todoTags {
+		repository.todoTags[todoID] = slices.DeleteFunc(ids, func(id uuid.UUID) bool {
+			return id == tag.ID
+		})
+	}
+	return nil
+}
+
+// inProject reports whether the todo is in the project, where uuid.Nil is the inbox
+func inProject(todo Todo, projectID uuid.UUID) bool {
+	if todo.ProjectID == nil {
+		return projectID == uuid.Nil
+	}
+	return *todo.ProjectID == projectID
+}
+
+func inRange(value time.Time, from *time.Time, to *time.Time) bool {
+	if from != nil && value.Before(*from) {
+		return false
+	}
+	if to != nil && value.After(*to) {
+		return false
+	}
+	return true
+}
+
+// compareTodos orders two todos by the sort field, breaking ties on the id
+func compareTodos(a Todo, b Todo, field string) int {
+	var result int
+	switch field {
+	case "created_at":
+		result = a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		result = a.UpdatedAt.Compare(b.UpdatedAt)
+	case "priority":
+		result = a.Priority - b.Priority
+	case "status":
+		result = strings.Compare(a.Status, b.Status)
+	case "position":
+		result = strings.Compare(a.Position, b.Position)
+	default:
+		result = strings.Compare(a.Title, b.Title)
+	}
+	if result != 0 {
+		return result
+	}
+	return strings.Compare(a.ID.String(), b.ID.String())
+}
+
+func sortTodos(todos []Todo, todoSort TodoSort) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		result := compareTodos(todos[i], todos[j], todoSort.Field)
+		if todoSort.Descending {
+			return result > 0
+		}
+		return result < 0
+	})
+}
+
+// isAfterCursor reports whether a todo comes strictly after the cursor position
+func isAfterCursor(todo Todo, todoSort TodoSort, value any, id uuid.UUID) bool {
+	pivot := todo
+	pivot.ID = id
+	switch todoSort.Field {
+	case "created_at":
+		pivot.CreatedAt = value.(time.Time)
+	case "updated_at":
+		pivot.UpdatedAt = value.(time.Time)
+	case "priority":
+		pivot.Priority = value.(int)
+	case "status":
+		pivot.Status = value.(string)
+	case "position":
+		pivot.Position = value.(string)
+	default:
+		pivot.Title = value.(string)
+	}
+
+	result := compareTodos(todo, pivot, todoSort.Field)
+	if todoSort.Descending {
+		return result < 0
+	}
+	return result > 0
+}
+
+func (repository *MemoryTodoRepository) FindProjectsByUserID(ctx context.Context, userID uuid.UUID, archived bool) ([]Project, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	projects := make([]Project, 0)
+	for _, project := range repository.projects {
+		if project.UserID == userID && (archived || !project.Archived) {
+			projects = append(projects, project)
+		}
+	}
+	slices.SortFunc(projects, func(a Project, b Project) int {
+		if a.Position != b.Position {
+			return a.Position - b.Position
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return projects, nil
+}
+
+func (repository *MemoryTodoRepository) FindProjectByIDAndUserID(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) (*Project, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	project, exists := repository.projects[projectID]
+	if !exists || project.UserID != userID {
+		return &Project{}, gorm.ErrRecordNotFound
+	}
+	return &project, nil
+}
+
+func (repository *MemoryTodoRepository) CreateProject(ctx context.Context, project *Project) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	_ = project.BeforeCreate(nil)
+	project.Position = 0
+	for _, existing := range repository.projects {
+		if existing.UserID == project.UserID && existing.Position >= project.Position {
+			project.Position = existing.Position + 1
+		}
+	}
+
+	now := time.Now()
+	project.CreatedAt, project.UpdatedAt = now, now
+	repository.projects[project.ID] = *project
+	return nil
+}
+
+func (repository *MemoryTodoRepository) UpdateProject(ctx context.Context, project *Project) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	project.UpdatedAt = time.Now()
+	repository.projects[project.ID] = *project
+	return nil
+}
+
+func (repository *MemoryTodoRepository) UpdateProjectPositions(ctx context.Context, projects []Project) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	for _, project := range projects {
+		if existing, exists := repository.projects[project.ID]; exists {
+			existing.Position = project.Position
+			repository.projects[project.ID] = existing
+		}
+	}
+	return nil
+}
+
+func (repository *MemoryTodoRepository) CountTodosByProject(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]TodoCounts, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]TodoCounts)
+	for _, todo := range repository.todos {
+		if todo.UserID != userID || todo.DeletedAt.Valid {
+			continue
+		}
+		key := uuid.Nil
+		if todo.ProjectID != nil {
+			key = *todo.ProjectID
+		}
+		count := counts[key]
+		if todo.Completed {
+			count.Completed++
+		} else {
+			count.Open++
+		}
+		counts[key] = count
+	}
+	return counts, nil
+}
+
+func (repository *MemoryTodoRepository) FindChecklistItemsByTodoIDs(ctx context.Context, todoIDs []uuid.UUID) ([]ChecklistItem, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	items := make([]ChecklistItem, 0)
+	for _, item := range repository.checklist {
+		if slices.Contains(todoIDs, item.TodoID) {
+			items = append(items, item)
+		}
+	}
+	slices.SortFunc(items, func(a ChecklistItem, b ChecklistItem) int {
+		if a.Position != b.Position {
+			return a.Position - b.Position
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return items, nil
+}
+
+func (repository *MemoryTodoRepository) FindChecklistItemByIDAndTodoID(ctx context.Context, itemID uuid.UUID, todoID uuid.UUID) (*ChecklistItem, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	item, exists := repository.checklist[itemID]
+	if !exists || item.TodoID != todoID {
+		return &ChecklistItem{}, gorm.ErrRecordNotFound
+	}
+	return &item, nil
+}
+
+func (repository *MemoryTodoRepository) CreateChecklistItem(ctx context.Context, item *ChecklistItem) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	_ = item.BeforeCreate(nil)
+	item.Position = 0
+	for _, existing := range repository.checklist {
+		if existing.TodoID == item.TodoID && existing.Position >= item.Position {
+			item.Position = existing.Position + 1
+		}
+	}
+
+	now := time.Now()
+	item.CreatedAt, item.UpdatedAt = now, now
+	repository.checklist[item.ID] = *item
+	return nil
+}
+
+func (repository *MemoryTodoRepository) UpdateChecklistItem(ctx context.Context, item *ChecklistItem) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	item.UpdatedAt = time.Now()
+	repository.checklist[item.ID] = *item
+	return nil
+}
+
+func (repository *MemoryTodoRepository) DeleteChecklistItem(ctx context.Context, item *ChecklistItem) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	delete(repository.checklist, item.ID)
+	return nil
+}
+
+func (repository *MemoryTodoRepository) UpdateChecklistItemPositions(ctx context.Context, items []ChecklistItem) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	for _, item := range items {
+		if existing, exists := repository.checklist[item.ID]; exists {
+			existing.Position = item.Position
+			repository.checklist[item.ID] = existing
+		}
+	}
+	return nil
+}
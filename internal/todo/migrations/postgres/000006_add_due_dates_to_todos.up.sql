@@ -0,0 +1,7 @@
+-- due_at holds an instant, or for all-day todos the due date at midnight UTC
+ALTER TABLE todos ADD COLUMN due_at TIMESTAMP NULL;
+ALTER TABLE todos ADD COLUMN all_day BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE todos ADD COLUMN remind_at TIMESTAMP NULL;
+
+CREATE INDEX idx_todos_user_id_due_at ON todos(user_id, due_at);
+CREATE INDEX idx_todos_remind_at ON todos(remind_at);
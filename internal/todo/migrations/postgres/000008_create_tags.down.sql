@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_todo_tags_tag_id;
+DROP TABLE IF EXISTS todo_tags;
+
+DROP INDEX IF EXISTS idx_tags_user_id_name;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_todos_user_id_project_id;
+ALTER TABLE todos DROP COLUMN project_id;
+
+DROP INDEX IF EXISTS idx_projects_user_id_position;
+DROP TABLE IF EXISTS projects;
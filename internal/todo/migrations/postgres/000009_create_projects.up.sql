@@ -0,0 +1,20 @@
+-- Projects group the todos of a user, todos without a project are in the user's inbox
+CREATE TABLE projects (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    color VARCHAR(7) NOT NULL,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL,
+    CONSTRAINT fk_projects_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_projects_user_id_position ON projects(user_id, position);
+
+ALTER TABLE todos ADD COLUMN project_id UUID NULL REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_todos_user_id_project_id ON todos(user_id, project_id);
@@ -0,0 +1,20 @@
+-- Subtasks point at their parent todo, which always belongs to the same user
+ALTER TABLE todos ADD COLUMN parent_id UUID NULL REFERENCES todos(id) ON DELETE CASCADE;
+ALTER TABLE todos ADD COLUMN auto_complete BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_todos_parent_id ON todos(parent_id);
+
+-- Checklist items are lightweight steps of a single todo
+CREATE TABLE checklist_items (
+    id UUID PRIMARY KEY,
+    todo_id UUID NOT NULL,
+    text VARCHAR(255) NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL,
+    CONSTRAINT fk_checklist_items_todo FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_checklist_items_todo_id_position ON checklist_items(todo_id, position);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_todos_series_id;
+
+ALTER TABLE todos DROP COLUMN skipped_dates;
+ALTER TABLE todos DROP COLUMN series_start;
+ALTER TABLE todos DROP COLUMN series_id;
+ALTER TABLE todos DROP COLUMN timezone;
+ALTER TABLE todos DROP COLUMN recurrence;
@@ -0,0 +1,8 @@
+-- Full text search over the title and description, matches in the title rank above those in the description
+-- SQLite has no counterpart, searching there falls back to LIKE
+ALTER TABLE todos ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_todos_search ON todos USING GIN (search);
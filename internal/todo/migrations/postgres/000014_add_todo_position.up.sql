@@ -0,0 +1,12 @@
+-- Manual order of a user's todos, positions are fractional keys compared byte by byte so a todo fits between any two others
+ALTER TABLE todos ADD COLUMN position VARCHAR(255) COLLATE "C" NOT NULL DEFAULT '';
+
+-- Existing todos keep the order they were listed in, newest first
+UPDATE todos SET position = ranked.position
+FROM (
+    SELECT id, 'i' || lpad(CAST(ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at DESC, id DESC) AS TEXT), 9, '0') || 'i' AS position
+    FROM todos
+) AS ranked
+WHERE todos.id = ranked.id;
+
+CREATE INDEX idx_todos_user_id_position ON todos(user_id, position);
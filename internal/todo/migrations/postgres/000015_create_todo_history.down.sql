@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_todo_history_user_id_created_at;
+DROP INDEX IF EXISTS idx_todo_history_todo_id_created_at;
+DROP TABLE IF EXISTS todo_history;
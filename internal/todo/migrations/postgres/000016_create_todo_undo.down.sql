@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_todo_undo_user_id_expires_at;
+DROP TABLE IF EXISTS todo_undo;
@@ -0,0 +1,12 @@
+-- Before-images of recent changes, the id is the undo token handed out with the change
+CREATE TABLE todo_undo (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    -- JSON array of the todos the change touched, with their state before and after it
+    entries TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_todo_undo_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_todo_undo_user_id_expires_at ON todo_undo(user_id, expires_at);
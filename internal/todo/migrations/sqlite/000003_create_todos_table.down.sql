@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_todos_completed;
+DROP INDEX IF EXISTS idx_todos_user_id;
+DROP INDEX IF EXISTS idx_todos_deleted_at;
+DROP TABLE IF EXISTS todos;
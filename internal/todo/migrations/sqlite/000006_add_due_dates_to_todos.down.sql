@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_todos_remind_at;
+DROP INDEX IF EXISTS idx_todos_user_id_due_at;
+
+ALTER TABLE todos DROP COLUMN remind_at;
+ALTER TABLE todos DROP COLUMN all_day;
+ALTER TABLE todos DROP COLUMN due_at;
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_todo_transitions_todo_id;
+DROP TABLE IF EXISTS todo_transitions;
+
+DROP INDEX IF EXISTS idx_workflow_transitions_user_id;
+DROP TABLE IF EXISTS workflow_transitions;
+
+DROP INDEX IF EXISTS idx_workflow_statuses_user_id_key;
+DROP TABLE IF EXISTS workflow_statuses;
+
+DROP INDEX IF EXISTS idx_todos_user_id_priority;
+DROP INDEX IF EXISTS idx_todos_user_id_status;
+
+ALTER TABLE todos DROP COLUMN status;
+ALTER TABLE todos DROP COLUMN priority;
@@ -0,0 +1,54 @@
+-- Priorities run from 0 (P0, most urgent) to 3 (P3)
+ALTER TABLE todos ADD COLUMN priority SMALLINT NOT NULL DEFAULT 2;
+ALTER TABLE todos ADD COLUMN status VARCHAR(50) NOT NULL DEFAULT 'backlog';
+
+-- completed is kept in sync with the status, existing todos map onto the default workflow
+UPDATE todos SET status = 'done' WHERE completed = TRUE;
+
+CREATE INDEX idx_todos_user_id_status ON todos(user_id, status);
+CREATE INDEX idx_todos_user_id_priority ON todos(user_id, priority);
+
+-- Statuses of a user's workflow, users without any rows use the default workflow
+CREATE TABLE workflow_statuses (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    key VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL,
+    CONSTRAINT fk_workflow_statuses_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_workflow_statuses_user_id_key ON workflow_statuses(user_id, key);
+
+-- Allowed moves between statuses, a workflow without transitions allows every move
+CREATE TABLE workflow_transitions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL,
+    CONSTRAINT fk_workflow_transitions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_workflow_transitions_user_id ON workflow_transitions(user_id);
+
+-- Status changes of todos
+CREATE TABLE todo_transitions (
+    id TEXT PRIMARY KEY,
+    todo_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL,
+    CONSTRAINT fk_todo_transitions_todo FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_todo_transitions_todo_id ON todo_transitions(todo_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_checklist_items_todo_id_position;
+DROP TABLE IF EXISTS checklist_items;
+
+DROP INDEX IF EXISTS idx_todos_parent_id;
+ALTER TABLE todos DROP COLUMN auto_complete;
+ALTER TABLE todos DROP COLUMN parent_id;
@@ -0,0 +1,12 @@
+-- Recurring todos carry an RRULE that moves on to the next instance once they are completed
+ALTER TABLE todos ADD COLUMN recurrence VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE todos ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Instances of a series share series_id, series_start is the due date the rule counts occurrences from
+ALTER TABLE todos ADD COLUMN series_id TEXT NULL;
+ALTER TABLE todos ADD COLUMN series_start TIMESTAMP NULL;
+
+-- JSON array of the dates the series leaves out
+ALTER TABLE todos ADD COLUMN skipped_dates TEXT NULL;
+
+CREATE INDEX idx_todos_series_id ON todos(series_id);
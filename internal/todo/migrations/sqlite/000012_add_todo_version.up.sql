@@ -0,0 +1,2 @@
+-- Every write of a todo increments its version, which is the ETag that If-Match is checked against
+ALTER TABLE todos ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,14 @@
+-- Manual order of a user's todos, positions are fractional keys compared byte by byte so a todo fits between any two others
+ALTER TABLE todos ADD COLUMN position VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Existing todos keep the order they were listed in, newest first
+UPDATE todos SET position = (
+    SELECT 'i' || printf('%09d', ranked.row_number) || 'i'
+    FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at DESC, id DESC) AS row_number
+        FROM todos
+    ) AS ranked
+    WHERE ranked.id = todos.id
+);
+
+CREATE INDEX idx_todos_user_id_position ON todos(user_id, position);
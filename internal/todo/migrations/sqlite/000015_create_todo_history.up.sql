@@ -0,0 +1,16 @@
+-- Immutable record of every change made to a todo, kept after the todo is purged so there is no foreign key to todos
+CREATE TABLE todo_history (
+    id TEXT PRIMARY KEY,
+    todo_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    actor_id TEXT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    -- JSON array of the fields that changed with their old and new values
+    changes TEXT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_todo_history_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_todo_history_todo_id_created_at ON todo_history(todo_id, created_at);
+CREATE INDEX idx_todo_history_user_id_created_at ON todo_history(user_id, created_at);
@@ -1,8 +1,14 @@
 package todo
 
 import (
+	"errors"
+	"slices"
+	"time"
+
 	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/utils"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Todo struct {
@@ -11,4 +17,98 @@ type Todo struct {
 	Description string    `json:"description"`
 	Completed   bool      `json:"completed"`
 	UserID      uuid.UUID `json:"user_id"`
+
+	// ProjectID is nil for todos in the user's inbox
+	ProjectID *uuid.UUID `json:"project_id"`
+
+	// Position is the todo's key in the user's manual order, see PositionBetween
+	Position string `json:"position"`
+
+	// ParentID is set on subtasks, which belong to the same user as their parent
+	ParentID *uuid.UUID `json:"parent_id"`
+
+	// AutoComplete completes the todo once all of its subtasks are done
+	AutoComplete bool `json:"auto_complete"`
+
+	// DueAt is an instant, or for an all-day todo the due date at midnight UTC
+	DueAt    *time.Time `json:"due_at"`
+	AllDay   bool       `json:"all_day"`
+	RemindAt *time.Time `json:"remind_at"`
+
+	// Completed mirrors whether Status is a done status of the user's workflow
+	Priority int    `json:"priority"`
+	Status   string `json:"status"`
+
+	// Recurrence is the RRULE of a recurring todo, which moves on to the next instance once the todo is completed
+	// Timezone is the IANA zone whose wall clock time occurrences keep, empty for UTC
+	Recurrence string `json:"recurrence"`
+	Timezone   string `json:"timezone"`
+
+	// Every instance of a series shares its SeriesID, the rule counts occurrences from SeriesStart
+	// SkippedDates are the dates in Timezone that the series leaves out
+	SeriesID     *uuid.UUID `json:"series_id"`
+	SeriesStart  *time.Time `json:"series_start"`
+	SkippedDates []string   `json:"skipped_dates" gorm:"serializer:json"`
+
+	// Version is incremented by every write, an update only goes through while the row still has the version it read
+	Version int64 `json:"version"`
+
+	// Tags are loaded and saved by the repository rather than through gorm associations
+	Tags []Tag `json:"tags" gorm:"-"`
+}
+
+// ErrVersionConflict means the todo was changed or deleted since it was read
+var ErrVersionConflict = errors.New("todo has been changed")
+
+// BeforeCreate starts new todos at version 1
+func (todo *Todo) BeforeCreate(tx *gorm.DB) error {
+	if todo.Version == 0 {
+		todo.Version = 1
+	}
+	return todo.Base.BeforeCreate(tx)
+}
+
+// ETag is the strong entity tag of the todo's current version
+func (todo Todo) ETag() string {
+	return utils.VersionETag(todo.Version)
+}
+
+// tagsColumn stands for the tag assignments of a todo among the columns of a partial update
+const tagsColumn = "tags"
+
+// changedColumns lists the columns in which the todo differs from before
+func changedColumns(before Todo, todo Todo) []string {
+	var columns []string
+	changed := func(column string, differs bool) {
+		if differs {
+			columns = append(columns, column)
+		}
+	}
+	changed("title", before.Title != todo.Title)
+	changed("description", before.Description != todo.Description)
+	changed("completed", before.Completed != todo.Completed)
+	changed("project_id", !equalPtr(before.ProjectID, todo.ProjectID))
+	changed("position", before.Position != todo.Position)
+	changed("parent_id", !equalPtr(before.ParentID, todo.ParentID))
+	changed("auto_complete", before.AutoComplete != todo.AutoComplete)
+	changed("due_at", !equalTimePtr(before.DueAt, todo.DueAt))
+	changed("all_day", before.AllDay != todo.AllDay)
+	changed("remind_at", !equalTimePtr(before.RemindAt, todo.RemindAt))
+	changed("priority", before.Priority != todo.Priority)
+	changed("status", before.Status != todo.Status)
+	changed("recurrence", before.Recurrence != todo.Recurrence)
+	changed("timezone", before.Timezone != todo.Timezone)
+	changed("series_id", !equalPtr(before.SeriesID, todo.SeriesID))
+	changed("series_start", !equalTimePtr(before.SeriesStart, todo.SeriesStart))
+	changed("skipped_dates", !slices.Equal(before.SkippedDates, todo.SkippedDates))
+	changed(tagsColumn, !slices.Equal(tagIDs(before.Tags), tagIDs(todo.Tags)))
+	return columns
+}
+
+func equalPtr[T comparable](a *T, b *T) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+func equalTimePtr(a *time.Time, b *time.Time) bool {
+	return a == nil && b == nil || a != nil && b != nil && a.Equal(*b)
 }
@@ -0,0 +1,43 @@
+package todo
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/Alfian57/golang-todo/pkg/module"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+func init() {
+	module.Register(Module{})
+}
+
+// Module wires the todo feature into the server
+type Module struct{}
+
+func (Module) Name() string {
+	return "todo"
+}
+
+func (Module) RegisterRoutes(router *gin.RouterGroup, deps *module.Dependencies) {
+	RegisterRoutes(router, deps)
+}
+
+func (Module) Migrations() fs.FS {
+	sub, _ := fs.Sub(migrations, "migrations")
+	return sub
+}
+
+func (Module) Jobs(deps *module.Dependencies) []module.Job {
+	trash := deps.Config.Trash
+	if trash.Retention <= 0 || trash.PurgeInterval <= 0 {
+		return nil
+	}
+
+	return []module.Job{
+		NewPurgeTrashJob(NewTodoRepository(deps.DB), trash.Retention, trash.PurgeInterval, deps.Log),
+	}
+}
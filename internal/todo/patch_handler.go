@@ -0,0 +1,97 @@
+package todo
+
+import (
+	"errors"
+	"os"
+
+	"github.com/Alfian57/golang-todo/pkg/jsonpatch"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      Patch todo
+// @Description  Partially update a todo of the authenticated user with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902), chosen by the Content-Type. The patch is applied to the fields of PatchTodoRequest and only the changed fields are validated and saved. The response carries an undo token that reverts the patch with POST /undo/{token}
+// @Tags         Todo
+// @Accept       application/merge-patch+json
+// @Accept       application/json-patch+json
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string            true  "Todo ID"
+// @Param        body  body      PatchTodoRequest  true  "Merge patch of the todo, or a JSON Patch array of operations on it"
+// @Param        If-Match  header  string  false  "ETag of the todo, the request fails with 412 once the todo has changed"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.UpdateTodoResponse}
+// @Header       200  {string}  ETag  "ETag of the todo"
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      409  {object}  models.Response
+// @Failure      412  {object}  models.Response
+// @Failure      415  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id} [patch]
+func (handler TodoHandler) Patch(ctx *gin.Context) {
+	isDebug := os.Getenv("GIN_MODE") == "debug"
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		response := utils.UnprocessableEntityResponse("Invalid request body", err, isDebug)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+	patch, err := jsonpatch.Parse(ctx.ContentType(), body)
+	if errors.Is(err, jsonpatch.ErrUnsupportedType) {
+		response := utils.UnsupportedMediaTypeResponse("Unsupported patch media type", err, isDebug)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+	if err != nil {
+		response := utils.UnprocessableEntityResponse("Invalid patch", err, isDebug)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Patch todo"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Patch todo"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.Patch(ctx, todoID, patch, ctx.GetHeader("If-Match"), userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Patch todo request failed",
+			logger.F("operation", "Patch todo"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	if data, ok := response.Data.(UpdateTodoResponse); ok {
+		ctx.Header("ETag", utils.VersionETag(data.Todo.Version))
+	}
+	ctx.JSON(response.StatusCode, response)
+}
@@ -0,0 +1,124 @@
+package todo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/jsonpatch"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// Patch applies a merge patch or JSON patch to the PatchTodoRequest document of a todo
+// Only the fields the patch changes are validated and written, so a client can toggle completed without sending the rest
+// A non-empty ifMatch is the If-Match header, the patch only goes through while the todo has one of its ETags
+func (service TodoService) Patch(ctx context.Context, todoID uuid.UUID, patch jsonpatch.Patch, ifMatch string, userID uuid.UUID) models.Response {
+	todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find todo",
+			logger.F("operation", "Patch todo"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found", err, service.isDebug)
+	}
+	if !ifMatches(todo, ifMatch) {
+		return utils.PreconditionFailedResponse("Todo has been changed", ErrVersionConflict, service.isDebug)
+	}
+
+	document, err := json.Marshal(NewPatchTodoRequest(*todo))
+	if err != nil {
+		return utils.InternalServerErrorResponse("Failed to patch todo", err, service.isDebug)
+	}
+	patched, err := patch.Apply(document)
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return utils.ConflictResponse("Patch test failed", err, service.isDebug)
+	}
+	if err != nil {
+		return utils.UnprocessableEntityResponse("Invalid patch", err, service.isDebug)
+	}
+
+	var req PatchTodoRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return utils.UnprocessableEntityResponse("Invalid patch", err, service.isDebug)
+	}
+	fields, err := patchedFields(document, patched)
+	if err != nil {
+		return utils.UnprocessableEntityResponse("Invalid patch", err, service.isDebug)
+	}
+	// all_day depends on due_at, so it is checked again when either changes
+	if slices.Contains(fields, "DueAt") && !slices.Contains(fields, "AllDay") {
+		fields = append(fields, "AllDay")
+	}
+	if len(fields) > 0 {
+		if err := utils.GetValidator().StructPartial(req, fields...); err != nil {
+			return utils.UnprocessableEntityResponse("Validation failed", err, service.isDebug)
+		}
+	}
+
+	return service.undoable(ctx, userID, "Patch todo", func(service TodoService) models.Response {
+		return service.update(ctx, todo, updateRequestOf(req, fields), userID, "Patch todo")
+	})
+}
+
+// updateRequestOf turns a patched document into an update that leaves the fields the patch didn't change alone
+func updateRequestOf(req PatchTodoRequest, fields []string) UpdateTodoRequest {
+	update := UpdateTodoRequest{
+		Title:        req.Title,
+		Description:  req.Description,
+		Completed:    req.Completed,
+		DueAt:        req.DueAt,
+		AllDay:       req.AllDay,
+		RemindAt:     req.RemindAt,
+		Priority:     req.Priority,
+		AutoComplete: req.AutoComplete,
+	}
+	// An unchanged status would otherwise win over a changed completed flag
+	if slices.Contains(fields, "Status") {
+		update.Status = req.Status
+	}
+	if slices.Contains(fields, "TagIDs") {
+		update.TagIDs = req.TagIDs
+		if update.TagIDs == nil {
+			update.TagIDs = []uuid.UUID{}
+		}
+	}
+	if slices.Contains(fields, "Recurrence") {
+		update.Recurrence = &req.Recurrence
+	}
+	if slices.Contains(fields, "Timezone") {
+		update.Timezone = &req.Timezone
+	}
+	return update
+}
+
+// patchedFields returns the names of the PatchTodoRequest fields whose values differ between the documents
+func patchedFields(document []byte, patched []byte) ([]string, error) {
+	var before, after map[string]any
+	if err := json.Unmarshal(document, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	requestType := reflect.TypeFor[PatchTodoRequest]()
+	for i := range requestType.NumField() {
+		field := requestType.Field(i)
+		name := field.Tag.Get("json")
+		if !reflect.DeepEqual(before[name], after[name]) {
+			fields = append(fields, field.Name)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,141 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/jsonpatch"
+	"github.com/google/uuid"
+)
+
+func mustParsePatch(t *testing.T, mediaType string, body string) jsonpatch.Patch {
+	t.Helper()
+
+	patch, err := jsonpatch.Parse(mediaType, []byte(body))
+	if err != nil {
+		t.Fatalf("failed to parse patch %s: %v", body, err)
+	}
+	return patch
+}
+
+func TestTodoServicePatch(t *testing.T) {
+	userID := uuid.New()
+	due := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			home := service.CreateTag(ctx, CreateTagRequest{Name: "home"}, userID).Data.(CreateTagResponse).Tag
+			created := createTodo(t, service, userID, CreateTodoRequest{
+				Title:       "Walk dog",
+				Description: "Around the park",
+				DueAt:       &due,
+				Priority:    "P1",
+				TagNames:    []string{"errand"},
+			})
+
+			patch := func(mediaType string, body string) (TodoResponse, int) {
+				t.Helper()
+				response := service.Patch(ctx, created.Id, mustParsePatch(t, mediaType, body), "", userID)
+				if response.StatusCode != http.StatusOK {
+					return TodoResponse{}, response.StatusCode
+				}
+				return response.Data.(UpdateTodoResponse).Todo, response.StatusCode
+			}
+
+			todo, status := patch(jsonpatch.MergePatchType, `{"completed": true}`)
+			if status != http.StatusOK {
+				t.Fatalf("complete status = %d, want %d", status, http.StatusOK)
+			}
+			if !todo.Completed || todo.Status != "done" {
+				t.Errorf("completed = %v status %q, want completed in done", todo.Completed, todo.Status)
+			}
+			if todo.Title != "Walk dog" || todo.Description != "Around the park" || todo.Priority != "P1" || todo.DueAt == nil {
+				t.Errorf("fields the patch didn't touch changed: %+v", todo)
+			}
+			if got := tagNamesOf(todo); !slices.Equal(got, []string{"errand"}) {
+				t.Errorf("tags = %v, want [errand]", got)
+			}
+
+			todo, status = patch(jsonpatch.JSONPatchType, `[
+				{"op": "test", "path": "/status", "value": "done"},
+				{"op": "replace", "path": "/status", "value": "in_progress"},
+				{"op": "add", "path": "/tag_ids/-", "value": "`+home.Id.String()+`"},
+				{"op": "remove", "path": "/due_at"}
+			]`)
+			if status != http.StatusOK {
+				t.Fatalf("JSON patch status = %d, want %d", status, http.StatusOK)
+			}
+			if todo.Completed || todo.Status != "in_progress" {
+				t.Errorf("completed = %v status %q, want open in in_progress", todo.Completed, todo.Status)
+			}
+			if got := tagNamesOf(todo); !slices.Equal(got, []string{"errand", "home"}) {
+				t.Errorf("tags = %v, want [errand home]", got)
+			}
+			if todo.DueAt != nil {
+				t.Errorf("due = %v, want none", *todo.DueAt)
+			}
+
+			tests := []struct {
+				name       string
+				mediaType  string
+				body       string
+				wantStatus int
+			}{
+				{name: "empty title", mediaType: jsonpatch.MergePatchType, body: `{"title": ""}`, wantStatus: http.StatusUnprocessableEntity},
+				{name: "unknown priority", mediaType: jsonpatch.MergePatchType, body: `{"priority": "P9"}`, wantStatus: http.StatusUnprocessableEntity},
+				{name: "all day without a due date", mediaType: jsonpatch.MergePatchType, body: `{"all_day": true}`, wantStatus: http.StatusUnprocessableEntity},
+				{name: "unknown status", mediaType: jsonpatch.MergePatchType, body: `{"status": "someday"}`, wantStatus: http.StatusUnprocessableEntity},
+				{name: "unknown field", mediaType: jsonpatch.MergePatchType, body: `{"user_id": "` + uuid.NewString() + `"}`, wantStatus: http.StatusUnprocessableEntity},
+				{name: "wrong type", mediaType: jsonpatch.MergePatchType, body: `{"completed": "yes"}`, wantStatus: http.StatusUnprocessableEntity},
+				{name: "missing path", mediaType: jsonpatch.JSONPatchType, body: `[{"op": "remove", "path": "/title/0"}]`, wantStatus: http.StatusUnprocessableEntity},
+				{name: "failed test", mediaType: jsonpatch.JSONPatchType, body: `[{"op": "test", "path": "/title", "value": "Walk cat"}]`, wantStatus: http.StatusConflict},
+			}
+			for _, test := range tests {
+				if _, status := patch(test.mediaType, test.body); status != test.wantStatus {
+					t.Errorf("%s status = %d, want %d", test.name, status, test.wantStatus)
+				}
+			}
+
+			response := service.Patch(ctx, created.Id, mustParsePatch(t, jsonpatch.MergePatchType, `{"completed": true}`), "", uuid.New())
+			if response.StatusCode != http.StatusNotFound {
+				t.Errorf("todo of another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+// Fields the patch leaves alone aren't validated, so a todo that no longer passes validation can still be completed
+func TestTodoServicePatchValidatesOnlyChangedFields(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			todo := seedTodos(t, repository, userID, "Walk dog")[0]
+			todo.Description = strings.Repeat("a", 2000)
+			if err := repository.UpdateTodo(ctx, &todo); err != nil {
+				t.Fatalf("failed to seed description: %v", err)
+			}
+
+			response := service.Patch(ctx, todo.ID, mustParsePatch(t, jsonpatch.MergePatchType, `{"completed": true}`), "", userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("patch status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+			}
+			response = service.Patch(ctx, todo.ID, mustParsePatch(t, jsonpatch.MergePatchType, `{"description": "`+strings.Repeat("b", 1001)+`"}`), "", userID)
+			if response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("long description status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+		})
+	}
+}
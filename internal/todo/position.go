@@ -0,0 +1,90 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Positions are fractional keys, digits of a base 36 fraction in [0, 1) that compare as bytes
+// There is a key between any two keys, so moving a todo only writes the todo itself
+const positionDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// maxPositionLength is the length past which keys are rebalanced, well within the size of the position column
+const maxPositionLength = 64
+
+var (
+	ErrNoPositionBetween = errors.New("no position between the neighbours")
+	ErrInvalidNeighbours = errors.New("the todo after must come before the todo before")
+)
+
+// PositionBetween returns a key that sorts after a and before b, an empty a or b is open ended
+// It fails for keys that are equal, out of order or not made by this package, which rebalancing fixes
+func PositionBetween(a string, b string) (string, error) {
+	if (b != "" && a >= b) || !validPosition(a) || !validPosition(b) {
+		return "", ErrNoPositionBetween
+	}
+	return midpoint(a, b), nil
+}
+
+// validPosition reports whether a key only has position digits and no trailing zero,
+// which would make it equal to the key without it
+func validPosition(position string) bool {
+	for i := range len(position) {
+		if strings.IndexByte(positionDigits, position[i]) < 0 {
+			return false
+		}
+	}
+	return !strings.HasSuffix(position, "0")
+}
+
+// midpoint returns the shortest key between a and b, b is one when empty
+// It keeps the common prefix of the keys, reading a as padded with zeros, and splits the first digit that differs
+func midpoint(a string, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(b) && positionDigit(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(a[min(n, len(a)):], b[n:])
+		}
+	}
+
+	low := strings.IndexByte(positionDigits, positionDigit(a, 0))
+	high := len(positionDigits)
+	if b != "" {
+		high = strings.IndexByte(positionDigits, b[0])
+	}
+	if high-low > 1 {
+		return string(positionDigits[(low+high+1)/2])
+	}
+	// The first digits are consecutive, so the key starts like b if b goes on, or like a followed by something after a
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(positionDigits[low]) + midpoint(a[min(1, len(a)):], "")
+}
+
+func positionDigit(position string, i int) byte {
+	if i < len(position) {
+		return position[i]
+	}
+	return positionDigits[0]
+}
+
+// balancedPositions returns n keys in order, all of the same length and with room before, after and between them
+func balancedPositions(n int) []string {
+	width := len(strconv.Itoa(n))
+	positions := make([]string, n)
+	for i := range positions {
+		positions[i] = fmt.Sprintf("i%0*di", width, i+1)
+	}
+	return positions
+}
+
+// needsRebalance reports whether a key has grown long enough that the todos should get new keys
+func needsRebalance(position string) bool {
+	return len(position) > maxPositionLength
+}
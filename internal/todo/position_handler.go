@@ -0,0 +1,73 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      Reorder todo
+// @Description  Move a todo of the authenticated user in their manual order, right after the todo given as after, right before the todo given as before, or between the two
+// @Tags         Todo
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string              true  "Todo ID"
+// @Param        body  body      ReorderTodoRequest  true  "Reorder Todo Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.ReorderTodoResponse}
+// @Header       200  {string}  ETag  "ETag of the todo"
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      412  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/move [post]
+func (handler TodoHandler) ReorderTodo(ctx *gin.Context) {
+	var req ReorderTodoRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Reorder todo"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Reorder todo"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.ReorderTodo(ctx, todoID, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Reorder todo request failed",
+			logger.F("operation", "Reorder todo"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	if data, ok := response.Data.(ReorderTodoResponse); ok {
+		ctx.Header("ETag", utils.VersionETag(data.Todo.Version))
+	}
+	ctx.JSON(response.StatusCode, response)
+}
@@ -0,0 +1,162 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// errReorderFailed rolls back a rebalance when the move it made room for fails
+var errReorderFailed = errors.New("reorder failed")
+
+// ReorderTodo moves a todo to a new place in the user's manual order, between the todos it is put after and before
+// Only the moved todo gets a new position, unless the positions around it have to be rebalanced first,
+// in which case the rebalance and the move go in one transaction
+func (service TodoService) ReorderTodo(ctx context.Context, todoID uuid.UUID, req ReorderTodoRequest, userID uuid.UUID) models.Response {
+	var response models.Response
+	err := service.inTransaction(ctx, func(service TodoService) error {
+		response = service.reorderTodo(ctx, todoID, req, userID)
+		if response.StatusCode >= http.StatusMultipleChoices {
+			return errReorderFailed
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errReorderFailed) {
+		service.log.Error("Failed to reorder todo",
+			logger.F("operation", "Reorder todo"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to reorder todo", err, service.isDebug)
+	}
+	return response
+}
+
+func (service TodoService) reorderTodo(ctx context.Context, todoID uuid.UUID, req ReorderTodoRequest, userID uuid.UUID) models.Response {
+	for rebalanced := false; ; rebalanced = true {
+		todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
+		if err != nil {
+			service.log.Error("Failed to find todo",
+				logger.F("operation", "Reorder todo"),
+				logger.F("todo_id", todoID.String()),
+				logger.F("user_id", userID.String()),
+				logger.F("error", err),
+			)
+			return utils.NotFoundResponse("Todo not found", err, service.isDebug)
+		}
+
+		lower, upper, response, ok := service.neighbourPositions(ctx, todo, req, userID)
+		if !ok {
+			return response
+		}
+		position, err := PositionBetween(lower, upper)
+		if err == nil && !needsRebalance(position) {
+			return service.saveTodoPosition(ctx, todo, position, userID)
+		}
+		if rebalanced {
+			return utils.InternalServerErrorResponse("Failed to reorder todo", ErrNoPositionBetween, service.isDebug)
+		}
+
+		// The neighbours share a position or their keys grew too long, rebalancing spreads them out again
+		if err := service.todoRepository.RebalanceTodoPositions(ctx, userID); err != nil {
+			service.log.Error("Failed to rebalance todo positions",
+				logger.F("operation", "Reorder todo"),
+				logger.F("user_id", userID.String()),
+				logger.F("error", err),
+			)
+			return utils.InternalServerErrorResponse("Failed to reorder todo", err, service.isDebug)
+		}
+	}
+}
+
+// neighbourPositions returns the positions that the todo goes between, an empty position is open ended
+// A missing neighbour is the todo next to the given one, so that the todo ends up right after or before it
+func (service TodoService) neighbourPositions(ctx context.Context, todo *Todo, req ReorderTodoRequest, userID uuid.UUID) (string, string, models.Response, bool) {
+	after, response, ok := service.neighbour(ctx, todo, req.After, userID)
+	if !ok {
+		return "", "", response, false
+	}
+	before, response, ok := service.neighbour(ctx, todo, req.Before, userID)
+	if !ok {
+		return "", "", response, false
+	}
+	if after == nil && before == nil {
+		return "", "", utils.UnprocessableEntityResponse("Invalid neighbours", ErrInvalidNeighbours, service.isDebug), false
+	}
+	if after != nil && before != nil && compareTodos(*after, *before, "position") >= 0 {
+		return "", "", utils.UnprocessableEntityResponse("Invalid neighbours", ErrInvalidNeighbours, service.isDebug), false
+	}
+
+	var lower, upper string
+	var err error
+	if after != nil {
+		lower = after.Position
+	} else {
+		lower, err = service.todoRepository.FindAdjacentTodoPosition(ctx, before, true, todo.ID)
+	}
+	if before != nil {
+		upper = before.Position
+	} else {
+		upper, err = service.todoRepository.FindAdjacentTodoPosition(ctx, after, false, todo.ID)
+	}
+	if err != nil {
+		service.log.Error("Failed to find neighbour positions",
+			logger.F("operation", "Reorder todo"),
+			logger.F("todo_id", todo.ID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return "", "", utils.InternalServerErrorResponse("Failed to reorder todo", err, service.isDebug), false
+	}
+	return lower, upper, models.Response{}, true
+}
+
+// neighbour finds a neighbour of the todo by its ID, a nil ID has no neighbour
+func (service TodoService) neighbour(ctx context.Context, todo *Todo, neighbourID *uuid.UUID, userID uuid.UUID) (*Todo, models.Response, bool) {
+	if neighbourID == nil {
+		return nil, models.Response{}, true
+	}
+	if *neighbourID == todo.ID {
+		return nil, utils.UnprocessableEntityResponse("A todo can't be its own neighbour", ErrInvalidNeighbours, service.isDebug), false
+	}
+
+	neighbour, err := service.todoRepository.FindTodoByIDAndUserID(ctx, *neighbourID, userID)
+	if err != nil {
+		return nil, utils.NotFoundResponse("Neighbour todo not found", err, service.isDebug), false
+	}
+	return neighbour, models.Response{}, true
+}
+
+func (service TodoService) saveTodoPosition(ctx context.Context, todo *Todo, position string, userID uuid.UUID) models.Response {
+	before := *todo
+	todo.Position = position
+	err := service.inTransaction(ctx, func(service TodoService) error {
+		if err := service.todoRepository.UpdateTodo(ctx, todo, "position"); err != nil {
+			return err
+		}
+		return service.recordHistory(ctx, HistoryUpdate, before, *todo, userID)
+	})
+	if errors.Is(err, ErrVersionConflict) {
+		return utils.PreconditionFailedResponse("Todo has been changed", err, service.isDebug)
+	}
+	if err != nil {
+		service.log.Error("Failed to reorder todo",
+			logger.F("operation", "Reorder todo"),
+			logger.F("todo_id", todo.ID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to reorder todo", err, service.isDebug)
+	}
+
+	responseData := ReorderTodoResponse{
+		Todo: NewTodoResponse(*todo),
+	}
+	return utils.OkResponse("Todo reordered successfully", responseData)
+}
@@ -0,0 +1,220 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/internal/auth"
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/Alfian57/golang-todo/pkg/database/databasetest"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/migrate"
+	"github.com/Alfian57/golang-todo/pkg/module"
+	"github.com/google/uuid"
+)
+
+func TestPositionBetween(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    string
+		wantErr bool
+	}{
+		{a: "", b: "", want: "i"},
+		{a: "i", b: "", want: "r"},
+		{a: "", b: "i", want: "9"},
+		{a: "z", b: "", want: "zi"},
+		{a: "", b: "1", want: "0i"},
+		{a: "a", b: "b", want: "ai"},
+		{a: "i000001i", b: "i000002i", want: "i000002"},
+		{a: "i000001i", b: "i000002", want: "i000001r"},
+		{a: "b", b: "a", wantErr: true},
+		{a: "a", b: "a", wantErr: true},
+		{a: "a0", b: "", wantErr: true},
+		{a: "A", b: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := PositionBetween(tt.a, tt.b)
+		if tt.wantErr {
+			if !errors.Is(err, ErrNoPositionBetween) {
+				t.Errorf("PositionBetween(%q, %q) error = %v, want %v", tt.a, tt.b, err, ErrNoPositionBetween)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("PositionBetween(%q, %q) = %q (%v), want %q", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+}
+
+// Inserting at random places must keep every key strictly between its neighbours
+func TestPositionBetweenKeepsOrder(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	positions := balancedPositions(3)
+	for range 1000 {
+		i := random.IntN(len(positions) + 1)
+		var a, b string
+		if i > 0 {
+			a = positions[i-1]
+		}
+		if i < len(positions) {
+			b = positions[i]
+		}
+		position, err := PositionBetween(a, b)
+		if err != nil {
+			t.Fatalf("PositionBetween(%q, %q) failed: %v", a, b, err)
+		}
+		positions = slices.Insert(positions, i, position)
+	}
+	if !sort.StringsAreSorted(positions) {
+		t.Error("positions are out of order")
+	}
+	if got := balancedPositions(12); got[0] != "i01i" || got[11] != "i12i" || !sort.StringsAreSorted(got) {
+		t.Errorf("balanced positions = %v", got)
+	}
+}
+
+func TestTodoServiceReorderTodo(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			// New todos go first, so the manual order starts out newest first
+			todos := map[string]uuid.UUID{}
+			for _, title := range []string{"D", "C", "B", "A"} {
+				todos[title] = createTodo(t, service, userID, CreateTodoRequest{Title: title}).Id
+			}
+			order := func() string {
+				t.Helper()
+				response := service.GetAll(ctx, userID, GetTodosRequest{})
+				var titles string
+				for _, todo := range response.Data.(GetTodosResponse).Todos {
+					titles += todo.Title
+				}
+				return titles
+			}
+			if got := order(); got != "ABCD" {
+				t.Fatalf("initial order = %s, want ABCD", got)
+			}
+			id := func(title string) *uuid.UUID {
+				id := todos[title]
+				return &id
+			}
+
+			tests := []struct {
+				name       string
+				todo       string
+				req        ReorderTodoRequest
+				wantStatus int
+				wantOrder  string
+			}{
+				{name: "after a todo", todo: "A", req: ReorderTodoRequest{After: id("C")}, wantStatus: http.StatusOK, wantOrder: "BCAD"},
+				{name: "before a todo", todo: "D", req: ReorderTodoRequest{Before: id("B")}, wantStatus: http.StatusOK, wantOrder: "DBCA"},
+				{name: "after the last todo", todo: "D", req: ReorderTodoRequest{After: id("A")}, wantStatus: http.StatusOK, wantOrder: "BCAD"},
+				{name: "before the first todo", todo: "A", req: ReorderTodoRequest{Before: id("B")}, wantStatus: http.StatusOK, wantOrder: "ABCD"},
+				{name: "between two todos", todo: "D", req: ReorderTodoRequest{After: id("A"), Before: id("B")}, wantStatus: http.StatusOK, wantOrder: "ADBC"},
+				{name: "own neighbour", todo: "D", req: ReorderTodoRequest{After: id("D")}, wantStatus: http.StatusUnprocessableEntity, wantOrder: "ADBC"},
+				{name: "neighbours out of order", todo: "D", req: ReorderTodoRequest{After: id("C"), Before: id("B")}, wantStatus: http.StatusUnprocessableEntity, wantOrder: "ADBC"},
+				{name: "unknown neighbour", todo: "D", req: ReorderTodoRequest{After: new(uuid.UUID)}, wantStatus: http.StatusNotFound, wantOrder: "ADBC"},
+			}
+			for _, tt := range tests {
+				response := service.ReorderTodo(ctx, todos[tt.todo], tt.req, userID)
+				if response.StatusCode != tt.wantStatus {
+					t.Errorf("%s status = %d, want %d (%s)", tt.name, response.StatusCode, tt.wantStatus, response.Message)
+				}
+				if got := order(); got != tt.wantOrder {
+					t.Errorf("%s order = %s, want %s", tt.name, got, tt.wantOrder)
+				}
+			}
+
+			other := createTodo(t, service, uuid.New(), CreateTodoRequest{Title: "Other"})
+			if response := service.ReorderTodo(ctx, todos["A"], ReorderTodoRequest{After: &other.Id}, userID); response.StatusCode != http.StatusNotFound {
+				t.Errorf("neighbour of another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+			if response := service.ReorderTodo(ctx, other.Id, ReorderTodoRequest{After: id("A")}, userID); response.StatusCode != http.StatusNotFound {
+				t.Errorf("todo of another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+
+			// Todos sharing a position leave no room between them until they are rebalanced
+			untouched, _ := repository.FindTodoByIDAndUserID(ctx, todos["A"], userID)
+			for _, title := range []string{"D", "B"} {
+				todo, _ := repository.FindTodoByIDAndUserID(ctx, todos[title], userID)
+				todo.Position = "i"
+				if err := repository.UpdateTodo(ctx, todo, "position"); err != nil {
+					t.Fatalf("failed to seed position: %v", err)
+				}
+			}
+			first, second := "B", "D"
+			if todos["D"].String() < todos["B"].String() {
+				first, second = "D", "B"
+			}
+			response := service.ReorderTodo(ctx, todos["C"], ReorderTodoRequest{After: id(first), Before: id(second)}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("reorder between tied todos status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+			}
+			if got := order(); got != "A"+first+"C"+second {
+				t.Errorf("order after rebalancing = %s, want A%sC%s", got, first, second)
+			}
+			todo, _ := repository.FindTodoByIDAndUserID(ctx, todos["A"], userID)
+			// Rebalancing only rewrites position keys, so ETags and undo tokens of the other todos stay valid
+			if todo.Position != "i1i" || todo.Version != untouched.Version {
+				t.Errorf("rebalanced todo position %q version %d, want i1i and version %d", todo.Position, todo.Version, untouched.Version)
+			}
+		})
+	}
+}
+
+// Existing todos get positions in the order they were listed in before, newest first
+func TestAddTodoPositionMigration(t *testing.T) {
+	ctx := context.Background()
+	db := databasetest.NewSQLite(t)
+	runner, err := migrate.NewModuleRunner(db, config.DriverSQLite, logger.NewNopLogger(), []module.Module{auth.Module{}, Module{}})
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if err := runner.To(ctx, 12); err != nil {
+		t.Fatalf("failed to migrate to the schema without positions: %v", err)
+	}
+	if err := db.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+		t.Fatalf("failed to disable foreign keys: %v", err)
+	}
+
+	userID := uuid.New()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, title := range []string{"Oldest", "Middle", "Newest"} {
+		createdAt := base.Add(time.Duration(i) * time.Hour)
+		err := db.Exec(`INSERT INTO todos (id, title, user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			uuid.New(), title, userID, createdAt, createdAt).Error
+		if err != nil {
+			t.Fatalf("failed to seed todo: %v", err)
+		}
+	}
+
+	if err := runner.Up(ctx); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	filter, _ := NewTodoFilter(GetTodosRequest{}, time.Now())
+	todos, err := NewTodoRepository(db).FindAllTodoByUserID(ctx, userID.String(), filter)
+	if err != nil {
+		t.Fatalf("failed to list todos: %v", err)
+	}
+	var got []string
+	for _, todo := range todos {
+		got = append(got, todo.Title+" "+todo.Position)
+	}
+	want := []string{"Newest i000000001i", "Middle i000000002i", "Oldest i000000003i"}
+	if !slices.Equal(got, want) {
+		t.Errorf("todos = %v, want %v", got, want)
+	}
+}
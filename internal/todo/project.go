@@ -0,0 +1,46 @@
+package todo
+
+import (
+	"errors"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/google/uuid"
+)
+
+// InboxProject selects the todos that are not in any project
+const InboxProject = "inbox"
+
+var (
+	ErrInvalidProject  = errors.New("invalid project, expected a project id or inbox")
+	ErrUnknownProject  = errors.New("project does not exist")
+	ErrArchivedProject = errors.New("project is archived")
+)
+
+// Project groups todos of a user, todos without a project are in the user's inbox
+type Project struct {
+	models.Base
+	UserID      uuid.UUID
+	Name        string
+	Description string
+	Color       string
+	Archived    bool
+	Position    int
+}
+
+// TodoCounts counts the open and completed todos of a project
+type TodoCounts struct {
+	Open      int64
+	Completed int64
+}
+
+// ParseProject parses a project filter, where uuid.Nil stands for the inbox
+func ParseProject(value string) (uuid.UUID, error) {
+	if value == InboxProject {
+		return uuid.Nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, ErrInvalidProject
+	}
+	return id, nil
+}
@@ -0,0 +1,415 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      Get all projects
+// @Description  Get the projects of the authenticated user in their order, with the open and completed todo counts of each project and of the inbox
+// @Tags         Project
+// @Produce      json
+// @Param        archived  query     bool  false  "Include archived projects"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetProjectsResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /projects [get]
+func (handler TodoHandler) GetProjects(ctx *gin.Context) {
+	var req GetProjectsRequest
+	if !utils.ValidateQuery(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get projects"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetProjects(ctx, userID, req)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get projects request failed",
+			logger.F("operation", "Get projects"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Get project
+// @Description  Get a project of the authenticated user with its todo counts
+// @Tags         Project
+// @Produce      json
+// @Param        id   path      string  true  "Project ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetProjectResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /projects/{id} [get]
+func (handler TodoHandler) GetProject(ctx *gin.Context) {
+	// Get project ID from URL parameter
+	projectIDStr := ctx.Param("id")
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid project ID",
+			logger.F("operation", "Get project"),
+			logger.F("project_id", projectIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid project ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get project"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetProject(ctx, projectID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get project request failed",
+			logger.F("operation", "Get project"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("project_id", projectID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Create project
+// @Description  Create a project for the authenticated user after their other projects
+// @Tags         Project
+// @Accept       json
+// @Produce      json
+// @Param        body  body      CreateProjectRequest  true  "Create Project Request"
+// @Security	 BearerAuth
+// @Success      201  {object}  models.Response{data=todo.CreateProjectResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /projects [post]
+func (handler TodoHandler) CreateProject(ctx *gin.Context) {
+	var req CreateProjectRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Create project"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.CreateProject(ctx, req, userID)
+	if response.StatusCode != 201 {
+		handler.log.Warn("Create project request failed",
+			logger.F("operation", "Create project"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Update project
+// @Description  Rename, describe or recolor a project of the authenticated user
+// @Tags         Project
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                true  "Project ID"
+// @Param        body  body      UpdateProjectRequest  true  "Update Project Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.UpdateProjectResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /projects/{id} [put]
+func (handler TodoHandler) UpdateProject(ctx *gin.Context) {
+	var req UpdateProjectRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get project ID from URL parameter
+	projectIDStr := ctx.Param("id")
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid project ID",
+			logger.F("operation", "Update project"),
+			logger.F("project_id", projectIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid project ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Update project"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.UpdateProject(ctx, projectID, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Update project request failed",
+			logger.F("operation", "Update project"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("project_id", projectID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Archive project
+// @Description  Archive a project of the authenticated user, its todos are kept but it no longer accepts new ones
+// @Tags         Project
+// @Produce      json
+// @Param        id   path      string  true  "Project ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.UpdateProjectResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /projects/{id}/archive [post]
+func (handler TodoHandler) ArchiveProject(ctx *gin.Context) {
+	// Get project ID from URL parameter
+	projectIDStr := ctx.Param("id")
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid project ID",
+			logger.F("operation", "Archive project"),
+			logger.F("project_id", projectIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid project ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Archive project"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.SetProjectArchived(ctx, projectID, true, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Archive project request failed",
+			logger.F("operation", "Archive project"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("project_id", projectID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Unarchive project
+// @Description  Take a project of the authenticated user out of the archive
+// @Tags         Project
+// @Produce      json
+// @Param        id   path      string  true  "Project ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.UpdateProjectResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /projects/{id}/unarchive [post]
+func (handler TodoHandler) UnarchiveProject(ctx *gin.Context) {
+	// Get project ID from URL parameter
+	projectIDStr := ctx.Param("id")
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid project ID",
+			logger.F("operation", "Unarchive project"),
+			logger.F("project_id", projectIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid project ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Unarchive project"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.SetProjectArchived(ctx, projectID, false, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Unarchive project request failed",
+			logger.F("operation", "Unarchive project"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("project_id", projectID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Reorder projects
+// @Description  Move the given projects of the authenticated user to the front in the given order, the others keep their order after them
+// @Tags         Project
+// @Accept       json
+// @Produce      json
+// @Param        body  body      ReorderProjectsRequest  true  "Reorder Projects Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetProjectsResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /projects/order [put]
+func (handler TodoHandler) ReorderProjects(ctx *gin.Context) {
+	var req ReorderProjectsRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Reorder projects"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.ReorderProjects(ctx, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Reorder projects request failed",
+			logger.F("operation", "Reorder projects"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Move todo
+// @Description  Move a todo of the authenticated user to another project, or to the inbox when project_id is null
+// @Tags         Todo
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string           true  "Todo ID"
+// @Param        body  body      MoveTodoRequest  true  "Move Todo Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.MoveTodoResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/project [put]
+func (handler TodoHandler) MoveTodo(ctx *gin.Context) {
+	var req MoveTodoRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Move todo"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Move todo"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.MoveTodo(ctx, todoID, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Move todo request failed",
+			logger.F("operation", "Move todo"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
@@ -0,0 +1,315 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// checkProject makes sure todos can be added to the project
+// It returns the error response to send when they can't
+func (service TodoService) checkProject(ctx context.Context, projectID uuid.UUID, userID uuid.UUID, operation string) (models.Response, bool) {
+	project, err := service.todoRepository.FindProjectByIDAndUserID(ctx, projectID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.UnprocessableEntityResponse("Unknown project", ErrUnknownProject, service.isDebug), false
+	}
+	if err != nil {
+		service.log.Error("Failed to find project",
+			logger.F("operation", operation),
+			logger.F("project_id", projectID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to find project", err, service.isDebug), false
+	}
+	if project.Archived {
+		return utils.UnprocessableEntityResponse("Project is archived", ErrArchivedProject, service.isDebug), false
+	}
+	return models.Response{}, true
+}
+
+// projectResponse builds the response of a single project with its todo counts
+func (service TodoService) projectResponse(ctx context.Context, project Project, userID uuid.UUID) (ProjectResponse, error) {
+	counts, err := service.todoRepository.CountTodosByProject(ctx, userID)
+	if err != nil {
+		return ProjectResponse{}, err
+	}
+	return NewProjectResponse(project, counts[project.ID]), nil
+}
+
+// GetProjects lists the user's projects in their order along with the inbox, each with its todo counts
+func (service TodoService) GetProjects(ctx context.Context, userID uuid.UUID, req GetProjectsRequest) models.Response {
+	responseData, err := service.projectList(ctx, userID, req.Archived)
+	if err != nil {
+		service.log.Error("Failed to get projects",
+			logger.F("operation", "Get projects"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get projects", err, service.isDebug)
+	}
+	return utils.OkResponse("Projects retrieved successfully", responseData)
+}
+
+func (service TodoService) projectList(ctx context.Context, userID uuid.UUID, archived bool) (GetProjectsResponse, error) {
+	projects, err := service.todoRepository.FindProjectsByUserID(ctx, userID, archived)
+	if err != nil {
+		return GetProjectsResponse{}, err
+	}
+	counts, err := service.todoRepository.CountTodosByProject(ctx, userID)
+	if err != nil {
+		return GetProjectsResponse{}, err
+	}
+
+	inbox := counts[uuid.Nil]
+	responseData := GetProjectsResponse{
+		Inbox: InboxResponse{
+			OpenCount:      inbox.Open,
+			CompletedCount: inbox.Completed,
+		},
+		Projects: make([]ProjectResponse, 0, len(projects)),
+	}
+	for _, project := range projects {
+		responseData.Projects = append(responseData.Projects, NewProjectResponse(project, counts[project.ID]))
+	}
+	return responseData, nil
+}
+
+func (service TodoService) GetProject(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) models.Response {
+	project, err := service.todoRepository.FindProjectByIDAndUserID(ctx, projectID, userID)
+	if err != nil {
+		service.log.Error("Failed to find project",
+			logger.F("operation", "Get project"),
+			logger.F("project_id", projectID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Project not found", err, service.isDebug)
+	}
+
+	response, err := service.projectResponse(ctx, *project, userID)
+	if err != nil {
+		service.log.Error("Failed to count todos by project",
+			logger.F("operation", "Get project"),
+			logger.F("project_id", projectID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get project", err, service.isDebug)
+	}
+
+	return utils.OkResponse("Project retrieved successfully", GetProjectResponse{Project: response})
+}
+
+func (service TodoService) CreateProject(ctx context.Context, req CreateProjectRequest, userID uuid.UUID) models.Response {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return utils.UnprocessableEntityResponse("Project name is required", nil, service.isDebug)
+	}
+
+	project := Project{
+		UserID:      userID,
+		Name:        name,
+		Description: req.Description,
+		Color:       colorOrDefault(req.Color),
+	}
+	err := service.todoRepository.CreateProject(ctx, &project)
+	if err != nil {
+		service.log.Error("Failed to create project",
+			logger.F("operation", "Create project"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to create project", err, service.isDebug)
+	}
+
+	responseData := CreateProjectResponse{
+		Project: NewProjectResponse(project, TodoCounts{}),
+	}
+	return utils.CreatedResponse("Success to create project", responseData)
+}
+
+// UpdateProject renames, describes or recolors a project
+func (service TodoService) UpdateProject(ctx context.Context, projectID uuid.UUID, req UpdateProjectRequest, userID uuid.UUID) models.Response {
+	project, err := service.todoRepository.FindProjectByIDAndUserID(ctx, projectID, userID)
+	if err != nil {
+		service.log.Error("Failed to find project",
+			logger.F("operation", "Update project"),
+			logger.F("project_id", projectID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Project not found", err, service.isDebug)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return utils.UnprocessableEntityResponse("Project name is required", nil, service.isDebug)
+	}
+
+	project.Name = name
+	project.Description = req.Description
+	project.Color = colorOrDefault(req.Color)
+	return service.saveProject(ctx, project, userID, "Update project", "Project updated successfully")
+}
+
+// SetProjectArchived archives or unarchives a project
+// Archived projects keep their todos but are hidden from the project list and can't receive new todos
+func (service TodoService) SetProjectArchived(ctx context.Context, projectID uuid.UUID, archived bool, userID uuid.UUID) models.Response {
+	operation, message := "Archive project", "Project archived successfully"
+	if !archived {
+		operation, message = "Unarchive project", "Project unarchived successfully"
+	}
+
+	project, err := service.todoRepository.FindProjectByIDAndUserID(ctx, projectID, userID)
+	if err != nil {
+		service.log.Error("Failed to find project",
+			logger.F("operation", operation),
+			logger.F("project_id", projectID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Project not found", err, service.isDebug)
+	}
+
+	project.Archived = archived
+	return service.saveProject(ctx, project, userID, operation, message)
+}
+
+func (service TodoService) saveProject(ctx context.Context, project *Project, userID uuid.UUID, operation string, message string) models.Response {
+	err := service.todoRepository.UpdateProject(ctx, project)
+	if err != nil {
+		service.log.Error("Failed to save project",
+			logger.F("operation", operation),
+			logger.F("project_id", project.ID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to update project", err, service.isDebug)
+	}
+
+	response, err := service.projectResponse(ctx, *project, userID)
+	if err != nil {
+		service.log.Error("Failed to count todos by project",
+			logger.F("operation", operation),
+			logger.F("project_id", project.ID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to update project", err, service.isDebug)
+	}
+
+	return utils.OkResponse(message, UpdateProjectResponse{Project: response})
+}
+
+// ReorderProjects moves the given projects to the front in the given order
+// Projects that are left out, including archived ones, keep their relative order after them
+func (service TodoService) ReorderProjects(ctx context.Context, req ReorderProjectsRequest, userID uuid.UUID) models.Response {
+	projects, err := service.todoRepository.FindProjectsByUserID(ctx, userID, true)
+	if err != nil {
+		service.log.Error("Failed to get projects",
+			logger.F("operation", "Reorder projects"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to reorder projects", err, service.isDebug)
+	}
+
+	byID := make(map[uuid.UUID]Project, len(projects))
+	for _, project := range projects {
+		byID[project.ID] = project
+	}
+
+	ordered := make([]Project, 0, len(projects))
+	placed := make(map[uuid.UUID]bool, len(req.ProjectIDs))
+	for _, id := range req.ProjectIDs {
+		project, exists := byID[id]
+		if !exists {
+			return utils.UnprocessableEntityResponse("Unknown project", ErrUnknownProject, service.isDebug)
+		}
+		if placed[id] {
+			continue
+		}
+		placed[id] = true
+		ordered = append(ordered, project)
+	}
+	for _, project := range projects {
+		if !placed[project.ID] {
+			ordered = append(ordered, project)
+		}
+	}
+	for i := range ordered {
+		ordered[i].Position = i
+	}
+
+	err = service.todoRepository.UpdateProjectPositions(ctx, ordered)
+	if err != nil {
+		service.log.Error("Failed to update project positions",
+			logger.F("operation", "Reorder projects"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to reorder projects", err, service.isDebug)
+	}
+
+	responseData, err := service.projectList(ctx, userID, true)
+	if err != nil {
+		service.log.Error("Failed to get projects",
+			logger.F("operation", "Reorder projects"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to reorder projects", err, service.isDebug)
+	}
+	return utils.OkResponse("Projects reordered successfully", responseData)
+}
+
+// MoveTodo moves a todo to another project, or to the inbox when no project is given
+func (service TodoService) MoveTodo(ctx context.Context, todoID uuid.UUID, req MoveTodoRequest, userID uuid.UUID) models.Response {
+	todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find todo",
+			logger.F("operation", "Move todo"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found", err, service.isDebug)
+	}
+
+	if req.ProjectID != nil {
+		if response, ok := service.checkProject(ctx, *req.ProjectID, userID, "Move todo"); !ok {
+			return response
+		}
+	}
+
+	before := *todo
+	todo.ProjectID = req.ProjectID
+	err = service.inTransaction(ctx, func(service TodoService) error {
+		if err := service.todoRepository.UpdateTodo(ctx, todo, "project_id"); err != nil {
+			return err
+		}
+		return service.recordHistory(ctx, HistoryUpdate, before, *todo, userID)
+	})
+	if err != nil {
+		service.log.Error("Failed to move todo",
+			logger.F("operation", "Move todo"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to move todo", err, service.isDebug)
+	}
+	service.undo.add(&before, *todo, false)
+
+	responseData := MoveTodoResponse{
+		Todo: NewTodoResponse(*todo),
+	}
+	return utils.OkResponse("Todo moved successfully", responseData)
+}
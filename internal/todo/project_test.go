@@ -0,0 +1,219 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func projectNamesOf(projects []ProjectResponse) []string {
+	names := make([]string, 0, len(projects))
+	for _, project := range projects {
+		names = append(names, project.Name)
+	}
+	return names
+}
+
+// createProject creates a project through the service and returns its ID
+func createProject(t *testing.T, service TodoService, userID uuid.UUID, name string) uuid.UUID {
+	t.Helper()
+
+	response := service.CreateProject(context.Background(), CreateProjectRequest{Name: name}, userID)
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create project %q: %s", name, response.Message)
+	}
+	return response.Data.(CreateProjectResponse).Project.Id
+}
+
+func TestParseProject(t *testing.T) {
+	id := uuid.New()
+	if got, err := ParseProject(id.String()); err != nil || got != id {
+		t.Errorf("ParseProject(id) = %v, %v, want %v", got, err, id)
+	}
+	if got, err := ParseProject(InboxProject); err != nil || got != uuid.Nil {
+		t.Errorf("ParseProject(inbox) = %v, %v, want uuid.Nil", got, err)
+	}
+	for _, value := range []string{"Inbox", "42", uuid.Nil.String()} {
+		if _, err := ParseProject(value); err != ErrInvalidProject {
+			t.Errorf("ParseProject(%q) error = %v, want ErrInvalidProject", value, err)
+		}
+	}
+}
+
+func TestTodoServiceProjects(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			work := createProject(t, service, userID, "Work")
+			home := createProject(t, service, userID, "Home")
+			garden := createProject(t, service, userID, "Garden")
+			createProject(t, service, uuid.New(), "Not mine")
+
+			// Todos go to the inbox unless they are created in a project
+			for _, req := range []CreateTodoRequest{
+				{Title: "Call boss", ProjectID: &work},
+				{Title: "Write report", ProjectID: &work},
+				{Title: "Fix sink", ProjectID: &home},
+				{Title: "Buy milk"},
+			} {
+				if response := service.Create(ctx, req, userID); response.StatusCode != http.StatusCreated {
+					t.Fatalf("create %q status = %d, want %d", req.Title, response.StatusCode, http.StatusCreated)
+				}
+			}
+
+			response := service.GetAll(ctx, userID, GetTodosRequest{Project: work.String(), Sort: "title"})
+			if got := titlesOf(response.Data.(GetTodosResponse).Todos); !slices.Equal(got, []string{"Call boss", "Write report"}) {
+				t.Errorf("work todos = %v, want [Call boss Write report]", got)
+			}
+			response = service.GetAll(ctx, userID, GetTodosRequest{Project: InboxProject})
+			inboxTodos := response.Data.(GetTodosResponse).Todos
+			if got := titlesOf(inboxTodos); !slices.Equal(got, []string{"Buy milk"}) {
+				t.Fatalf("inbox todos = %v, want [Buy milk]", got)
+			}
+			if response := service.GetAll(ctx, userID, GetTodosRequest{Project: "someday"}); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("invalid project status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+
+			// Completing a todo moves it between the counts
+			callBoss := service.GetAll(ctx, userID, GetTodosRequest{Project: work.String(), Sort: "title"}).Data.(GetTodosResponse).Todos[0]
+			service.Update(ctx, callBoss.Id, UpdateTodoRequest{Title: callBoss.Title, Completed: true}, userID)
+
+			response = service.MoveTodo(ctx, inboxTodos[0].Id, MoveTodoRequest{ProjectID: &garden}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("move status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if moved := response.Data.(MoveTodoResponse).Todo; moved.ProjectID == nil || *moved.ProjectID != garden {
+				t.Errorf("moved project = %v, want %v", moved.ProjectID, garden)
+			}
+
+			response = service.GetProjects(ctx, userID, GetProjectsRequest{})
+			projects := response.Data.(GetProjectsResponse)
+			if got := projectNamesOf(projects.Projects); !slices.Equal(got, []string{"Work", "Home", "Garden"}) {
+				t.Fatalf("projects = %v, want [Work Home Garden]", got)
+			}
+			wantCounts := map[string][2]int64{"Work": {1, 1}, "Home": {1, 0}, "Garden": {1, 0}}
+			for _, project := range projects.Projects {
+				if got := [2]int64{project.OpenCount, project.CompletedCount}; got != wantCounts[project.Name] {
+					t.Errorf("%s counts = %v, want %v", project.Name, got, wantCounts[project.Name])
+				}
+			}
+			if projects.Inbox.OpenCount != 0 || projects.Inbox.CompletedCount != 0 {
+				t.Errorf("inbox counts = %+v, want none", projects.Inbox)
+			}
+
+			// Moving back to the inbox clears the project
+			response = service.MoveTodo(ctx, inboxTodos[0].Id, MoveTodoRequest{}, userID)
+			if moved := response.Data.(MoveTodoResponse).Todo; moved.ProjectID != nil {
+				t.Errorf("moved project = %v, want the inbox", moved.ProjectID)
+			}
+			if response := service.MoveTodo(ctx, inboxTodos[0].Id, MoveTodoRequest{}, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("move todo of another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestTodoServiceArchiveProject(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			work := createProject(t, service, userID, "Work")
+			home := createProject(t, service, userID, "Home")
+			todo := service.Create(ctx, CreateTodoRequest{Title: "Call boss", ProjectID: &work}, userID).Data.(CreateTodoResponse).Todo
+
+			response := service.SetProjectArchived(ctx, work, true, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("archive status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if archived := response.Data.(UpdateProjectResponse).Project; !archived.Archived || archived.OpenCount != 1 {
+				t.Errorf("archived = %+v, want an archived project that keeps its todo", archived)
+			}
+
+			if got := projectNamesOf(service.GetProjects(ctx, userID, GetProjectsRequest{}).Data.(GetProjectsResponse).Projects); !slices.Equal(got, []string{"Home"}) {
+				t.Errorf("active projects = %v, want [Home]", got)
+			}
+			if got := projectNamesOf(service.GetProjects(ctx, userID, GetProjectsRequest{Archived: true}).Data.(GetProjectsResponse).Projects); !slices.Equal(got, []string{"Work", "Home"}) {
+				t.Errorf("all projects = %v, want [Work Home]", got)
+			}
+
+			// Archived projects and projects of other users don't accept todos
+			if response := service.Create(ctx, CreateTodoRequest{Title: "Write report", ProjectID: &work}, userID); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("create in archived project status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+			if response := service.MoveTodo(ctx, todo.Id, MoveTodoRequest{ProjectID: &home}, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("move as another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+			other := createProject(t, service, uuid.New(), "Not mine")
+			if response := service.MoveTodo(ctx, todo.Id, MoveTodoRequest{ProjectID: &other}, userID); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("move to a project of another user status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+
+			if response := service.SetProjectArchived(ctx, work, false, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("unarchive status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if response := service.Create(ctx, CreateTodoRequest{Title: "Write report", ProjectID: &work}, userID); response.StatusCode != http.StatusCreated {
+				t.Errorf("create in unarchived project status = %d, want %d", response.StatusCode, http.StatusCreated)
+			}
+		})
+	}
+}
+
+func TestTodoServiceUpdateAndReorderProjects(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			work := createProject(t, service, userID, "Work")
+			home := createProject(t, service, userID, "Home")
+			garden := createProject(t, service, userID, "Garden")
+			service.SetProjectArchived(ctx, home, true, userID)
+
+			response := service.UpdateProject(ctx, work, UpdateProjectRequest{Name: "Office", Description: "Day job", Color: "#123ABC"}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("update status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if updated := response.Data.(UpdateProjectResponse).Project; updated.Name != "Office" || updated.Description != "Day job" || updated.Color != "#123abc" {
+				t.Errorf("updated = %+v, want Office with its description and color", updated)
+			}
+			if response := service.UpdateProject(ctx, work, UpdateProjectRequest{Name: "Office"}, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("update project of another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+
+			response = service.ReorderProjects(ctx, ReorderProjectsRequest{ProjectIDs: []uuid.UUID{garden, work}}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("reorder status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if got := projectNamesOf(response.Data.(GetProjectsResponse).Projects); !slices.Equal(got, []string{"Garden", "Office", "Home"}) {
+				t.Errorf("reordered = %v, want [Garden Office Home]", got)
+			}
+
+			// New projects go last
+			createProject(t, service, userID, "Errands")
+			if got := projectNamesOf(service.GetProjects(ctx, userID, GetProjectsRequest{}).Data.(GetProjectsResponse).Projects); !slices.Equal(got, []string{"Garden", "Office", "Errands"}) {
+				t.Errorf("projects = %v, want [Garden Office Errands]", got)
+			}
+
+			other := createProject(t, service, uuid.New(), "Not mine")
+			if response := service.ReorderProjects(ctx, ReorderProjectsRequest{ProjectIDs: []uuid.UUID{other}}, userID); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("reorder with a project of another user status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+		})
+	}
+}
@@ -0,0 +1,492 @@
+package todo
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequencies of a recurrence rule
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+const (
+	// maxRecurrenceCount caps COUNT, a series with a count is walked from its start
+	maxRecurrenceCount = 1000
+
+	// maxRecurrencePeriods bounds the search for an occurrence, so rules that never match,
+	// such as every 30th of February, come to an end
+	maxRecurrencePeriods = 5000
+)
+
+var (
+	ErrInvalidRecurrence  = errors.New("invalid recurrence rule")
+	ErrRecurrenceNeedsDue = errors.New("a recurring todo needs a due date")
+	ErrNotRecurring       = errors.New("todo does not recur")
+	ErrNotAnOccurrence    = errors.New("date is not an occurrence of the series")
+	ErrSeriesEnded        = errors.New("series has no later occurrence")
+)
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// recurrencePresets are the shorthands accepted next to full rules,
+// monthly-on-day-N is handled by ParseRecurrence
+var recurrencePresets = map[string]string{
+	"daily":    "FREQ=DAILY",
+	"weekdays": "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+	"weekly":   "FREQ=WEEKLY",
+	"monthly":  "FREQ=MONTHLY",
+	"yearly":   "FREQ=YEARLY",
+}
+
+// RecurrenceDay is a BYDAY entry, N picks the Nth (or with a negative N the Nth last) such day of the month
+// and is 0 for every such day
+type RecurrenceDay struct {
+	N   int
+	Day time.Weekday
+}
+
+// Recurrence is an RFC 5545 recurrence rule
+// The supported parts are FREQ, INTERVAL, COUNT, UNTIL, BYMONTH, BYMONTHDAY, BYDAY and WKST
+type Recurrence struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	UntilDate  bool
+	ByMonth    []time.Month
+	ByMonthDay []int
+	ByDay      []RecurrenceDay
+	WeekStart  time.Weekday
+}
+
+// ParseRecurrence parses an RRULE, with or without the "RRULE:" prefix, or one of the presets
+// daily, weekdays, weekly, monthly, monthly-on-day-N and yearly
+func ParseRecurrence(value string) (Recurrence, error) {
+	value = strings.TrimSpace(value)
+	if preset, ok := recurrencePresets[strings.ToLower(value)]; ok {
+		value = preset
+	} else if day, ok := strings.CutPrefix(strings.ToLower(value), "monthly-on-day-"); ok {
+		if n, err := strconv.Atoi(day); err != nil || n < 1 || n > 31 {
+			return Recurrence{}, ErrInvalidRecurrence
+		}
+		value = "FREQ=MONTHLY;BYMONTHDAY=" + day
+	}
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+
+	rule := Recurrence{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(strings.ToUpper(value), ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" || seen[key] {
+			return Recurrence{}, ErrInvalidRecurrence
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			if !slices.Contains([]string{FreqDaily, FreqWeekly, FreqMonthly, FreqYearly}, val) {
+				return Recurrence{}, ErrInvalidRecurrence
+			}
+			rule.Freq = val
+		case "INTERVAL":
+			rule.Interval, err = parseRuleInt(val, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseRuleInt(val, 1, maxRecurrenceCount)
+		case "UNTIL":
+			rule.Until, rule.UntilDate, err = parseRuleUntil(val)
+		case "BYMONTH":
+			for _, month := range strings.Split(val, ",") {
+				var n int
+				if n, err = parseRuleInt(month, 1, 12); err != nil {
+					break
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(n))
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(val, ",") {
+				var n int
+				if n, err = parseRuleInt(day, -31, 31); err != nil || n == 0 {
+					return Recurrence{}, ErrInvalidRecurrence
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				var recurrenceDay RecurrenceDay
+				if recurrenceDay, err = parseRuleDay(day); err != nil {
+					break
+				}
+				rule.ByDay = append(rule.ByDay, recurrenceDay)
+			}
+		case "WKST":
+			day := slices.Index(weekdayCodes, val)
+			if day < 0 {
+				return Recurrence{}, ErrInvalidRecurrence
+			}
+			rule.WeekStart = time.Weekday(day)
+		default:
+			return Recurrence{}, ErrInvalidRecurrence
+		}
+		if err != nil {
+			return Recurrence{}, ErrInvalidRecurrence
+		}
+	}
+
+	if rule.Freq == "" || (rule.Count > 0 && !rule.Until.IsZero()) {
+		return Recurrence{}, ErrInvalidRecurrence
+	}
+	// Weekly rules pick days of the week, so days of the month make no sense there,
+	// and the Nth weekday only exists within a month
+	if rule.Freq == FreqWeekly && len(rule.ByMonthDay) > 0 {
+		return Recurrence{}, ErrInvalidRecurrence
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && (rule.Freq == FreqDaily || rule.Freq == FreqWeekly) {
+			return Recurrence{}, ErrInvalidRecurrence
+		}
+	}
+	return rule, nil
+}
+
+func parseRuleInt(value string, min int, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, ErrInvalidRecurrence
+	}
+	return n, nil
+}
+
+// parseRuleUntil parses an UNTIL value, either a UTC date-time or a date that includes the whole day
+func parseRuleUntil(value string) (time.Time, bool, error) {
+	if until, err := time.Parse("20060102T150405Z", value); err == nil {
+		return until, false, nil
+	}
+	until, err := time.Parse("20060102", value)
+	return until, true, err
+}
+
+// parseRuleDay parses a BYDAY entry such as MO, 2TU or -1FR
+func parseRuleDay(value string) (RecurrenceDay, error) {
+	if len(value) < 2 {
+		return RecurrenceDay{}, ErrInvalidRecurrence
+	}
+	code, ordinal := value[len(value)-2:], value[:len(value)-2]
+	day := slices.Index(weekdayCodes, code)
+	if day < 0 {
+		return RecurrenceDay{}, ErrInvalidRecurrence
+	}
+
+	recurrenceDay := RecurrenceDay{Day: time.Weekday(day)}
+	if ordinal != "" {
+		n, err := strconv.Atoi(ordinal)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return RecurrenceDay{}, ErrInvalidRecurrence
+		}
+		recurrenceDay.N = n
+	}
+	return recurrenceDay, nil
+}
+
+// String formats the rule as a normalized RRULE without the "RRULE:" prefix
+func (rule Recurrence) String() string {
+	parts := []string{"FREQ=" + rule.Freq}
+	if rule.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rule.Interval))
+	}
+	if rule.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(rule.Count))
+	}
+	if rule.UntilDate {
+		parts = append(parts, "UNTIL="+rule.Until.Format("20060102"))
+	} else if !rule.Until.IsZero() {
+		parts = append(parts, "UNTIL="+rule.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(rule.ByMonth) > 0 {
+		months := make([]string, 0, len(rule.ByMonth))
+		for _, month := range rule.ByMonth {
+			months = append(months, strconv.Itoa(int(month)))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(rule.ByMonthDay) > 0 {
+		days := make([]string, 0, len(rule.ByMonthDay))
+		for _, day := range rule.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(rule.ByDay) > 0 {
+		days := make([]string, 0, len(rule.ByDay))
+		for _, day := range rule.ByDay {
+			code := weekdayCodes[day.Day]
+			if day.N != 0 {
+				code = strconv.Itoa(day.N) + code
+			}
+			days = append(days, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if rule.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCodes[rule.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence after the given time of the series that starts at start,
+// leaving out the skipped dates (see OccurrenceDate)
+// Occurrences keep the wall clock time of start in loc, so they stay at the same local time across DST changes
+func (rule Recurrence) Next(start time.Time, after time.Time, loc *time.Location, skipped []string) (time.Time, bool) {
+	var next time.Time
+	found := false
+	rule.occurrences(start, after, loc, func(occurrence time.Time) bool {
+		if !occurrence.After(after) || slices.Contains(skipped, OccurrenceDate(occurrence, loc)) {
+			return true
+		}
+		next, found = occurrence, true
+		return false
+	})
+	return next, found
+}
+
+// Includes reports whether the series that starts at start has an occurrence on the date in loc
+func (rule Recurrence) Includes(start time.Time, date time.Time, loc *time.Location) bool {
+	key := date.Format(time.DateOnly)
+	found := false
+	rule.occurrences(start, date.AddDate(0, 0, -1), loc, func(occurrence time.Time) bool {
+		day := OccurrenceDate(occurrence, loc)
+		found = day == key
+		return day < key
+	})
+	return found
+}
+
+// OccurrenceDate returns the date of an occurrence in loc, the form skipped dates are kept in
+func OccurrenceDate(occurrence time.Time, loc *time.Location) string {
+	return occurrence.In(loc).Format(time.DateOnly)
+}
+
+// occurrences calls yield with the occurrences of the series in order until it returns false or the series ends
+// from is a hint that occurrences before it are not needed, the series is still counted from its start
+func (rule Recurrence) occurrences(start time.Time, from time.Time, loc *time.Location, yield func(time.Time) bool) {
+	local := start.In(loc)
+	startDate := calendarDate(start, loc)
+	hour, minute, second := local.Clock()
+
+	emitted := 0
+	emit := func(occurrence time.Time) bool {
+		if rule.Count > 0 && emitted >= rule.Count {
+			return false
+		}
+		if rule.UntilDate && calendarDate(occurrence, loc).After(rule.Until) {
+			return false
+		}
+		if !rule.UntilDate && !rule.Until.IsZero() && occurrence.After(rule.Until) {
+			return false
+		}
+		emitted++
+		return yield(occurrence)
+	}
+
+	// The start is always the first occurrence, even when it doesn't match the rule
+	if !emit(start) {
+		return
+	}
+
+	// Without a count nothing before from matters, so whole periods are skipped up to just before it
+	period := 0
+	if rule.Count == 0 && from.After(start) {
+		period = max(rule.periodsBetween(startDate, calendarDate(from, loc))/rule.Interval-1, 0)
+	}
+	for end := period + maxRecurrencePeriods; period < end; period++ {
+		for _, date := range rule.periodDates(startDate, period*rule.Interval) {
+			if !date.After(startDate) {
+				continue
+			}
+			if !emit(wallClock(date, hour, minute, second, loc)) {
+				return
+			}
+		}
+	}
+}
+
+// periodsBetween returns how many periods of the rule's frequency lie between two dates
+func (rule Recurrence) periodsBetween(from time.Time, to time.Time) int {
+	switch rule.Freq {
+	case FreqDaily:
+		return int(to.Sub(from).Hours() / 24)
+	case FreqWeekly:
+		return int(to.Sub(rule.weekOf(from)).Hours() / 24 / 7)
+	case FreqMonthly:
+		return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	default:
+		return to.Year() - from.Year()
+	}
+}
+
+// weekOf returns the first day of the week of the date, weeks begin on the rule's WKST
+func (rule Recurrence) weekOf(date time.Time) time.Time {
+	return date.AddDate(0, 0, -((int(date.Weekday()) - int(rule.WeekStart) + 7) % 7))
+}
+
+// periodDates returns the dates of the period that lies the given number of periods after the start, in order
+// Dates are at midnight UTC like calendarDate
+func (rule Recurrence) periodDates(startDate time.Time, period int) []time.Time {
+	var dates []time.Time
+	switch rule.Freq {
+	case FreqDaily:
+		date := startDate.AddDate(0, 0, period)
+		if rule.matchesMonth(date) && rule.matchesWeekday(date) && rule.matchesMonthDay(date) {
+			dates = append(dates, date)
+		}
+	case FreqWeekly:
+		week := rule.weekOf(startDate).AddDate(0, 0, 7*period)
+		for i := 0; i < 7; i++ {
+			date := week.AddDate(0, 0, i)
+			if len(rule.ByDay) == 0 && date.Weekday() != startDate.Weekday() {
+				continue
+			}
+			if rule.matchesMonth(date) && rule.matchesWeekday(date) {
+				dates = append(dates, date)
+			}
+		}
+	case FreqMonthly:
+		month := time.Date(startDate.Year(), startDate.Month()+time.Month(period), 1, 0, 0, 0, 0, time.UTC)
+		if rule.matchesMonth(month) {
+			dates = rule.monthDates(month, startDate.Day())
+		}
+	case FreqYearly:
+		months := rule.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{startDate.Month()}
+		}
+		months = slices.Sorted(slices.Values(months))
+		for _, month := range slices.Compact(months) {
+			first := time.Date(startDate.Year()+period, month, 1, 0, 0, 0, 0, time.UTC)
+			dates = append(dates, rule.monthDates(first, startDate.Day())...)
+		}
+	}
+	return dates
+}
+
+// monthDates returns the dates of the month picked by BYMONTHDAY and BYDAY, or the given day of the month without them
+// Days that the month doesn't have, such as the 31st of April, are left out
+func (rule Recurrence) monthDates(first time.Time, day int) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+
+	var days []int
+	switch {
+	case len(rule.ByMonthDay) == 0 && len(rule.ByDay) == 0:
+		days = []int{day}
+	case len(rule.ByDay) == 0:
+		days = rule.monthDays(last)
+	case len(rule.ByMonthDay) == 0:
+		days = rule.weekdaysOfMonth(first, last)
+	default:
+		monthDays := rule.monthDays(last)
+		for _, day := range rule.weekdaysOfMonth(first, last) {
+			if slices.Contains(monthDays, day) {
+				days = append(days, day)
+			}
+		}
+	}
+
+	slices.Sort(days)
+	dates := make([]time.Time, 0, len(days))
+	for _, day := range slices.Compact(days) {
+		if day >= 1 && day <= last {
+			dates = append(dates, first.AddDate(0, 0, day-1))
+		}
+	}
+	return dates
+}
+
+// monthDays resolves BYMONTHDAY in a month with the given number of days, negative days count from its end
+func (rule Recurrence) monthDays(last int) []int {
+	days := make([]int, 0, len(rule.ByMonthDay))
+	for _, day := range rule.ByMonthDay {
+		if day < 0 {
+			day = last + day + 1
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// weekdaysOfMonth resolves BYDAY in the month starting at first
+func (rule Recurrence) weekdaysOfMonth(first time.Time, last int) []int {
+	var days []int
+	for _, recurrenceDay := range rule.ByDay {
+		offset := (int(recurrenceDay.Day) - int(first.Weekday()) + 7) % 7
+		var matches []int
+		for day := 1 + offset; day <= last; day += 7 {
+			matches = append(matches, day)
+		}
+
+		switch {
+		case recurrenceDay.N == 0:
+			days = append(days, matches...)
+		case recurrenceDay.N > 0 && recurrenceDay.N <= len(matches):
+			days = append(days, matches[recurrenceDay.N-1])
+		case recurrenceDay.N < 0 && -recurrenceDay.N <= len(matches):
+			days = append(days, matches[len(matches)+recurrenceDay.N])
+		}
+	}
+	return days
+}
+
+func (rule Recurrence) matchesMonth(date time.Time) bool {
+	return len(rule.ByMonth) == 0 || slices.Contains(rule.ByMonth, date.Month())
+}
+
+func (rule Recurrence) matchesWeekday(date time.Time) bool {
+	if len(rule.ByDay) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(rule.ByDay, func(day RecurrenceDay) bool {
+		return day.Day == date.Weekday()
+	})
+}
+
+func (rule Recurrence) matchesMonthDay(date time.Time) bool {
+	if len(rule.ByMonthDay) == 0 {
+		return true
+	}
+	return slices.Contains(rule.monthDays(date.AddDate(0, 1, -date.Day()).Day()), date.Day())
+}
+
+// wallClock returns the instant the date has the given wall clock time in loc, resolved like RFC 5545 does:
+// a time that a DST change skips is taken with the offset from before the change, which moves it forward by the gap,
+// and a time that happens twice is its first occurrence
+func wallClock(date time.Time, hour int, minute int, second int, loc *time.Location) time.Time {
+	naive := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, second, 0, time.UTC)
+
+	// Zones change their offset at most once around a day
+	_, before := naive.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := naive.Add(24 * time.Hour).In(loc).Zone()
+
+	var first time.Time
+	for _, offset := range []int{before, after} {
+		candidate := naive.Add(-time.Duration(offset) * time.Second)
+		local := candidate.In(loc)
+		if local.Hour() != hour || local.Minute() != minute || local.Day() != date.Day() {
+			continue
+		}
+		if first.IsZero() || candidate.Before(first) {
+			first = candidate
+		}
+	}
+	if first.IsZero() {
+		first = naive.Add(-time.Duration(before) * time.Second)
+	}
+	return first.In(loc)
+}
@@ -0,0 +1,121 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      Skip occurrence
+// @Description  Leave an occurrence out of the series of a recurring todo of the authenticated user, by default its own occurrence which moves the todo on to the next one
+// @Tags         Todo
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                 true  "Todo ID"
+// @Param        body  body      SkipOccurrenceRequest  true  "Skip Occurrence Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.SkipOccurrenceResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/skip [post]
+func (handler TodoHandler) SkipOccurrence(ctx *gin.Context) {
+	var req SkipOccurrenceRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Skip occurrence"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Skip occurrence"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.SkipOccurrence(ctx, todoID, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Skip occurrence request failed",
+			logger.F("operation", "Skip occurrence"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Complete series
+// @Description  Complete a recurring todo of the authenticated user without creating its next instance, which ends the series
+// @Tags         Todo
+// @Produce      json
+// @Param        id   path      string  true  "Todo ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.CompleteSeriesResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/complete-series [post]
+func (handler TodoHandler) CompleteSeries(ctx *gin.Context) {
+	// Complete series ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Complete series"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Complete series"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.CompleteSeries(ctx, todoID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Complete series request failed",
+			logger.F("operation", "Complete series"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
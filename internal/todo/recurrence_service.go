@@ -0,0 +1,272 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// applyRecurrence updates the rule and time zone of a todo, nil values are left as they are and an empty rule stops the todo from recurring
+// Setting a different rule starts the series over at the todo's due date
+func applyRecurrence(todo *Todo, recurrence *string, timezone *string) error {
+	if timezone != nil {
+		todo.Timezone = *timezone
+	}
+	if recurrence != nil && *recurrence == "" {
+		todo.Recurrence = ""
+	} else if recurrence != nil {
+		rule, err := ParseRecurrence(*recurrence)
+		if err != nil {
+			return err
+		}
+		if todo.DueAt == nil {
+			return ErrRecurrenceNeedsDue
+		}
+		if rule.String() != todo.Recurrence || todo.SeriesStart == nil {
+			start := *todo.DueAt
+			todo.Recurrence = rule.String()
+			todo.SeriesStart = &start
+			todo.SkippedDates = nil
+		}
+		if todo.SeriesID == nil {
+			seriesID := uuid.New()
+			todo.SeriesID = &seriesID
+		}
+	}
+
+	if todo.Recurrence != "" && todo.DueAt == nil {
+		return ErrRecurrenceNeedsDue
+	}
+	return nil
+}
+
+// recurrenceErrorResponse returns the response for an error of applyRecurrence
+func (service TodoService) recurrenceErrorResponse(err error) models.Response {
+	if errors.Is(err, ErrRecurrenceNeedsDue) {
+		return utils.UnprocessableEntityResponse("A recurring todo needs a due date", err, service.isDebug)
+	}
+	return utils.UnprocessableEntityResponse("Invalid recurrence rule", err, service.isDebug)
+}
+
+// recurrenceOf parses the rule of a recurring todo and returns it with the zone its occurrences are expanded in
+// All-day todos are due on a date, so their occurrences are expanded in UTC like their due dates are kept
+func recurrenceOf(todo *Todo) (Recurrence, *time.Location, time.Time, error) {
+	rule, err := ParseRecurrence(todo.Recurrence)
+	if err != nil || todo.DueAt == nil {
+		return rule, nil, time.Time{}, ErrNotRecurring
+	}
+
+	start := *todo.DueAt
+	if todo.SeriesStart != nil {
+		start = *todo.SeriesStart
+	}
+	if todo.AllDay {
+		return rule, time.UTC, start, nil
+	}
+	return rule, LoadLocation(todo.Timezone), start, nil
+}
+
+// nextInstance builds the instance that follows a completed occurrence of a recurring todo, due at the first occurrence
+// after both the todo's due date and now, so a late completion doesn't create instances that are overdue already
+// It returns nil when the series has ended
+func nextInstance(todo *Todo, status WorkflowStatus, now time.Time) *Todo {
+	rule, loc, start, err := recurrenceOf(todo)
+	if err != nil {
+		return nil
+	}
+	after := *todo.DueAt
+	if !todo.AllDay && now.After(after) {
+		after = now
+	} else if todo.AllDay && calendarDate(now, loc).After(after) {
+		after = calendarDate(now, loc)
+	}
+	due, ok := rule.Next(start, after, loc, todo.SkippedDates)
+	if !ok {
+		return nil
+	}
+	due = due.UTC()
+
+	next := &Todo{
+		Title:        todo.Title,
+		Description:  todo.Description,
+		Completed:    status.Done,
+		UserID:       todo.UserID,
+		ProjectID:    todo.ProjectID,
+		ParentID:     todo.ParentID,
+		AutoComplete: todo.AutoComplete,
+		DueAt:        &due,
+		AllDay:       todo.AllDay,
+		Priority:     todo.Priority,
+		Status:       status.Key,
+		Recurrence:   todo.Recurrence,
+		Timezone:     todo.Timezone,
+		SeriesID:     todo.SeriesID,
+		SeriesStart:  todo.SeriesStart,
+		SkippedDates: slices.Clone(todo.SkippedDates),
+		Tags:         slices.Clone(todo.Tags),
+	}
+	// The reminder keeps its distance to the due date
+	if todo.RemindAt != nil {
+		remindAt := due.Add(todo.RemindAt.Sub(*todo.DueAt))
+		next.RemindAt = &remindAt
+	}
+	return next
+}
+
+// SkipOccurrence leaves an occurrence out of the series of a recurring todo
+// Skipping the todo's own occurrence, which is the default, moves it on to the next one
+func (service TodoService) SkipOccurrence(ctx context.Context, todoID uuid.UUID, req SkipOccurrenceRequest, userID uuid.UUID) models.Response {
+	todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find todo",
+			logger.F("operation", "Skip occurrence"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found", err, service.isDebug)
+	}
+
+	rule, loc, start, err := recurrenceOf(todo)
+	if err != nil {
+		return utils.UnprocessableEntityResponse("Todo does not recur", err, service.isDebug)
+	}
+	before := *todo
+	before.SkippedDates = slices.Clone(todo.SkippedDates)
+
+	current := OccurrenceDate(*todo.DueAt, loc)
+	date := req.Date
+	if date == "" {
+		date = current
+	}
+	if date == current {
+		due, ok := rule.Next(start, *todo.DueAt, loc, todo.SkippedDates)
+		if !ok {
+			return utils.UnprocessableEntityResponse("The series has no later occurrence", ErrSeriesEnded, service.isDebug)
+		}
+		due = due.UTC()
+		if todo.RemindAt != nil {
+			remindAt := due.Add(todo.RemindAt.Sub(*todo.DueAt))
+			todo.RemindAt = &remindAt
+		}
+		todo.DueAt = &due
+	} else {
+		// Only later occurrences can be skipped, earlier ones are already behind the todo
+		day, _ := time.Parse(time.DateOnly, date)
+		if date < current || !rule.Includes(start, day, loc) {
+			return utils.UnprocessableEntityResponse("Not an occurrence of the series", ErrNotAnOccurrence, service.isDebug)
+		}
+	}
+	if !slices.Contains(todo.SkippedDates, date) {
+		todo.SkippedDates = append(todo.SkippedDates, date)
+		slices.Sort(todo.SkippedDates)
+	}
+
+	// Skipping a date that is already skipped changes nothing
+	columns := changedColumns(before, *todo)
+	err = service.inTransaction(ctx, func(service TodoService) error {
+		if len(columns) == 0 {
+			return nil
+		}
+		if err := service.todoRepository.UpdateTodo(ctx, todo, columns...); err != nil {
+			return err
+		}
+		return service.recordHistory(ctx, HistoryUpdate, before, *todo, userID)
+	})
+	if err != nil {
+		service.log.Error("Failed to skip occurrence",
+			logger.F("operation", "Skip occurrence"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to skip occurrence", err, service.isDebug)
+	}
+
+	responseData := SkipOccurrenceResponse{
+		Todo: NewTodoResponse(*todo),
+	}
+	return utils.OkResponse("Occurrence skipped successfully", responseData)
+}
+
+// CompleteSeries completes a recurring todo without creating a next instance, which ends its series
+func (service TodoService) CompleteSeries(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) models.Response {
+	todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find todo",
+			logger.F("operation", "Complete series"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found", err, service.isDebug)
+	}
+	if todo.Recurrence == "" {
+		return utils.UnprocessableEntityResponse("Todo does not recur", ErrNotRecurring, service.isDebug)
+	}
+
+	workflow, err := service.workflow(ctx, userID)
+	if err != nil {
+		service.log.Error("Failed to get workflow",
+			logger.F("operation", "Complete series"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to complete series", err, service.isDebug)
+	}
+
+	before := *todo
+	var transition *TodoTransition
+	todo.Recurrence = ""
+	if !todo.Completed {
+		done := workflow.DoneStatus()
+		if !workflow.CanTransition(todo.Status, done.Key) {
+			return utils.UnprocessableEntityResponse("Status transition not allowed", ErrInvalidTransition, service.isDebug)
+		}
+		transition = &TodoTransition{
+			TodoID:     todo.ID,
+			UserID:     userID,
+			FromStatus: todo.Status,
+			ToStatus:   done.Key,
+		}
+		todo.Status = done.Key
+		todo.Completed = true
+	}
+	columns := changedColumns(before, *todo)
+	err = service.inTransaction(ctx, func(service TodoService) error {
+		var err error
+		if transition != nil {
+			err = service.todoRepository.TransitionTodo(ctx, todo, transition, columns...)
+		} else {
+			err = service.todoRepository.UpdateTodo(ctx, todo, columns...)
+		}
+		if err != nil {
+			return err
+		}
+		if err := service.recordHistory(ctx, HistoryUpdate, before, *todo, userID); err != nil {
+			return err
+		}
+		service.rollUp(ctx, todo, userID, "Complete series")
+		return nil
+	})
+	if err != nil {
+		service.log.Error("Failed to complete series",
+			logger.F("operation", "Complete series"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to complete series", err, service.isDebug)
+	}
+
+	responseData := CompleteSeriesResponse{
+		Todo: NewTodoResponse(*todo),
+	}
+	return utils.OkResponse("Series completed successfully", responseData)
+}
@@ -0,0 +1,400 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", name, err)
+	}
+	return loc
+}
+
+// nextOccurrences follows the series from its start and returns up to n later occurrences in loc
+func nextOccurrences(rule Recurrence, start time.Time, loc *time.Location, skipped []string, n int) []string {
+	occurrences := []string{}
+	for after := start; len(occurrences) < n; {
+		next, ok := rule.Next(start, after, loc, skipped)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, next.In(loc).Format(time.RFC3339))
+		after = next
+	}
+	return occurrences
+}
+
+func TestParseRecurrence(t *testing.T) {
+	valid := map[string]string{
+		"daily":                         "FREQ=DAILY",
+		"Weekdays":                      "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		"monthly-on-day-15":             "FREQ=MONTHLY;BYMONTHDAY=15",
+		"RRULE:freq=monthly;byday=-1fr": "FREQ=MONTHLY;BYDAY=-1FR",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;WKST=SU":         "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;WKST=SU",
+		"FREQ=WEEKLY;INTERVAL=1;WKST=MO":                     "FREQ=WEEKLY",
+		"FREQ=DAILY;UNTIL=20250301T120000Z":                  "FREQ=DAILY;UNTIL=20250301T120000Z",
+		"FREQ=DAILY;COUNT=3":                                 "FREQ=DAILY;COUNT=3",
+		"FREQ=YEARLY;BYMONTHDAY=29;BYMONTH=2":                "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+		"FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20251231":          "FREQ=MONTHLY;UNTIL=20251231;BYMONTHDAY=-1",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYMONTHDAY=1,2,3": "FREQ=MONTHLY;BYMONTHDAY=1,2,3;BYDAY=MO,TU,WE,TH,FR",
+	}
+	for value, want := range valid {
+		rule, err := ParseRecurrence(value)
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q) error = %v", value, err)
+			continue
+		}
+		if got := rule.String(); got != want {
+			t.Errorf("ParseRecurrence(%q) = %q, want %q", value, got, want)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"hourly",
+		"monthly-on-day-32",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=YEARLY;BYMONTH=13",
+	}
+	for _, value := range invalid {
+		if _, err := ParseRecurrence(value); err != ErrInvalidRecurrence {
+			t.Errorf("ParseRecurrence(%q) error = %v, want ErrInvalidRecurrence", value, err)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+
+	tests := []struct {
+		name    string
+		rule    string
+		start   time.Time
+		loc     *time.Location
+		skipped []string
+		want    []string
+	}{
+		{
+			name:  "keeps the local time across spring forward",
+			rule:  "daily",
+			start: time.Date(2025, 3, 8, 9, 0, 0, 0, newYork),
+			loc:   newYork,
+			want:  []string{"2025-03-09T09:00:00-04:00", "2025-03-10T09:00:00-04:00", "2025-03-11T09:00:00-04:00"},
+		},
+		{
+			name:  "moves a time skipped by spring forward ahead by the gap",
+			rule:  "daily",
+			start: time.Date(2025, 3, 8, 2, 30, 0, 0, newYork),
+			loc:   newYork,
+			want:  []string{"2025-03-09T03:30:00-04:00", "2025-03-10T02:30:00-04:00", "2025-03-11T02:30:00-04:00"},
+		},
+		{
+			name:  "takes the first of a time repeated by fall back",
+			rule:  "daily",
+			start: time.Date(2025, 11, 1, 1, 30, 0, 0, newYork),
+			loc:   newYork,
+			want:  []string{"2025-11-02T01:30:00-04:00", "2025-11-03T01:30:00-05:00", "2025-11-04T01:30:00-05:00"},
+		},
+		{
+			name:  "handles a gap in a zone east of UTC",
+			rule:  "weekly",
+			start: time.Date(2025, 3, 23, 2, 30, 0, 0, berlin),
+			loc:   berlin,
+			want:  []string{"2025-03-30T03:30:00+02:00", "2025-04-06T02:30:00+02:00", "2025-04-13T02:30:00+02:00"},
+		},
+		{
+			name:  "handles an overlap in a zone east of UTC",
+			rule:  "daily",
+			start: time.Date(2025, 10, 25, 2, 30, 0, 0, berlin),
+			loc:   berlin,
+			want:  []string{"2025-10-26T02:30:00+02:00", "2025-10-27T02:30:00+01:00", "2025-10-28T02:30:00+01:00"},
+		},
+		{
+			name:  "skips the weekend",
+			rule:  "weekdays",
+			start: time.Date(2025, 3, 7, 9, 0, 0, 0, time.UTC),
+			loc:   time.UTC,
+			want:  []string{"2025-03-10T09:00:00Z", "2025-03-11T09:00:00Z", "2025-03-12T09:00:00Z"},
+		},
+		{
+			name:  "leaves out months without the day",
+			rule:  "monthly",
+			start: time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC),
+			loc:   time.UTC,
+			want:  []string{"2025-03-31T10:00:00Z", "2025-05-31T10:00:00Z", "2025-07-31T10:00:00Z"},
+		},
+		{
+			name:  "picks the last weekday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC),
+			loc:   time.UTC,
+			want:  []string{"2025-02-28T10:00:00Z", "2025-03-28T10:00:00Z", "2025-04-25T10:00:00Z"},
+		},
+		{
+			name:  "waits for leap years",
+			rule:  "yearly",
+			start: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			loc:   time.UTC,
+			want:  []string{"2028-02-29T00:00:00Z", "2032-02-29T00:00:00Z", "2036-02-29T00:00:00Z"},
+		},
+		{
+			name:  "stops after the count including the start",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC),
+			loc:   time.UTC,
+			want:  []string{"2025-03-02T08:00:00Z", "2025-03-03T08:00:00Z"},
+		},
+		{
+			name:  "includes the whole day of a date until",
+			rule:  "FREQ=WEEKLY;UNTIL=20250315",
+			start: time.Date(2025, 3, 1, 22, 0, 0, 0, jakarta),
+			loc:   jakarta,
+			want:  []string{"2025-03-08T22:00:00+07:00", "2025-03-15T22:00:00+07:00"},
+		},
+		{
+			name:  "counts intervals from the week of the start",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			start: time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC),
+			loc:   time.UTC,
+			want:  []string{"2025-03-17T09:00:00Z", "2025-03-19T09:00:00Z", "2025-03-31T09:00:00Z"},
+		},
+		{
+			name:  "starts weeks on the week start",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,TU;WKST=SU",
+			start: time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC),
+			loc:   time.UTC,
+			want:  []string{"2025-03-16T09:00:00Z", "2025-03-18T09:00:00Z", "2025-03-30T09:00:00Z"},
+		},
+		{
+			name:    "leaves out skipped dates",
+			rule:    "daily",
+			start:   time.Date(2025, 3, 1, 23, 0, 0, 0, jakarta),
+			loc:     jakarta,
+			skipped: []string{"2025-03-02"},
+			want:    []string{"2025-03-03T23:00:00+07:00", "2025-03-04T23:00:00+07:00", "2025-03-05T23:00:00+07:00"},
+		},
+		{
+			name:  "ends when nothing matches",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			start: time.Date(2025, 1, 30, 9, 0, 0, 0, time.UTC),
+			loc:   time.UTC,
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q) error = %v", tt.rule, err)
+			}
+			if got := nextOccurrences(rule, tt.start, tt.loc, tt.skipped, 3); !slices.Equal(got, tt.want) {
+				t.Errorf("occurrences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNextFarAfterStart(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, newYork)
+
+	rule, _ := ParseRecurrence("FREQ=WEEKLY;INTERVAL=3;BYDAY=TU")
+	next, ok := rule.Next(start, time.Date(2026, 6, 15, 12, 0, 0, 0, newYork), newYork, nil)
+	// The series runs on the Tuesdays of every third week from the week of 2024-12-30
+	if want := time.Date(2026, 6, 30, 9, 0, 0, 0, newYork); !ok || !next.Equal(want) {
+		t.Errorf("Next = %v, %v, want %v", next, ok, want)
+	}
+
+	if !rule.Includes(start, time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC), newYork) {
+		t.Errorf("Includes(2026-06-30) = false, want true")
+	}
+	if rule.Includes(start, time.Date(2026, 6, 23, 0, 0, 0, 0, time.UTC), newYork) {
+		t.Errorf("Includes(2026-06-23) = true, want false")
+	}
+}
+
+// completeTodo completes a todo through an update that keeps its other fields
+func completeTodo(t *testing.T, service TodoService, userID uuid.UUID, todo TodoResponse) UpdateTodoResponse {
+	t.Helper()
+
+	req := UpdateTodoRequest{Title: todo.Title, Completed: true, AllDay: todo.AllDay}
+	if todo.DueAt != nil {
+		dueAt, _ := time.Parse(time.RFC3339, *todo.DueAt)
+		req.DueAt = &dueAt
+	}
+	if todo.RemindAt != nil {
+		remindAt, _ := time.Parse(time.RFC3339, *todo.RemindAt)
+		req.RemindAt = &remindAt
+	}
+	response := service.Update(context.Background(), todo.Id, req, userID)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("complete status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+	}
+	return response.Data.(UpdateTodoResponse)
+}
+
+func TestTodoServiceRecurringTodos(t *testing.T) {
+	userID := uuid.New()
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+			service.now = func() time.Time { return time.Date(2025, 3, 3, 8, 0, 0, 0, newYork) }
+
+			dueAt := time.Date(2025, 3, 3, 17, 0, 0, 0, newYork)
+			report := createTodo(t, service, userID, CreateTodoRequest{
+				Title:      "Weekly report",
+				DueAt:      &dueAt,
+				RemindAt:   timePtr(dueAt.Add(-time.Hour)),
+				Recurrence: "RRULE:FREQ=WEEKLY;BYDAY=MO",
+				Timezone:   "America/New_York",
+			})
+			if report.Recurrence != "FREQ=WEEKLY;BYDAY=MO" || report.SeriesID == nil {
+				t.Fatalf("created = %+v, want a weekly series", report)
+			}
+
+			for _, req := range []CreateTodoRequest{
+				{Title: "No due date", Recurrence: "daily"},
+				{Title: "Hourly", DueAt: &dueAt, Recurrence: "FREQ=HOURLY"},
+			} {
+				if response := service.Create(ctx, req, userID); response.StatusCode != http.StatusUnprocessableEntity {
+					t.Errorf("create %q status = %d, want %d", req.Title, response.StatusCode, http.StatusUnprocessableEntity)
+				}
+			}
+
+			// The next instance keeps 17:00 in New York across the start of DST and the reminder an hour before
+			completed := completeTodo(t, service, userID, report)
+			if completed.Todo.Recurrence != "" || !completed.Todo.Completed {
+				t.Errorf("completed = %+v, want a completed todo that no longer recurs", completed.Todo)
+			}
+			next := completed.Next
+			if next == nil {
+				t.Fatalf("completing an occurrence created no next instance")
+			}
+			if *next.DueAt != "2025-03-10T21:00:00Z" || *next.RemindAt != "2025-03-10T20:00:00Z" {
+				t.Errorf("next due = %s, remind = %s, want 2025-03-10T21:00:00Z and an hour before", *next.DueAt, *next.RemindAt)
+			}
+			if next.Completed || next.Recurrence != report.Recurrence || *next.SeriesID != *report.SeriesID {
+				t.Errorf("next = %+v, want an open instance of the same series", next)
+			}
+
+			// Completing the same occurrence again doesn't start another instance
+			service.Update(ctx, report.Id, UpdateTodoRequest{Title: report.Title, DueAt: &dueAt}, userID)
+			if again := completeTodo(t, service, userID, report); again.Next != nil {
+				t.Errorf("completing a reopened occurrence created %+v", again.Next)
+			}
+
+			// A late completion moves on to the first occurrence that is not behind already
+			service.now = func() time.Time { return time.Date(2025, 3, 26, 8, 0, 0, 0, newYork) }
+			late := completeTodo(t, service, userID, *next)
+			if late.Next == nil || *late.Next.DueAt != "2025-03-31T21:00:00Z" {
+				t.Errorf("next after a late completion = %+v, want it due 2025-03-31T21:00:00Z", late.Next)
+			}
+
+			todos := service.GetAll(ctx, userID, GetTodosRequest{Completed: boolPtr(false)}).Data.(GetTodosResponse).Todos
+			if len(todos) != 1 || todos[0].Id != late.Next.Id {
+				t.Errorf("open todos = %v, want only the latest instance", titlesOf(todos))
+			}
+		})
+	}
+}
+
+func TestTodoServiceSkipAndCompleteSeries(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+			service.now = func() time.Time { return time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC) }
+
+			dueAt := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+			standup := createTodo(t, service, userID, CreateTodoRequest{Title: "Standup notes", DueAt: &dueAt, AllDay: true, Recurrence: "weekdays"})
+
+			// Skipping its own occurrence moves the todo on, later ones are only left out
+			response := service.SkipOccurrence(ctx, standup.Id, SkipOccurrenceRequest{}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("skip status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+			}
+			skipped := response.Data.(SkipOccurrenceResponse).Todo
+			if *skipped.DueAt != "2025-03-11T00:00:00Z" {
+				t.Errorf("due after skip = %s, want 2025-03-11", *skipped.DueAt)
+			}
+			if response := service.SkipOccurrence(ctx, standup.Id, SkipOccurrenceRequest{Date: "2025-03-12"}, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("skip a later occurrence status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			for _, date := range []string{"2025-03-10", "2025-03-15"} {
+				if response := service.SkipOccurrence(ctx, standup.Id, SkipOccurrenceRequest{Date: date}, userID); response.StatusCode != http.StatusUnprocessableEntity {
+					t.Errorf("skip %s status = %d, want %d", date, response.StatusCode, http.StatusUnprocessableEntity)
+				}
+			}
+
+			completed := completeTodo(t, service, userID, getTree(t, service, standup.Id, userID).TodoResponse)
+			if completed.Next == nil || *completed.Next.DueAt != "2025-03-13T00:00:00Z" {
+				t.Fatalf("next = %+v, want it due on 2025-03-13 after the skipped 12th", completed.Next)
+			}
+			if !slices.Equal(completed.Next.SkippedDates, []string{"2025-03-10", "2025-03-12"}) {
+				t.Errorf("skipped dates = %v, want [2025-03-10 2025-03-12]", completed.Next.SkippedDates)
+			}
+
+			// Completing the series ends it without another instance
+			response = service.CompleteSeries(ctx, completed.Next.Id, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("complete series status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if ended := response.Data.(CompleteSeriesResponse).Todo; !ended.Completed || ended.Recurrence != "" {
+				t.Errorf("ended = %+v, want a completed todo that no longer recurs", ended)
+			}
+			if todos := service.GetAll(ctx, userID, GetTodosRequest{Completed: boolPtr(false)}).Data.(GetTodosResponse).Todos; len(todos) != 0 {
+				t.Errorf("open todos = %v, want none", titlesOf(todos))
+			}
+			for _, todoID := range []uuid.UUID{completed.Next.Id, standup.Id} {
+				if response := service.CompleteSeries(ctx, todoID, userID); response.StatusCode != http.StatusUnprocessableEntity {
+					t.Errorf("complete series of a todo that no longer recurs status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+				}
+				if response := service.SkipOccurrence(ctx, todoID, SkipOccurrenceRequest{}, userID); response.StatusCode != http.StatusUnprocessableEntity {
+					t.Errorf("skip on a todo that no longer recurs status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+				}
+			}
+
+			// A series with a count ends by itself
+			last := createTodo(t, service, userID, CreateTodoRequest{Title: "Twice", DueAt: &dueAt, AllDay: true, Recurrence: "FREQ=DAILY;COUNT=2"})
+			second := completeTodo(t, service, userID, last).Next
+			if second == nil {
+				t.Fatalf("completing the first of two occurrences created no next instance")
+			}
+			if third := completeTodo(t, service, userID, *second).Next; third != nil {
+				t.Errorf("completing the last occurrence created %+v", third)
+			}
+			if response := service.SkipOccurrence(ctx, last.Id, SkipOccurrenceRequest{}, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("skip as another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+		})
+	}
+}
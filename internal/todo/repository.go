@@ -2,46 +2,1029 @@ package todo
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type TodoRepository struct {
+// TodoRepository is the persistence contract used by TodoService
+// Every lookup is scoped to the owning user
+type TodoRepository interface {
+	// Transaction runs fn with a repository whose changes are committed together once fn returns nil
+	// and rolled back when it returns an error, transactions inside fn only roll back their own changes
+	Transaction(ctx context.Context, fn func(repository TodoRepository) error) error
+	FindAllTodoByUserID(ctx context.Context, userID string, filter TodoFilter) ([]Todo, error)
+	CountTodoByUserID(ctx context.Context, userID string, filter TodoFilter) (int64, error)
+	FindDueTodosByUserID(ctx context.Context, userID string, window DueWindow) ([]Todo, error)
+	SearchTodosByUserID(ctx context.Context, userID string, query SearchQuery, filter TodoFilter, offset int) ([]SearchHit, error)
+	// CreateTodo puts a todo without a position first in the user's manual order
+	CreateTodo(ctx context.Context, todo *Todo) error
+	FindTodoByIDAndUserID(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) (*Todo, error)
+	UpdateTodo(ctx context.Context, todo *Todo, columns ...string) error
+	// FindAdjacentTodoPosition returns the position of the todo right after the given one in its user's manual order,
+	// or right before it when previous is set, leaving out the excluded todo, and an empty position when there is none
+	FindAdjacentTodoPosition(ctx context.Context, todo *Todo, previous bool, exclude uuid.UUID) (string, error)
+	// RebalanceTodoPositions gives the user's todos evenly spread positions in their current order
+	RebalanceTodoPositions(ctx context.Context, userID uuid.UUID) error
+	FindSubtasksByParentIDs(ctx context.Context, userID uuid.UUID, parentIDs []uuid.UUID) ([]Todo, error)
+	// DeleteTodo returns the subtasks it moved to the trash or up to the parent, as they are after the change
+	DeleteTodo(ctx context.Context, todo *Todo, subtasks string) ([]Todo, error)
+	FindTrashedTodoByIDAndUserID(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) (*Todo, error)
+	// RestoreTodo returns the subtasks it took out of the trash along with the todo
+	RestoreTodo(ctx context.Context, todo *Todo) ([]Todo, error)
+	PurgeTodo(ctx context.Context, todo *Todo) error
+	PurgeTodosDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	TransitionTodo(ctx context.Context, todo *Todo, transition *TodoTransition, columns ...string) error
+	CompleteOccurrence(ctx context.Context, todo *Todo, transition *TodoTransition, next *Todo, columns ...string) error
+	FindTodoTransitionsByTodoID(ctx context.Context, todoID uuid.UUID) ([]TodoTransition, error)
+	AppendTodoHistory(ctx context.Context, history *TodoHistory) error
+	FindTodoHistoryByUserID(ctx context.Context, userID uuid.UUID, filter HistoryFilter) ([]TodoHistory, error)
+	// CreateTodoUndo saves the before-images of a change, clearing the user's expired ones
+	CreateTodoUndo(ctx context.Context, undo *TodoUndo) error
+	FindTodoUndoByIDAndUserID(ctx context.Context, undoID uuid.UUID, userID uuid.UUID) (*TodoUndo, error)
+	DeleteTodoUndo(ctx context.Context, undo *TodoUndo) error
+	FindWorkflowByUserID(ctx context.Context, userID uuid.UUID) (Workflow, error)
+	SaveWorkflow(ctx context.Context, userID uuid.UUID, workflow *Workflow) error
+	FindUsedStatusesByUserID(ctx context.Context, userID uuid.UUID) ([]string, error)
+	FindTagsByUserID(ctx context.Context, userID uuid.UUID) ([]Tag, error)
+	FindTagByIDAndUserID(ctx context.Context, tagID uuid.UUID, userID uuid.UUID) (*Tag, error)
+	FindTagsByIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]Tag, error)
+	FindTagsByNames(ctx context.Context, userID uuid.UUID, names []string) ([]Tag, error)
+	CreateTag(ctx context.Context, tag *Tag) error
+	UpdateTag(ctx context.Context, tag *Tag) error
+	DeleteTag(ctx context.Context, tag *Tag) error
+	FindProjectsByUserID(ctx context.Context, userID uuid.UUID, archived bool) ([]Project, error)
+	FindProjectByIDAndUserID(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) (*Project, error)
+	CreateProject(ctx context.Context, project *Project) error
+	UpdateProject(ctx context.Context, project *Project) error
+	UpdateProjectPositions(ctx context.Context, projects []Project) error
+	CountTodosByProject(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]TodoCounts, error)
+	FindChecklistItemsByTodoIDs(ctx context.Context, todoIDs []uuid.UUID) ([]ChecklistItem, error)
+	FindChecklistItemByIDAndTodoID(ctx context.Context, itemID uuid.UUID, todoID uuid.UUID) (*ChecklistItem, error)
+	CreateChecklistItem(ctx context.Context, item *ChecklistItem) error
+	UpdateChecklistItem(ctx context.Context, item *ChecklistItem) error
+	DeleteChecklistItem(ctx context.Context, item *ChecklistItem) error
+	UpdateChecklistItemPositions(ctx context.Context, items []ChecklistItem) error
+}
+
+type todoRepository struct {
 	db *gorm.DB
 }
 
 func NewTodoRepository(db *gorm.DB) TodoRepository {
-	return TodoRepository{
+	return todoRepository{
 		db: db,
 	}
 }
 
-func (repository TodoRepository) FindAllTodoByUserID(ctx context.Context, userID string) ([]Todo, error) {
+func (repository todoRepository) Transaction(ctx context.Context, fn func(repository TodoRepository) error) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(todoRepository{db: tx})
+	})
+}
+
+func (repository todoRepository) FindAllTodoByUserID(ctx context.Context, userID string, filter TodoFilter) ([]Todo, error) {
+	query, err := repository.scopedQuery(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	column := filter.Sort.Column()
+	direction := "ASC"
+	comparator := ">"
+	if filter.Sort.Descending {
+		direction = "DESC"
+		comparator = "<"
+	}
+
+	// Keyset pagination: continue strictly after the (sort value, id) of the cursor
+	if filter.Cursor != nil {
+		value, err := sortArg(filter.Sort.Field, filter.Cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(
+			fmt.Sprintf("(%s %s ?) OR (%s = ? AND id %s ?)", column, comparator, column, comparator),
+			value, value, filter.Cursor.ID,
+		)
+	}
+
 	var todos []Todo
-	err := repository.db.Where("user_id = ?", userID).Find(&todos).Error
-	return todos, err
+	err = query.
+		Order(fmt.Sprintf("%s %s, id %s", column, direction, direction)).
+		Limit(filter.Limit + 1).
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, repository.loadTags(ctx, todos)
 }
 
-func (repository TodoRepository) CreateTodo(ctx context.Context, todo *Todo) error {
-	return repository.db.Create(todo).Error
+func (repository todoRepository) CountTodoByUserID(ctx context.Context, userID string, filter TodoFilter) (int64, error) {
+	query, err := repository.scopedQuery(ctx, userID, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	err = query.Count(&total).Error
+	return total, err
 }
 
-func (repository TodoRepository) FindTodoByID(ctx context.Context, id uint) (*Todo, error) {
-	todo, err := gorm.G[Todo](repository.db).First(ctx)
-	return &todo, err
+// FindDueTodosByUserID returns the user's incomplete todos due within the window, earliest first
+func (repository todoRepository) FindDueTodosByUserID(ctx context.Context, userID string, window DueWindow) ([]Todo, error) {
+	pending := false
+	query, err := repository.scopedQuery(ctx, userID, TodoFilter{
+		Completed: &pending,
+		Due:       &window,
+		Sort:      ParseTodoSort(defaultTodoSort),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []Todo
+	err = query.Order("due_at ASC, id ASC").Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, repository.loadTags(ctx, todos)
+}
+
+// SearchTodosByUserID returns the user's todos that match the query and filter, best match first
+// It skips offset matches and returns up to filter.Limit+1 of them so the caller can tell whether more follow
+// Postgres searches the search column with full text search, other databases fall back to matching substrings with LIKE
+func (repository todoRepository) SearchTodosByUserID(ctx context.Context, userID string, query SearchQuery, filter TodoFilter, offset int) ([]SearchHit, error) {
+	scoped, err := repository.scopedQuery(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	fullText := repository.db.Dialector.Name() == "postgres"
+	if fullText {
+		tsquery := query.TSQuery()
+		titleOptions := fmt.Sprintf("HighlightAll=true, StartSel=%s, StopSel=%s", highlightStart, highlightStop)
+		snippetOptions := fmt.Sprintf(`MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" … ", StartSel=%s, StopSel=%s`, highlightStart, highlightStop)
+		scoped = scoped.
+			Select(`id, ts_rank(search, to_tsquery('english', ?)) AS rank,
+				ts_headline('english', title, to_tsquery('english', ?), ?) AS title,
+				ts_headline('english', description, to_tsquery('english', ?), ?) AS snippet`,
+				tsquery, tsquery, titleOptions, tsquery, snippetOptions).
+			Where("search @@ to_tsquery('english', ?)", tsquery)
+	} else {
+		ranks := make([]string, 0, len(query.Terms))
+		var args []any
+		for _, term := range query.Terms {
+			pattern := "%" + escapeLike(term.Text) + "%"
+			scoped = scoped.Where(`(LOWER(title) LIKE ? ESCAPE '\' OR LOWER(description) LIKE ? ESCAPE '\')`, pattern, pattern)
+			ranks = append(ranks, fmt.Sprintf(
+				`(CASE WHEN LOWER(title) LIKE ? ESCAPE '\' THEN %v ELSE 0 END + CASE WHEN LOWER(description) LIKE ? ESCAPE '\' THEN %v ELSE 0 END)`,
+				titleWeight, descriptionWeight,
+			))
+			args = append(args, pattern, pattern)
+		}
+		rank := fmt.Sprintf("(%s) / %d.0", strings.Join(ranks, " + "), len(ranks))
+		scoped = scoped.Select("id, "+rank+" AS rank", args...)
+	}
+
+	var rows []struct {
+		ID      uuid.UUID
+		Rank    float64
+		Title   string
+		Snippet string
+	}
+	err = scoped.
+		Order("rank DESC, updated_at DESC, id ASC").
+		Offset(offset).
+		Limit(filter.Limit + 1).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	var todos []Todo
+	if err := repository.db.WithContext(ctx).Where("id IN ?", ids).Find(&todos).Error; err != nil {
+		return nil, err
+	}
+	if err := repository.loadTags(ctx, todos); err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]Todo, len(todos))
+	for _, todo := range todos {
+		byID[todo.ID] = todo
+	}
+
+	hits := make([]SearchHit, 0, len(rows))
+	for _, row := range rows {
+		hit := SearchHit{Todo: byID[row.ID], Rank: row.Rank, Title: row.Title, Snippet: row.Snippet}
+		if !fullText {
+			hit.Title = query.Highlight(hit.Todo.Title)
+			hit.Snippet = query.Snippet(hit.Todo.Description)
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+// scopedQuery builds the user scoped query shared by listing and counting, without the cursor
+func (repository todoRepository) scopedQuery(ctx context.Context, userID string, filter TodoFilter) (*gorm.DB, error) {
+	if filter.Sort.Column() == "" {
+		return nil, fmt.Errorf("unsupported sort field %q", filter.Sort.Field)
+	}
+
+	query := repository.db.WithContext(ctx).Model(&Todo{}).Where("user_id = ?", userID)
+	if filter.Trashed {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if filter.Completed != nil {
+		query = query.Where("completed = ?", *filter.Completed)
+	}
+	if filter.Title != "" {
+		query = query.Where(`LOWER(title) LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(filter.Title))+"%")
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		query = query.Where("updated_at >= ?", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		query = query.Where("updated_at <= ?", *filter.UpdatedTo)
+	}
+	if len(filter.Priorities) > 0 {
+		query = query.Where("priority IN ?", filter.Priorities)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.Project != nil {
+		if *filter.Project == uuid.Nil {
+			query = query.Where("project_id IS NULL")
+		} else {
+			query = query.Where("project_id = ?", *filter.Project)
+		}
+	}
+	if len(filter.Tags) > 0 {
+		tagged := repository.db.WithContext(ctx).Table("todo_tags").
+			Select("todo_tags.todo_id").
+			Joins("JOIN tags ON tags.id = todo_tags.tag_id").
+			Where("tags.user_id = ? AND LOWER(tags.name) IN ?", userID, filter.Tags)
+		if filter.AllTags {
+			tagged = tagged.Group("todo_tags.todo_id").Having("COUNT(*) = ?", len(filter.Tags))
+		}
+		query = query.Where("id IN (?)", tagged)
+	}
+	if filter.Overdue {
+		query = query.Where("completed = ?", false)
+	}
+	if filter.Due != nil {
+		timed, timedArgs := dueBounds(filter.Due.From, filter.Due.To)
+		allDay, allDayArgs := dueBounds(filter.Due.FromDate, filter.Due.ToDate)
+		query = query.Where(
+			fmt.Sprintf("(all_day = ? AND %s) OR (all_day = ? AND %s)", timed, allDay),
+			append(append([]any{false}, timedArgs...), append([]any{true}, allDayArgs...)...)...,
+		)
+	}
+
+	return query, nil
+}
+
+// dueBounds builds the due_at condition of one side of a DueWindow
+func dueBounds(from *time.Time, to *time.Time) (string, []any) {
+	conditions := []string{"due_at IS NOT NULL"}
+	var args []any
+	if from != nil {
+		conditions = append(conditions, "due_at >= ?")
+		args = append(args, *from)
+	}
+	if to != nil {
+		conditions = append(conditions, "due_at < ?")
+		args = append(args, *to)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func (repository todoRepository) CreateTodo(ctx context.Context, todo *Todo) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := assignPosition(tx, todo); err != nil {
+			return err
+		}
+		if err := tx.Create(todo).Error; err != nil {
+			return err
+		}
+		return replaceTags(tx, todo)
+	})
+}
+
+// assignPosition puts a todo without a position before the user's first todo, rebalancing once keys grow too long
+func assignPosition(tx *gorm.DB, todo *Todo) error {
+	if todo.Position != "" {
+		return nil
+	}
+
+	for rebalanced := false; ; rebalanced = true {
+		var positions []string
+		err := tx.Model(&Todo{}).
+			Where("user_id = ?", todo.UserID).
+			Order("position ASC, id ASC").
+			Limit(1).
+			Pluck("position", &positions).Error
+		if err != nil {
+			return err
+		}
+
+		first := ""
+		if len(positions) > 0 {
+			first = positions[0]
+		}
+		position, err := PositionBetween("", first)
+		if err == nil && !needsRebalance(position) {
+			todo.Position = position
+			return nil
+		}
+		if rebalanced {
+			return ErrNoPositionBetween
+		}
+		if err := rebalancePositions(tx, todo.UserID); err != nil {
+			return err
+		}
+	}
+}
+
+func (repository todoRepository) FindAdjacentTodoPosition(ctx context.Context, todo *Todo, previous bool, exclude uuid.UUID) (string, error) {
+	query := repository.db.WithContext(ctx).Model(&Todo{}).Where("user_id = ? AND id <> ?", todo.UserID, exclude)
+	if previous {
+		query = query.
+			Where("position < ? OR (position = ? AND id < ?)", todo.Position, todo.Position, todo.ID).
+			Order("position DESC, id DESC")
+	} else {
+		query = query.
+			Where("position > ? OR (position = ? AND id > ?)", todo.Position, todo.Position, todo.ID).
+			Order("position ASC, id ASC")
+	}
+
+	var positions []string
+	if err := query.Limit(1).Pluck("position", &positions).Error; err != nil || len(positions) == 0 {
+		return "", err
+	}
+	return positions[0], nil
+}
+
+func (repository todoRepository) RebalanceTodoPositions(ctx context.Context, userID uuid.UUID) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return rebalancePositions(tx, userID)
+	})
+}
+
+// rebalancePositions rewrites the positions of the user's todos without changing their order, see balancedPositions
+// It is a single UPDATE that leaves version and updated_at alone, so ETags and undo tokens stay valid
+// as the todos only read differently in their position key
+func rebalancePositions(tx *gorm.DB, userID uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&Todo{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	// The rank is padded with zeros to the width of the count, substr keeps the last width digits
+	zeros := strings.Repeat("0", len(strconv.FormatInt(count, 10)))
+	// The ranks are joined in with FROM rather than a correlated subquery, so they are computed once from the old positions
+	return tx.Exec(`
+		UPDATE todos SET position = 'i' || substr(CAST(? AS TEXT) || CAST(ranked.position_rank AS TEXT), length(CAST(ranked.position_rank AS TEXT)) + 1) || 'i'
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY position ASC, id ASC) AS position_rank
+			FROM todos
+			WHERE user_id = ? AND deleted_at IS NULL
+		) ranked
+		WHERE todos.id = ranked.id`,
+		zeros, userID,
+	).Error
+}
+
+func (repository todoRepository) FindTodoByIDAndUserID(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) (*Todo, error) {
+	var todo Todo
+	err := repository.db.WithContext(ctx).Where("id = ? AND user_id = ?", todoID, userID).First(&todo).Error
+	if err != nil {
+		return &todo, err
+	}
+	return &todo, repository.loadTag(ctx, &todo)
+}
+
+// UpdateTodo saves the given columns of the todo, or all of them and its tags when no columns are given
+func (repository todoRepository) UpdateTodo(ctx context.Context, todo *Todo, columns ...string) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return saveTodo(tx, todo, columns)
+	})
+}
+
+// saveTodo writes only the given columns so that concurrent updates of other columns aren't overwritten
+// The tags column replaces the todo's tags with todo.Tags
+// The row must still have the version the todo was read at, which the write increments, or ErrVersionConflict is returned
+func saveTodo(tx *gorm.DB, todo *Todo, columns []string) error {
+	fields := []string{"*"}
+	if len(columns) > 0 {
+		fields = slices.DeleteFunc(slices.Clone(columns), func(column string) bool {
+			return column == tagsColumn
+		})
+		fields = append(fields, "version")
+	}
+
+	version := todo.Version
+	todo.Version++
+	result := tx.Model(todo).Where("version = ?", version).Select(fields).Updates(todo)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		todo.Version = version
+		return result.Error
+	}
+
+	if len(columns) == 0 || slices.Contains(columns, tagsColumn) {
+		return replaceTags(tx, todo)
+	}
+	return nil
+}
+
+// FindSubtasksByParentIDs returns the direct subtasks of the todos, oldest first
+func (repository todoRepository) FindSubtasksByParentIDs(ctx context.Context, userID uuid.UUID, parentIDs []uuid.UUID) ([]Todo, error) {
+	if len(parentIDs) == 0 {
+		return []Todo{}, nil
+	}
+
+	var todos []Todo
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ? AND parent_id IN ?", userID, parentIDs).
+		Order("created_at ASC, id ASC").
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, repository.loadTags(ctx, todos)
+}
+
+// DeleteTodo moves the todo to the trash, provided it still has the version it was read at
+// Its subtasks either go to the trash with it or move up to its parent, see SubtasksCascade and SubtasksReparent
+func (repository todoRepository) DeleteTodo(ctx context.Context, todo *Todo, subtasks string) ([]Todo, error) {
+	var changed []Todo
+	err := repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(todo).Where("version = ?", todo.Version).UpdateColumn("version", gorm.Expr("version + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		todo.Version++
+
+		if subtasks == SubtasksReparent {
+			var ids []uuid.UUID
+			if err := tx.Model(&Todo{}).Where("parent_id = ?", todo.ID).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			if len(ids) > 0 {
+				err := tx.Model(&Todo{}).Where("id IN ?", ids).UpdateColumns(map[string]any{
+					"parent_id": todo.ParentID,
+					"version":   gorm.Expr("version + 1"),
+				}).Error
+				if err != nil {
+					return err
+				}
+				if err := tx.Where("id IN ?", ids).Order("created_at ASC, id ASC").Find(&changed).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Delete(todo).Error
+		}
+
+		ids, err := subtaskIDs(tx, todo.ID, false)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", append(ids, todo.ID)).Delete(&Todo{}).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Order("created_at ASC, id ASC").Find(&changed).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, repository.loadTags(ctx, changed)
+}
+
+// subtaskIDs walks down the subtasks of the todo and returns the ids of every level
+// With trashed set it only follows subtasks in the trash, otherwise the ones that are not
+func subtaskIDs(tx *gorm.DB, todoID uuid.UUID, trashed bool) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for parentIDs := []uuid.UUID{todoID}; len(parentIDs) > 0; {
+		query := tx.Model(&Todo{}).Where("parent_id IN ?", parentIDs)
+		if trashed {
+			query = query.Unscoped().Where("deleted_at IS NOT NULL")
+		}
+
+		var children []uuid.UUID
+		if err := query.Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		parentIDs = children
+	}
+	return ids, nil
 }
 
-func (repository TodoRepository) FindTodoByIDAndUserID(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) (*Todo, error) {
+func (repository todoRepository) FindTrashedTodoByIDAndUserID(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) (*Todo, error) {
 	var todo Todo
-	err := repository.db.Where("id = ? AND user_id = ?", todoID, userID).First(&todo).Error
-	return &todo, err
+	err := repository.db.WithContext(ctx).Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", todoID, userID).
+		First(&todo).Error
+	if err != nil {
+		return &todo, err
+	}
+	return &todo, repository.loadTag(ctx, &todo)
+}
+
+// RestoreTodo takes the todo out of the trash along with the subtasks that were deleted with it
+// The todo's parent is saved as well, so callers can move it to the top level when its parent is gone
+func (repository todoRepository) RestoreTodo(ctx context.Context, todo *Todo) ([]Todo, error) {
+	var restored []Todo
+	err := repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids, err := subtaskIDs(tx, todo.ID, true)
+		if err != nil {
+			return err
+		}
+
+		// Subtasks deleted with the todo share its deletion time, the ones deleted before it stay in the trash
+		var subtasks []Todo
+		if len(ids) > 0 {
+			err = tx.Unscoped().Select("id", "deleted_at").Where("id IN ?", ids).Find(&subtasks).Error
+			if err != nil {
+				return err
+			}
+		}
+		ids = ids[:0]
+		for _, subtask := range subtasks {
+			if subtask.DeletedAt.Time.Equal(todo.DeletedAt.Time) {
+				ids = append(ids, subtask.ID)
+			}
+		}
+
+		err = tx.Unscoped().Model(todo).Updates(map[string]any{
+			"deleted_at": nil,
+			"parent_id":  todo.ParentID,
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		if err := tx.Unscoped().Model(&Todo{}).Where("id IN ?", ids).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Order("created_at ASC, id ASC").Find(&restored).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	todo.DeletedAt = gorm.DeletedAt{}
+	todo.Version++
+	return restored, repository.loadTags(ctx, restored)
+}
+
+// PurgeTodo permanently deletes the todo along with its subtasks in the trash
+func (repository todoRepository) PurgeTodo(ctx context.Context, todo *Todo) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids, err := subtaskIDs(tx, todo.ID, true)
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", append(ids, todo.ID)).Delete(&Todo{}).Error
+	})
+}
+
+// PurgeTodosDeletedBefore permanently deletes every todo that was moved to the trash before the given time
+func (repository todoRepository) PurgeTodosDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := repository.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before.UTC()).
+		Delete(&Todo{})
+	return result.RowsAffected, result.Error
+}
+
+// TransitionTodo saves the todo like UpdateTodo and records its status change in one transaction
+func (repository todoRepository) TransitionTodo(ctx context.Context, todo *Todo, transition *TodoTransition, columns ...string) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := saveTodo(tx, todo, columns); err != nil {
+			return err
+		}
+		return tx.Create(transition).Error
+	})
+}
+
+// CompleteOccurrence saves a completed occurrence of a recurring todo with its status change
+// and creates the next instance of the series in the same transaction
+func (repository todoRepository) CompleteOccurrence(ctx context.Context, todo *Todo, transition *TodoTransition, next *Todo, columns ...string) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := saveTodo(tx, todo, columns); err != nil {
+			return err
+		}
+		if err := tx.Create(transition).Error; err != nil {
+			return err
+		}
+		if err := assignPosition(tx, next); err != nil {
+			return err
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return replaceTags(tx, next)
+	})
+}
+
+func (repository todoRepository) FindTodoTransitionsByTodoID(ctx context.Context, todoID uuid.UUID) ([]TodoTransition, error) {
+	var transitions []TodoTransition
+	err := repository.db.WithContext(ctx).
+		Where("todo_id = ?", todoID).
+		Order("created_at ASC, id ASC").
+		Find(&transitions).Error
+	return transitions, err
+}
+
+func (repository todoRepository) AppendTodoHistory(ctx context.Context, history *TodoHistory) error {
+	return repository.db.WithContext(ctx).Create(history).Error
+}
+
+// FindTodoHistoryByUserID returns up to filter.Limit+1 history records of the user's todos, newest first,
+// so the caller can tell whether more follow
+func (repository todoRepository) FindTodoHistoryByUserID(ctx context.Context, userID uuid.UUID, filter HistoryFilter) ([]TodoHistory, error) {
+	query := repository.db.WithContext(ctx).Where("user_id = ?", userID)
+	if filter.TodoID != nil {
+		query = query.Where("todo_id = ?", *filter.TodoID)
+	}
+	if filter.Cursor != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", filter.Cursor.CreatedAt, filter.Cursor.CreatedAt, filter.Cursor.ID)
+	}
+
+	var history []TodoHistory
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(filter.Limit + 1).
+		Find(&history).Error
+	return history, err
+}
+
+func (repository todoRepository) CreateTodoUndo(ctx context.Context, undo *TodoUndo) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND expires_at < ?", undo.UserID, time.Now()).Delete(&TodoUndo{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(undo).Error
+	})
+}
+
+func (repository todoRepository) FindTodoUndoByIDAndUserID(ctx context.Context, undoID uuid.UUID, userID uuid.UUID) (*TodoUndo, error) {
+	var undo TodoUndo
+	err := repository.db.WithContext(ctx).Where("id = ? AND user_id = ?", undoID, userID).First(&undo).Error
+	return &undo, err
+}
+
+func (repository todoRepository) DeleteTodoUndo(ctx context.Context, undo *TodoUndo) error {
+	return repository.db.WithContext(ctx).Delete(undo).Error
+}
+
+// FindWorkflowByUserID returns the user's workflow, which has no statuses if the user never configured one
+func (repository todoRepository) FindWorkflowByUserID(ctx context.Context, userID uuid.UUID) (Workflow, error) {
+	var workflow Workflow
+	db := repository.db.WithContext(ctx)
+
+	if err := db.Where("user_id = ?", userID).Order("position ASC").Find(&workflow.Statuses).Error; err != nil {
+		return workflow, err
+	}
+	err := db.Where("user_id = ?", userID).Order("from_status ASC, to_status ASC").Find(&workflow.Transitions).Error
+	return workflow, err
+}
+
+// SaveWorkflow replaces the user's workflow and derives the completed flag of their todos from it
+func (repository todoRepository) SaveWorkflow(ctx context.Context, userID uuid.UUID, workflow *Workflow) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&WorkflowStatus{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&WorkflowTransition{}).Error; err != nil {
+			return err
+		}
+
+		for i := range workflow.Statuses {
+			workflow.Statuses[i].UserID = userID
+			workflow.Statuses[i].Position = i
+		}
+		for i := range workflow.Transitions {
+			workflow.Transitions[i].UserID = userID
+		}
+
+		if err := tx.Create(&workflow.Statuses).Error; err != nil {
+			return err
+		}
+		if len(workflow.Transitions) > 0 {
+			if err := tx.Create(&workflow.Transitions).Error; err != nil {
+				return err
+			}
+		}
+
+		// A status may have changed between open and done, so completed is derived again
+		doneKeys := workflow.doneKeys()
+		return tx.Unscoped().Model(&Todo{}).
+			Where("user_id = ? AND completed <> (status IN ?)", userID, doneKeys).
+			UpdateColumns(map[string]any{
+				"completed": gorm.Expr("status IN ?", doneKeys),
+				"version":   gorm.Expr("version + 1"),
+			}).Error
+	})
+}
+
+// FindUsedStatusesByUserID returns the statuses of the user's todos, including the ones in the trash
+func (repository todoRepository) FindUsedStatusesByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var statuses []string
+	err := repository.db.WithContext(ctx).Unscoped().Model(&Todo{}).
+		Where("user_id = ?", userID).
+		Distinct().
+		Pluck("status", &statuses).Error
+	return statuses, err
+}
+
+// todoTag is a tag joined with the todo it is assigned to
+type todoTag struct {
+	Tag
+	TodoID uuid.UUID
+}
+
+// loadTags fills in the tags of the todos with a single query
+func (repository todoRepository) loadTags(ctx context.Context, todos []Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(todos))
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+
+	var rows []todoTag
+	err := repository.db.WithContext(ctx).Model(&Tag{}).
+		Select("tags.*, todo_tags.todo_id").
+		Joins("JOIN todo_tags ON todo_tags.tag_id = tags.id").
+		Where("todo_tags.todo_id IN ?", ids).
+		Order("LOWER(tags.name) ASC, tags.id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	tags := make(map[uuid.UUID][]Tag, len(todos))
+	for _, row := range rows {
+		tags[row.TodoID] = append(tags[row.TodoID], row.Tag)
+	}
+	for i := range todos {
+		todos[i].Tags = tags[todos[i].ID]
+		if todos[i].Tags == nil {
+			todos[i].Tags = []Tag{}
+		}
+	}
+	return nil
+}
+
+func (repository todoRepository) loadTag(ctx context.Context, todo *Todo) error {
+	todos := []Todo{*todo}
+	if err := repository.loadTags(ctx, todos); err != nil {
+		return err
+	}
+	todo.Tags = todos[0].Tags
+	return nil
+}
+
+// replaceTags assigns exactly todo.Tags to the todo
+func replaceTags(tx *gorm.DB, todo *Todo) error {
+	if err := tx.Where("todo_id = ?", todo.ID).Delete(&TodoTag{}).Error; err != nil {
+		return err
+	}
+	if len(todo.Tags) == 0 {
+		return nil
+	}
+
+	assignments := make([]TodoTag, 0, len(todo.Tags))
+	for _, tag := range todo.Tags {
+		assignments = append(assignments, TodoTag{TodoID: todo.ID, TagID: tag.ID})
+	}
+	return tx.Create(&assignments).Error
+}
+
+func (repository todoRepository) FindTagsByUserID(ctx context.Context, userID uuid.UUID) ([]Tag, error) {
+	var tags []Tag
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("LOWER(name) ASC, id ASC").
+		Find(&tags).Error
+	return tags, err
+}
+
+func (repository todoRepository) FindTagByIDAndUserID(ctx context.Context, tagID uuid.UUID, userID uuid.UUID) (*Tag, error) {
+	var tag Tag
+	err := repository.db.WithContext(ctx).Where("id = ? AND user_id = ?", tagID, userID).First(&tag).Error
+	return &tag, err
+}
+
+func (repository todoRepository) FindTagsByIDs(ctx context.Context, userID uuid.UUID, tagIDs []uuid.UUID) ([]Tag, error) {
+	var tags []Tag
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ? AND id IN ?", userID, tagIDs).
+		Order("LOWER(name) ASC, id ASC").
+		Find(&tags).Error
+	return tags, err
+}
+
+// FindTagsByNames looks tags up by name, ignoring case
+func (repository todoRepository) FindTagsByNames(ctx context.Context, userID uuid.UUID, names []string) ([]Tag, error) {
+	lowered := make([]string, 0, len(names))
+	for _, name := range names {
+		lowered = append(lowered, strings.ToLower(name))
+	}
+
+	var tags []Tag
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ? AND LOWER(name) IN ?", userID, lowered).
+		Order("LOWER(name) ASC, id ASC").
+		Find(&tags).Error
+	return tags, err
+}
+
+func (repository todoRepository) CreateTag(ctx context.Context, tag *Tag) error {
+	return repository.db.WithContext(ctx).Create(tag).Error
+}
+
+func (repository todoRepository) UpdateTag(ctx context.Context, tag *Tag) error {
+	return repository.db.WithContext(ctx).Save(tag).Error
+}
+
+// DeleteTag permanently deletes the tag and takes it off every todo
+func (repository todoRepository) DeleteTag(ctx context.Context, tag *Tag) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", tag.ID).Delete(&TodoTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(tag).Error
+	})
+}
+
+// FindProjectsByUserID returns the user's projects in their order, archived ones are only included when asked for
+func (repository todoRepository) FindProjectsByUserID(ctx context.Context, userID uuid.UUID, archived bool) ([]Project, error) {
+	query := repository.db.WithContext(ctx).Where("user_id = ?", userID)
+	if !archived {
+		query = query.Where("archived = ?", false)
+	}
+
+	var projects []Project
+	err := query.Order("position ASC, id ASC").Find(&projects).Error
+	return projects, err
+}
+
+func (repository todoRepository) FindProjectByIDAndUserID(ctx context.Context, projectID uuid.UUID, userID uuid.UUID) (*Project, error) {
+	var project Project
+	err := repository.db.WithContext(ctx).Where("id = ? AND user_id = ?", projectID, userID).First(&project).Error
+	return &project, err
+}
+
+// CreateProject adds the project after the user's other projects
+func (repository todoRepository) CreateProject(ctx context.Context, project *Project) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Project{}).
+			Where("user_id = ?", project.UserID).
+			Select("COALESCE(MAX(position), -1) + 1").
+			Scan(&project.Position).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(project).Error
+	})
+}
+
+func (repository todoRepository) UpdateProject(ctx context.Context, project *Project) error {
+	return repository.db.WithContext(ctx).Save(project).Error
+}
+
+// UpdateProjectPositions saves the position of every project in one transaction
+func (repository todoRepository) UpdateProjectPositions(ctx context.Context, projects []Project) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, project := range projects {
+			err := tx.Model(&Project{}).Where("id = ?", project.ID).UpdateColumn("position", project.Position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CountTodosByProject counts the user's open and completed todos per project, the inbox is counted under uuid.Nil
+func (repository todoRepository) CountTodosByProject(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]TodoCounts, error) {
+	var rows []struct {
+		ProjectID *uuid.UUID
+		Completed bool
+		Total     int64
+	}
+	err := repository.db.WithContext(ctx).Model(&Todo{}).
+		Select("project_id, completed, COUNT(*) AS total").
+		Where("user_id = ?", userID).
+		Group("project_id, completed").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]TodoCounts)
+	for _, row := range rows {
+		key := uuid.Nil
+		if row.ProjectID != nil {
+			key = *row.ProjectID
+		}
+		count := counts[key]
+		if row.Completed {
+			count.Completed += row.Total
+		} else {
+			count.Open += row.Total
+		}
+		counts[key] = count
+	}
+	return counts, nil
+}
+
+// FindChecklistItemsByTodoIDs returns the checklist items of the todos in their order
+func (repository todoRepository) FindChecklistItemsByTodoIDs(ctx context.Context, todoIDs []uuid.UUID) ([]ChecklistItem, error) {
+	if len(todoIDs) == 0 {
+		return []ChecklistItem{}, nil
+	}
+
+	var items []ChecklistItem
+	err := repository.db.WithContext(ctx).
+		Where("todo_id IN ?", todoIDs).
+		Order("position ASC, id ASC").
+		Find(&items).Error
+	return items, err
+}
+
+func (repository todoRepository) FindChecklistItemByIDAndTodoID(ctx context.Context, itemID uuid.UUID, todoID uuid.UUID) (*ChecklistItem, error) {
+	var item ChecklistItem
+	err := repository.db.WithContext(ctx).Where("id = ? AND todo_id = ?", itemID, todoID).First(&item).Error
+	return &item, err
+}
+
+// CreateChecklistItem adds the item at the end of the todo's checklist
+func (repository todoRepository) CreateChecklistItem(ctx context.Context, item *ChecklistItem) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&ChecklistItem{}).
+			Where("todo_id = ?", item.TodoID).
+			Select("COALESCE(MAX(position), -1) + 1").
+			Scan(&item.Position).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(item).Error
+	})
+}
+
+func (repository todoRepository) UpdateChecklistItem(ctx context.Context, item *ChecklistItem) error {
+	return repository.db.WithContext(ctx).Save(item).Error
 }
 
-func (repository TodoRepository) UpdateTodo(ctx context.Context, todo *Todo) error {
-	return repository.db.Save(todo).Error
+// DeleteChecklistItem permanently deletes the item
+func (repository todoRepository) DeleteChecklistItem(ctx context.Context, item *ChecklistItem) error {
+	return repository.db.WithContext(ctx).Unscoped().Delete(item).Error
 }
 
-func (repository TodoRepository) DeleteTodo(ctx context.Context, todo *Todo) error {
-	return repository.db.Delete(todo).Error
+// UpdateChecklistItemPositions saves the position of every item in one transaction
+func (repository todoRepository) UpdateChecklistItemPositions(ctx context.Context, items []ChecklistItem) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			err := tx.Model(&ChecklistItem{}).Where("id = ?", item.ID).UpdateColumn("position", item.Position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
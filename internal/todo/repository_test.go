@@ -0,0 +1,244 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/internal/auth"
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/Alfian57/golang-todo/pkg/database/databasetest"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/migrate"
+	"github.com/Alfian57/golang-todo/pkg/module"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// testRepositories returns a constructor for every TodoRepository implementation
+// so that the service and handler tests check they all behave the same
+func testRepositories() map[string]func(t *testing.T) TodoRepository {
+	return map[string]func(t *testing.T) TodoRepository{
+		"memory": func(t *testing.T) TodoRepository {
+			return NewMemoryTodoRepository()
+		},
+		"sqlite": func(t *testing.T) TodoRepository {
+			db := databasetest.NewSQLite(t, auth.Module{}, Module{})
+
+			// Tests create todos for random user IDs without seeding the users table
+			if err := db.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+				t.Fatalf("failed to disable foreign keys: %v", err)
+			}
+			return NewTodoRepository(db)
+		},
+	}
+}
+
+// Before soft delete was enabled, gorm wrote the zero time into deleted_at
+// The migration must reset those rows to NULL or every existing todo would vanish
+func TestResetZeroDeletedAtMigration(t *testing.T) {
+	ctx := context.Background()
+	db := databasetest.NewSQLite(t)
+	runner, err := migrate.NewModuleRunner(db, config.DriverSQLite, logger.NewNopLogger(), []module.Module{auth.Module{}, Module{}})
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if err := runner.To(ctx, 3); err != nil {
+		t.Fatalf("failed to migrate to the legacy schema: %v", err)
+	}
+
+	userID := uuid.New()
+	now := time.Now().UTC()
+	err = db.Exec(`INSERT INTO users (id, username, password, created_at, updated_at, deleted_at) VALUES (?, 'alice', 'hash', ?, ?, ?)`,
+		userID, now, now, time.Time{}).Error
+	if err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	err = db.Exec(`INSERT INTO todos (id, title, user_id, created_at, updated_at, deleted_at) VALUES (?, 'Walk dog', ?, ?, ?, ?)`,
+		uuid.New(), userID, now, now, time.Time{}).Error
+	if err != nil {
+		t.Fatalf("failed to seed todo: %v", err)
+	}
+
+	if err := runner.Up(ctx); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	filter, _ := NewTodoFilter(GetTodosRequest{}, time.Now())
+	total, err := NewTodoRepository(db).CountTodoByUserID(ctx, userID.String(), filter)
+	if err != nil || total != 1 {
+		t.Errorf("total = %d (%v), want 1", total, err)
+	}
+	if _, err := auth.NewAuthRepository(db).FindUserByID(ctx, userID); err != nil {
+		t.Errorf("user not found after migration: %v", err)
+	}
+}
+
+// Todos are only found for the user who owns them
+func TestTodoRepositoryFindTodoByIDAndUserID(t *testing.T) {
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			userID := uuid.New()
+
+			seedTodos(t, repository, uuid.New(), "Walk dog")
+			todos := seedTodos(t, repository, userID, "Buy milk")
+
+			todo, err := repository.FindTodoByIDAndUserID(ctx, todos[0].ID, userID)
+			if err != nil {
+				t.Fatalf("failed to find todo: %v", err)
+			}
+			if todo.ID != todos[0].ID || todo.Title != "Buy milk" {
+				t.Errorf("found %s %q, want %s %q", todo.ID, todo.Title, todos[0].ID, "Buy milk")
+			}
+
+			if _, err := repository.FindTodoByIDAndUserID(ctx, todos[0].ID, uuid.New()); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("todo of another user error = %v, want %v", err, gorm.ErrRecordNotFound)
+			}
+			if _, err := repository.FindTodoByIDAndUserID(ctx, uuid.New(), userID); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("unknown todo error = %v, want %v", err, gorm.ErrRecordNotFound)
+			}
+		})
+	}
+}
+
+// Updates that name their columns leave the other columns as they are
+func TestTodoRepositoryUpdateTodoColumns(t *testing.T) {
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			userID := uuid.New()
+			seeded := seedTodos(t, repository, userID, "Walk dog")[0]
+
+			todo, err := repository.FindTodoByIDAndUserID(ctx, seeded.ID, userID)
+			if err != nil {
+				t.Fatalf("failed to find todo: %v", err)
+			}
+			todo.Title = "Walk cat"
+			todo.Completed = true
+			if err := repository.UpdateTodo(ctx, todo, "title"); err != nil {
+				t.Fatalf("failed to update title: %v", err)
+			}
+
+			todo, err = repository.FindTodoByIDAndUserID(ctx, seeded.ID, userID)
+			if err != nil {
+				t.Fatalf("failed to find todo: %v", err)
+			}
+			if todo.Title != "Walk cat" {
+				t.Errorf("title = %q, want %q", todo.Title, "Walk cat")
+			}
+			if todo.Completed {
+				t.Errorf("completed was written without being named")
+			}
+			if todo.Version != seeded.Version+1 {
+				t.Errorf("version = %d, want %d", todo.Version, seeded.Version+1)
+			}
+		})
+	}
+}
+
+// A write based on a todo that has been changed since it was read fails instead of overwriting the change
+func TestTodoRepositoryChecksVersion(t *testing.T) {
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			userID := uuid.New()
+			seeded := seedTodos(t, repository, userID, "Walk dog")[0]
+			if seeded.Version != 1 {
+				t.Errorf("version of a new todo = %d, want 1", seeded.Version)
+			}
+
+			first, err := repository.FindTodoByIDAndUserID(ctx, seeded.ID, userID)
+			if err != nil {
+				t.Fatalf("failed to find todo: %v", err)
+			}
+			second, err := repository.FindTodoByIDAndUserID(ctx, seeded.ID, userID)
+			if err != nil {
+				t.Fatalf("failed to find todo: %v", err)
+			}
+
+			first.Title = "Walk cat"
+			if err := repository.UpdateTodo(ctx, first, "title"); err != nil {
+				t.Fatalf("failed to update title: %v", err)
+			}
+			second.Description = "Around the park"
+			if err := repository.UpdateTodo(ctx, second, "description"); !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("stale update error = %v, want %v", err, ErrVersionConflict)
+			}
+			if err := repository.UpdateTodo(ctx, second); !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("stale save error = %v, want %v", err, ErrVersionConflict)
+			}
+			if _, err := repository.DeleteTodo(ctx, second, SubtasksCascade); !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("stale delete error = %v, want %v", err, ErrVersionConflict)
+			}
+
+			todo, err := repository.FindTodoByIDAndUserID(ctx, seeded.ID, userID)
+			if err != nil {
+				t.Fatalf("failed to find todo: %v", err)
+			}
+			if todo.Title != "Walk cat" || todo.Description != "" {
+				t.Errorf("todo = %q %q, want only the first update", todo.Title, todo.Description)
+			}
+			if _, err := repository.DeleteTodo(ctx, todo, SubtasksCascade); err != nil {
+				t.Errorf("failed to delete the current version: %v", err)
+			}
+		})
+	}
+}
+
+func TestTodoRepositoryTransaction(t *testing.T) {
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			userID := uuid.New()
+			failed := errors.New("failed")
+
+			var kept, dropped Todo
+			err := repository.Transaction(ctx, func(repository TodoRepository) error {
+				kept = Todo{Title: "Walk dog", UserID: userID, Priority: defaultPriority, Status: "backlog"}
+				if err := repository.CreateTodo(ctx, &kept); err != nil {
+					return err
+				}
+				err := repository.Transaction(ctx, func(repository TodoRepository) error {
+					dropped = Todo{Title: "Walk cat", UserID: userID, Priority: defaultPriority, Status: "backlog"}
+					if err := repository.CreateTodo(ctx, &dropped); err != nil {
+						return err
+					}
+					return failed
+				})
+				if !errors.Is(err, failed) {
+					t.Errorf("nested transaction error = %v, want %v", err, failed)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("transaction failed: %v", err)
+			}
+			if _, err := repository.FindTodoByIDAndUserID(ctx, kept.ID, userID); err != nil {
+				t.Errorf("committed todo not found: %v", err)
+			}
+			if _, err := repository.FindTodoByIDAndUserID(ctx, dropped.ID, userID); err == nil {
+				t.Error("todo of the rolled back nested transaction found")
+			}
+
+			err = repository.Transaction(ctx, func(repository TodoRepository) error {
+				kept.Title = "Walk the dog"
+				if err := repository.UpdateTodo(ctx, &kept, "title"); err != nil {
+					return err
+				}
+				return failed
+			})
+			if !errors.Is(err, failed) {
+				t.Fatalf("transaction error = %v, want %v", err, failed)
+			}
+			if todo, err := repository.FindTodoByIDAndUserID(ctx, kept.ID, userID); err != nil || todo.Title != "Walk dog" || todo.Version != 1 {
+				t.Errorf("todo after rollback = %+v (%v), want it unchanged", todo, err)
+			}
+		})
+	}
+}
@@ -1,27 +1,72 @@
 package todo
 
 import (
-	"github.com/Alfian57/golang-todo/pkg/config"
-	"github.com/Alfian57/golang-todo/pkg/logger"
 	"github.com/Alfian57/golang-todo/pkg/middleware"
-	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/Alfian57/golang-todo/pkg/module"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB, cfg *config.Config, log logger.Logger) {
-	jwtUtils := utils.NewJWTUtils(cfg)
-	isDebug := cfg.App.Mode != "release"
+func RegisterRoutes(router *gin.RouterGroup, deps *module.Dependencies) {
+	todoRepository := NewTodoRepository(deps.DB)
+	todoService := NewTodoService(todoRepository, deps.Config.Todo, deps.Log, deps.IsDebug)
+	todoHandler := NewTodoHandler(todoService, deps.Log)
 
-	todoRepository := NewTodoRepository(db)
-	todoService := NewTodoService(todoRepository, log, isDebug)
-	todoHandler := NewTodoHandler(todoService, log)
-
-	todoGroup := router.Group("/todo", middleware.AuthMiddleware(jwtUtils, isDebug))
+	todoGroup := router.Group("/todo", middleware.AuthMiddleware(deps.JWTUtils, deps.TokenValidator, deps.IsDebug))
 	{
 		todoGroup.GET("/", todoHandler.GetAll)
 		todoGroup.POST("/", todoHandler.Create)
+		todoGroup.GET("/:id", todoHandler.Get)
 		todoGroup.PUT("/:id", todoHandler.Update)
+		todoGroup.PATCH("/:id", todoHandler.Patch)
 		todoGroup.DELETE("/:id", todoHandler.Delete)
+
+		todoGroup.POST("/bulk", todoHandler.Bulk)
+		todoGroup.GET("/search", todoHandler.Search)
+		todoGroup.GET("/upcoming", todoHandler.Upcoming)
+		todoGroup.GET("/activity", todoHandler.GetActivity)
+		todoGroup.GET("/board", todoHandler.GetBoard)
+		todoGroup.GET("/workflow", todoHandler.GetWorkflow)
+		todoGroup.PUT("/workflow", todoHandler.UpdateWorkflow)
+		todoGroup.GET("/:id/transitions", todoHandler.GetTransitions)
+		todoGroup.GET("/:id/history", todoHandler.GetHistory)
+		todoGroup.PUT("/:id/project", todoHandler.MoveTodo)
+		todoGroup.POST("/:id/move", todoHandler.ReorderTodo)
+		todoGroup.PUT("/:id/parent", todoHandler.SetParent)
+		todoGroup.POST("/:id/skip", todoHandler.SkipOccurrence)
+		todoGroup.POST("/:id/complete-series", todoHandler.CompleteSeries)
+		todoGroup.GET("/:id/checklist", todoHandler.GetChecklist)
+		todoGroup.POST("/:id/checklist", todoHandler.CreateChecklistItem)
+		todoGroup.PUT("/:id/checklist/order", todoHandler.ReorderChecklist)
+		todoGroup.PUT("/:id/checklist/:item_id", todoHandler.UpdateChecklistItem)
+		todoGroup.POST("/:id/checklist/:item_id/toggle", todoHandler.ToggleChecklistItem)
+		todoGroup.DELETE("/:id/checklist/:item_id", todoHandler.DeleteChecklistItem)
+		todoGroup.GET("/trash", todoHandler.GetTrash)
+		todoGroup.POST("/:id/restore", todoHandler.Restore)
+		todoGroup.DELETE("/trash/:id", todoHandler.Purge)
+	}
+
+	undoGroup := router.Group("/undo", middleware.AuthMiddleware(deps.JWTUtils, deps.TokenValidator, deps.IsDebug))
+	{
+		undoGroup.POST("/:token", todoHandler.Undo)
+	}
+
+	tagGroup := router.Group("/tags", middleware.AuthMiddleware(deps.JWTUtils, deps.TokenValidator, deps.IsDebug))
+	{
+		tagGroup.GET("", todoHandler.GetTags)
+		tagGroup.POST("", todoHandler.CreateTag)
+		tagGroup.GET("/:id", todoHandler.GetTag)
+		tagGroup.PUT("/:id", todoHandler.UpdateTag)
+		tagGroup.DELETE("/:id", todoHandler.DeleteTag)
+	}
+
+	projectGroup := router.Group("/projects", middleware.AuthMiddleware(deps.JWTUtils, deps.TokenValidator, deps.IsDebug))
+	{
+		projectGroup.GET("", todoHandler.GetProjects)
+		projectGroup.POST("", todoHandler.CreateProject)
+		projectGroup.PUT("/order", todoHandler.ReorderProjects)
+		projectGroup.GET("/:id", todoHandler.GetProject)
+		projectGroup.PUT("/:id", todoHandler.UpdateProject)
+		projectGroup.POST("/:id/archive", todoHandler.ArchiveProject)
+		projectGroup.POST("/:id/unarchive", todoHandler.UnarchiveProject)
 	}
 }
@@ -0,0 +1,175 @@
+package todo
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultSearchLimit = 20
+
+	// snippetLength is the number of runes of a description that a snippet shows
+	snippetLength = 160
+
+	// highlightStart and highlightStop delimit the matches in a highlighted text until markHighlights escapes it
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+// Weights of a match in the title and in the description, the same as Postgres gives the A and B labels
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+)
+
+var ErrEmptySearch = errors.New("search query has no words")
+
+// SearchTerm is a word, a prefix such as walk* or a quoted phrase such as "walk the dog"
+type SearchTerm struct {
+	// Text is the lowercased term, matched as a substring where full text search isn't available
+	Text   string
+	Words  []string
+	Prefix bool
+}
+
+// SearchQuery is a parsed search, a todo matches when it matches every term
+type SearchQuery struct {
+	Terms []SearchTerm
+}
+
+// ParseSearchQuery parses a search of words, prefixes ending in * and phrases in double quotes
+// Words are split on anything but letters and digits, so a word such as e-mail is searched as a phrase
+func ParseSearchQuery(value string) (SearchQuery, error) {
+	var query SearchQuery
+	rest := strings.ToLower(value)
+	for rest != "" {
+		var text string
+		if after, ok := strings.CutPrefix(rest, `"`); ok {
+			text, rest, _ = strings.Cut(after, `"`)
+		} else {
+			end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(rest)
+			}
+			text, rest = rest[:end], rest[end:]
+		}
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+
+		term := SearchTerm{Text: strings.TrimSpace(text)}
+		term.Text, term.Prefix = strings.CutSuffix(term.Text, "*")
+		term.Words = strings.FieldsFunc(term.Text, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(term.Words) > 0 {
+			query.Terms = append(query.Terms, term)
+		}
+	}
+
+	if len(query.Terms) == 0 {
+		return query, ErrEmptySearch
+	}
+	return query, nil
+}
+
+// TSQuery returns the query in the syntax of Postgres' to_tsquery
+// Words only hold letters and digits, so they never carry tsquery operators
+func (query SearchQuery) TSQuery() string {
+	terms := make([]string, 0, len(query.Terms))
+	for _, term := range query.Terms {
+		words := slices.Clone(term.Words)
+		if term.Prefix {
+			words[len(words)-1] += ":*"
+		}
+		terms = append(terms, "("+strings.Join(words, " <-> ")+")")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// Rank ranks a todo by the terms its title and description contain, zero when a term is in neither
+// It matches substrings like the LIKE fallback of the database repository does
+func (query SearchQuery) Rank(title string, description string) float64 {
+	title, description = strings.ToLower(title), strings.ToLower(description)
+
+	var rank float64
+	for _, term := range query.Terms {
+		inTitle := strings.Contains(title, term.Text)
+		inDescription := strings.Contains(description, term.Text)
+		if !inTitle && !inDescription {
+			return 0
+		}
+		if inTitle {
+			rank += titleWeight
+		}
+		if inDescription {
+			rank += descriptionWeight
+		}
+	}
+	return rank / float64(len(query.Terms))
+}
+
+// pattern matches the text of any of the terms, longer terms first so they win over the terms they contain
+func (query SearchQuery) pattern() *regexp.Regexp {
+	texts := make([]string, 0, len(query.Terms))
+	for _, term := range query.Terms {
+		texts = append(texts, regexp.QuoteMeta(term.Text))
+	}
+	slices.SortFunc(texts, func(a, b string) int { return len(b) - len(a) })
+	return regexp.MustCompile("(?i)" + strings.Join(texts, "|"))
+}
+
+// Highlight delimits the matches of the terms in the text
+func (query SearchQuery) Highlight(text string) string {
+	return query.pattern().ReplaceAllString(text, highlightStart+"$0"+highlightStop)
+}
+
+// Snippet returns the part of the text around the first match with the matches highlighted,
+// or the start of the text when nothing matches
+func (query SearchQuery) Snippet(text string) string {
+	start := 0
+	if match := query.pattern().FindStringIndex(text); match != nil {
+		// Show some context before the match, starting at a word
+		start = max(0, match[0]-snippetLength/4)
+		if space := strings.IndexFunc(text[start:match[0]], unicode.IsSpace); start > 0 && space >= 0 {
+			start += space + 1
+		}
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start++
+		}
+	}
+
+	snippet := text[start:]
+	truncated := false
+	if utf8.RuneCountInString(snippet) > snippetLength {
+		snippet = string([]rune(snippet)[:snippetLength])
+		truncated = true
+	}
+
+	snippet = query.Highlight(snippet)
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if truncated {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// markHighlights escapes a highlighted text for HTML and wraps its matches in <mark> tags
+func markHighlights(text string) string {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, highlightStart, "<mark>")
+	return strings.ReplaceAll(text, highlightStop, "</mark>")
+}
+
+// SearchHit is a todo that matches a search with its rank, its highlighted title and a snippet of its description
+type SearchHit struct {
+	Todo    Todo
+	Rank    float64
+	Title   string
+	Snippet string
+}
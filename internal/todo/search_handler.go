@@ -0,0 +1,60 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Search todos
+// @Description  Search the titles and descriptions of the authenticated user's todos, best match first. Words match in any order, a word ending in * matches as a prefix and "quoted words" match as a phrase. Postgres uses full text search with stemming, other databases match substrings
+// @Tags         Todo
+// @Produce      json
+// @Param        q          query     string    true   "Search query"
+// @Param        limit      query     int       false  "Page size (1-100, default 20)"
+// @Param        offset     query     int       false  "Number of matches to skip"
+// @Param        completed  query     bool      false  "Filter by completion"
+// @Param        priority   query     []string  false  "Only these priorities"  collectionFormat(multi)  Enums(P0, P1, P2, P3)
+// @Param        status     query     []string  false  "Only these workflow statuses"  collectionFormat(multi)
+// @Param        tag        query     []string  false  "Only todos with these tag names"  collectionFormat(multi)
+// @Param        tag_mode   query     string    false  "Match any or all of the tags (default any)"  Enums(any, all)
+// @Param        project    query     string    false  "Only todos of this project id, or inbox for todos without a project"
+// @Param        If-None-Match  header  string  false  "ETag of a previous response, answered with 304 while the results are unchanged"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.SearchTodosResponse}
+// @Header       200  {string}  ETag  "Weak ETag of the response"
+// @Success      304  "Results are unchanged"
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/search [get]
+func (handler TodoHandler) Search(ctx *gin.Context) {
+	var req SearchTodosRequest
+	if !utils.ValidateQuery(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Search todos"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.Search(ctx, userID, req)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Search todos request failed",
+			logger.F("operation", "Search todos"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	utils.JSONWithETag(ctx, response)
+}
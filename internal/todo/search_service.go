@@ -0,0 +1,76 @@
+package todo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// Search returns a page of the user's todos matching a search of their title and description, best match first
+// The search can be narrowed with the same filters as listing todos
+func (service TodoService) Search(ctx context.Context, userID uuid.UUID, req SearchTodosRequest) models.Response {
+	query, err := ParseSearchQuery(req.Query)
+	if err != nil {
+		return utils.UnprocessableEntityResponse("Invalid search query", err, service.isDebug)
+	}
+	filter, err := NewTodoFilter(GetTodosRequest{
+		Limit:     req.Limit,
+		Completed: req.Completed,
+		Priority:  req.Priority,
+		Status:    req.Status,
+		Tag:       req.Tag,
+		TagMode:   req.TagMode,
+		Project:   req.Project,
+	}, service.now())
+	if errors.Is(err, ErrInvalidPriority) {
+		return utils.UnprocessableEntityResponse("Invalid priority", err, service.isDebug)
+	}
+	if errors.Is(err, ErrInvalidProject) {
+		return utils.UnprocessableEntityResponse("Invalid project", err, service.isDebug)
+	}
+	if err != nil {
+		return utils.UnprocessableEntityResponse("Invalid search filter", err, service.isDebug)
+	}
+	if req.Limit == 0 {
+		filter.Limit = defaultSearchLimit
+	}
+
+	hits, err := service.todoRepository.SearchTodosByUserID(ctx, userID.String(), query, filter, req.Offset)
+	if err != nil {
+		service.log.Error("Failed to search todos",
+			logger.F("operation", "Search todos"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to search todos", err, service.isDebug)
+	}
+
+	// The repository fetches one extra match to tell whether another page exists
+	meta := SearchMeta{
+		Limit:  filter.Limit,
+		Offset: req.Offset,
+	}
+	if len(hits) > filter.Limit {
+		hits = hits[:filter.Limit]
+		meta.HasMore = true
+	}
+
+	results := make([]TodoSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, TodoSearchResult{
+			Todo:           NewTodoResponse(hit.Todo),
+			Rank:           hit.Rank,
+			TitleHighlight: markHighlights(hit.Title),
+			Snippet:        markHighlights(hit.Snippet),
+		})
+	}
+	responseData := SearchTodosResponse{
+		Results: results,
+		Meta:    meta,
+	}
+	return utils.OkResponse("Todos found successfully", responseData)
+}
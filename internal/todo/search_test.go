@@ -0,0 +1,132 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query       string
+		wantTSQuery string
+		wantErr     error
+	}{
+		{query: "walk dog", wantTSQuery: "(walk) & (dog)"},
+		{query: "Walk*", wantTSQuery: "(walk:*)"},
+		{query: `"walk the dog" park`, wantTSQuery: "(walk <-> the <-> dog) & (park)"},
+		{query: `e-mail boss`, wantTSQuery: "(e <-> mail) & (boss)"},
+		{query: `"quoted prefix*"`, wantTSQuery: "(quoted <-> prefix:*)"},
+		{query: `"unterminated phrase`, wantTSQuery: "(unterminated <-> phrase)"},
+		{query: `walk's & !dog:*`, wantTSQuery: "(walk <-> s) & (dog:*)"},
+		{query: `  " " * & `, wantErr: ErrEmptySearch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := ParseSearchQuery(tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && query.TSQuery() != tt.wantTSQuery {
+				t.Errorf("tsquery = %q, want %q", query.TSQuery(), tt.wantTSQuery)
+			}
+		})
+	}
+}
+
+func TestSearchQueryHighlight(t *testing.T) {
+	query, err := ParseSearchQuery(`walk "the dog"`)
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	if got := markHighlights(query.Highlight("Walk <the> dog, walk THE DOG")); got != "<mark>Walk</mark> &lt;the&gt; dog, <mark>walk</mark> <mark>THE DOG</mark>" {
+		t.Errorf("highlight = %q", got)
+	}
+
+	description := strings.Repeat("lorem ipsum ", 20) + "then walk the dog " + strings.Repeat("dolor sit ", 20)
+	snippet := markHighlights(query.Snippet(description))
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") || !strings.Contains(snippet, "<mark>walk</mark> <mark>the dog</mark>") {
+		t.Errorf("snippet = %q, want the matches with context cut at both ends", snippet)
+	}
+	if got := query.Snippet("Nothing here"); got != "Nothing here" {
+		t.Errorf("snippet without a match = %q, want the start of the text", got)
+	}
+}
+
+func TestTodoServiceSearch(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			createTodo(t, service, userID, CreateTodoRequest{Title: "Walk the dog", Description: "Around the park", Priority: "P1"})
+			createTodo(t, service, userID, CreateTodoRequest{Title: "Buy food", Description: "Dog food and a <new> walking lead"})
+			createTodo(t, service, userID, CreateTodoRequest{Title: "Call boss", Description: "About the dog walker"})
+			trashed := createTodo(t, service, userID, CreateTodoRequest{Title: "Walk cat"})
+			service.Delete(ctx, trashed.Id, DeleteTodoRequest{}, userID)
+			createTodo(t, service, uuid.New(), CreateTodoRequest{Title: "Walk their dog"})
+
+			search := func(req SearchTodosRequest) SearchTodosResponse {
+				t.Helper()
+				response := service.Search(ctx, userID, req)
+				if response.StatusCode != http.StatusOK {
+					t.Fatalf("search %q status = %d, want %d (%s)", req.Query, response.StatusCode, http.StatusOK, response.Message)
+				}
+				return response.Data.(SearchTodosResponse)
+			}
+			titles := func(response SearchTodosResponse) []string {
+				titles := make([]string, 0, len(response.Results))
+				for _, result := range response.Results {
+					titles = append(titles, result.Todo.Title)
+				}
+				return titles
+			}
+
+			result := search(SearchTodosRequest{Query: "dog"})
+			if got := titles(result); !slices.Equal(got, []string{"Walk the dog", "Call boss", "Buy food"}) && !slices.Equal(got, []string{"Walk the dog", "Buy food", "Call boss"}) {
+				t.Errorf("dog = %v, want the title match first and no trashed or foreign todos", got)
+			}
+			if first := result.Results[0]; first.TitleHighlight != "Walk the <mark>dog</mark>" || first.Rank <= result.Results[1].Rank {
+				t.Errorf("first result = %+v, want a highlighted title ranked above the rest", first)
+			}
+
+			result = search(SearchTodosRequest{Query: `walk* "dog food"`})
+			if got := titles(result); !slices.Equal(got, []string{"Buy food"}) {
+				t.Errorf("prefix and phrase = %v, want [Buy food]", got)
+			}
+			if snippet := result.Results[0].Snippet; snippet != "<mark>Dog food</mark> and a &lt;new&gt; <mark>walk</mark>ing lead" {
+				t.Errorf("snippet = %q, want the description highlighted and escaped", snippet)
+			}
+
+			if got := titles(search(SearchTodosRequest{Query: "dog", Priority: []string{"P1"}})); !slices.Equal(got, []string{"Walk the dog"}) {
+				t.Errorf("dog in P1 = %v, want [Walk the dog]", got)
+			}
+			if got := titles(search(SearchTodosRequest{Query: "cat"})); len(got) != 0 {
+				t.Errorf("cat = %v, want the trashed todo left out", got)
+			}
+
+			page := search(SearchTodosRequest{Query: "dog", Limit: 2})
+			if len(page.Results) != 2 || !page.Meta.HasMore {
+				t.Errorf("first page = %d results has more %v, want 2 and more", len(page.Results), page.Meta.HasMore)
+			}
+			page = search(SearchTodosRequest{Query: "dog", Limit: 2, Offset: 2})
+			if len(page.Results) != 1 || page.Meta.HasMore {
+				t.Errorf("last page = %d results has more %v, want 1 and no more", len(page.Results), page.Meta.HasMore)
+			}
+
+			if response := service.Search(ctx, userID, SearchTodosRequest{Query: `"" *`}); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("empty query status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+		})
+	}
+}
@@ -2,64 +2,313 @@ package todo
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/config"
 	"github.com/Alfian57/golang-todo/pkg/logger"
 	"github.com/Alfian57/golang-todo/pkg/utils"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type TodoService struct {
 	todoRepository TodoRepository
 	log            logger.Logger
 	isDebug        bool
+
+	// maxSubtaskDepth is how deep subtasks can be nested below a top level todo
+	maxSubtaskDepth int
+
+	// now is the clock that relative due date filters are resolved against
+	now func() time.Time
+
+	// undoWindow is how long an undo token stays valid, undo collects the before-images of an undoable change
+	undoWindow time.Duration
+	undo       *undoLog
 }
 
-func NewTodoService(todoRepository TodoRepository, log logger.Logger, isDebug bool) TodoService {
+func NewTodoService(todoRepository TodoRepository, cfg config.TodoConfig, log logger.Logger, isDebug bool) TodoService {
 	return TodoService{
-		todoRepository: todoRepository,
-		log:            log,
-		isDebug:        isDebug,
+		todoRepository:  todoRepository,
+		log:             log,
+		isDebug:         isDebug,
+		maxSubtaskDepth: cfg.MaxSubtaskDepth,
+		now:             time.Now,
+		undoWindow:      cfg.UndoWindow,
 	}
 }
 
-func (service TodoService) GetAll(ctx context.Context, userID uuid.UUID) models.Response {
-	todos, err := service.todoRepository.FindAllTodoByUserID(ctx, userID.String())
+func (service TodoService) GetAll(ctx context.Context, userID uuid.UUID, req GetTodosRequest) models.Response {
+	return service.list(ctx, userID, req, false, "Get all todos")
+}
+
+// GetTrash lists the todos in the user's trash, with the same filters and pagination as GetAll
+func (service TodoService) GetTrash(ctx context.Context, userID uuid.UUID, req GetTodosRequest) models.Response {
+	return service.list(ctx, userID, req, true, "Get trashed todos")
+}
+
+func (service TodoService) list(ctx context.Context, userID uuid.UUID, req GetTodosRequest, trashed bool, operation string) models.Response {
+	filter, err := NewTodoFilter(req, service.now())
+	if errors.Is(err, ErrInvalidDueWithin) {
+		return utils.UnprocessableEntityResponse("Invalid due_within", err, service.isDebug)
+	}
+	if errors.Is(err, ErrInvalidPriority) {
+		return utils.UnprocessableEntityResponse("Invalid priority", err, service.isDebug)
+	}
+	if errors.Is(err, ErrInvalidProject) {
+		return utils.UnprocessableEntityResponse("Invalid project", err, service.isDebug)
+	}
+	if err != nil {
+		service.log.Debug("Invalid todo list cursor",
+			logger.F("operation", operation),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.UnprocessableEntityResponse("Invalid cursor", err, service.isDebug)
+	}
+	filter.Trashed = trashed
+
+	todos, err := service.todoRepository.FindAllTodoByUserID(ctx, userID.String(), filter)
 	if err != nil {
 		service.log.Error("Failed to get todos",
-			logger.F("operation", "Get all todos"),
+			logger.F("operation", operation),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get todos", err, service.isDebug)
+	}
+
+	total, err := service.todoRepository.CountTodoByUserID(ctx, userID.String(), filter)
+	if err != nil {
+		service.log.Error("Failed to count todos",
+			logger.F("operation", operation),
 			logger.F("user_id", userID.String()),
 			logger.F("error", err),
 		)
 		return utils.InternalServerErrorResponse("Failed to get todos", err, service.isDebug)
 	}
 
+	// The repository fetches one extra row to tell whether another page exists
+	meta := PaginationMeta{
+		Limit: filter.Limit,
+		Total: total,
+	}
+	if len(todos) > filter.Limit {
+		todos = todos[:filter.Limit]
+		meta.HasMore = true
+		meta.NextCursor = NewTodoCursor(todos[len(todos)-1], filter.Sort).Encode()
+	}
+
 	todosResponse := make([]TodoResponse, 0, len(todos))
 	for _, todo := range todos {
-		todoResponse := TodoResponse{
-			Id:          todo.ID,
-			Title:       todo.Title,
-			Description: todo.Description,
-			Completed:   todo.Completed,
-			CreatedAt:   todo.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
-		todosResponse = append(todosResponse, todoResponse)
+		todosResponse = append(todosResponse, NewTodoResponse(todo))
 	}
 	responseData := GetTodosResponse{
 		Todos: todosResponse,
+		Meta:  meta,
 	}
 	return utils.OkResponse("Todos retrieved successfully", responseData)
 }
 
-func (service TodoService) Create(ctx context.Context, title string, description string, userID uuid.UUID) models.Response {
+// Get returns a todo of the user with its checklist, expanded with its tags, tree of subtasks and status history on request
+func (service TodoService) Get(ctx context.Context, todoID uuid.UUID, req GetTodoRequest, userID uuid.UUID) models.Response {
+	includes, err := ParseTodoIncludes(req.Include)
+	if err != nil {
+		return utils.UnprocessableEntityResponse("Unknown include", err, service.isDebug)
+	}
+
+	todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find todo",
+			logger.F("operation", "Get todo"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found", err, service.isDebug)
+	}
+
+	detail, err := service.todoDetail(ctx, *todo, includes, userID)
+	if err != nil {
+		service.log.Error("Failed to expand todo",
+			logger.F("operation", "Get todo"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get todo", err, service.isDebug)
+	}
+
+	responseData := GetTodoResponse{
+		Todo: detail,
+	}
+	return utils.OkResponse("Todo retrieved successfully", responseData)
+}
+
+// todoDetail returns the todo with its checklist and the whole tree of its subtasks, expanded with what includes asks for
+func (service TodoService) todoDetail(ctx context.Context, todo Todo, includes TodoIncludes, userID uuid.UUID) (TodoDetailResponse, error) {
+	tree, err := service.todoTree(ctx, todo, userID)
+	if err != nil {
+		return TodoDetailResponse{}, err
+	}
+	detail := TodoDetailResponse{
+		TodoTreeResponse: tree,
+	}
+	if includes.History {
+		// Older changes are paged through GET /todo/{id}/history, the repository fetches one extra record for that
+		records, err := service.todoRepository.FindTodoHistoryByUserID(ctx, userID, HistoryFilter{
+			TodoID: &todo.ID,
+			Limit:  defaultHistoryLimit,
+		})
+		if err != nil {
+			return detail, err
+		}
+		history := make([]TodoHistoryResponse, 0, len(records))
+		for _, record := range records[:min(len(records), defaultHistoryLimit)] {
+			history = append(history, NewTodoHistoryResponse(record))
+		}
+		detail.History = &history
+	}
+	return detail, nil
+}
+
+// Upcoming lists the incomplete todos due in the next days, grouped by their day in the request's time zone
+func (service TodoService) Upcoming(ctx context.Context, userID uuid.UUID, req GetUpcomingTodosRequest) models.Response {
+	days := req.Days
+	if days == 0 {
+		days = defaultUpcomingDays
+	}
+	loc := LoadLocation(req.Timezone)
+	now := service.now()
+
+	todos, err := service.todoRepository.FindDueTodosByUserID(ctx, userID.String(), DaysWindow(now, loc, days))
+	if err != nil {
+		service.log.Error("Failed to get upcoming todos",
+			logger.F("operation", "Get upcoming todos"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get upcoming todos", err, service.isDebug)
+	}
+
+	// Every day of the range is listed, even without todos, so clients can render a planner
+	responseData := GetUpcomingTodosResponse{
+		Timezone: loc.String(),
+		Days:     make([]UpcomingDay, 0, days),
+	}
+	today := calendarDate(now, loc)
+	byDate := make(map[string]int, days)
+	for i := 0; i < days; i++ {
+		date := today.AddDate(0, 0, i).Format(time.DateOnly)
+		byDate[date] = i
+		responseData.Days = append(responseData.Days, UpcomingDay{Date: date, Todos: []TodoResponse{}})
+	}
+
+	// All-day todos come first in their day, then timed ones in order
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].AllDay && !todos[j].AllDay
+	})
+	for _, todo := range todos {
+		date := todo.DueAt.In(loc).Format(time.DateOnly)
+		if todo.AllDay {
+			date = todo.DueAt.UTC().Format(time.DateOnly)
+		}
+		if i, ok := byDate[date]; ok {
+			responseData.Days[i].Todos = append(responseData.Days[i].Todos, NewTodoResponse(todo))
+		}
+	}
+
+	return utils.OkResponse("Upcoming todos retrieved successfully", responseData)
+}
+
+func (service TodoService) Create(ctx context.Context, req CreateTodoRequest, userID uuid.UUID) models.Response {
+	workflow, err := service.workflow(ctx, userID)
+	if err != nil {
+		service.log.Error("Failed to get workflow",
+			logger.F("operation", "Create todo"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to create todo", err, service.isDebug)
+	}
+
+	status := workflow.InitialStatus()
+	if req.Status != "" {
+		var ok bool
+		if status, ok = workflow.Status(req.Status); !ok {
+			return utils.UnprocessableEntityResponse("Unknown status", ErrUnknownStatus, service.isDebug)
+		}
+	}
+	priority := defaultPriority
+	if req.Priority != "" {
+		if priority, err = ParsePriority(req.Priority); err != nil {
+			return utils.UnprocessableEntityResponse("Invalid priority", err, service.isDebug)
+		}
+	}
+	projectID := req.ProjectID
+	if req.ParentID != nil {
+		parent, response, ok := service.checkParent(ctx, *req.ParentID, nil, userID, "Create todo")
+		if !ok {
+			return response
+		}
+		// Subtasks are kept in the project of their parent unless told otherwise
+		if projectID == nil {
+			projectID = parent.ProjectID
+		}
+	}
+	if req.ProjectID != nil {
+		if response, ok := service.checkProject(ctx, *req.ProjectID, userID, "Create todo"); !ok {
+			return response
+		}
+	}
+	tags, err := service.resolveTags(ctx, userID, req.TagIDs, req.TagNames)
+	if errors.Is(err, ErrUnknownTag) {
+		return utils.UnprocessableEntityResponse("Unknown tag", err, service.isDebug)
+	}
+	if err != nil {
+		service.log.Error("Failed to resolve tags",
+			logger.F("operation", "Create todo"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to create todo", err, service.isDebug)
+	}
+
 	todo := Todo{
-		Title:       title,
-		Description: description,
-		Completed:   false,
-		UserID:      userID,
+		Title:        req.Title,
+		Description:  req.Description,
+		Completed:    status.Done,
+		UserID:       userID,
+		DueAt:        normalizeDue(req.DueAt, req.AllDay),
+		AllDay:       req.AllDay,
+		RemindAt:     toUTC(req.RemindAt),
+		Priority:     priority,
+		Status:       status.Key,
+		Tags:         tags,
+		ProjectID:    projectID,
+		ParentID:     req.ParentID,
+		AutoComplete: req.AutoComplete,
+		Timezone:     req.Timezone,
 	}
-	err := service.todoRepository.CreateTodo(ctx, &todo)
+	if req.Recurrence != "" {
+		if err := applyRecurrence(&todo, &req.Recurrence, nil); err != nil {
+			return service.recurrenceErrorResponse(err)
+		}
+	}
+	err = service.inTransaction(ctx, func(service TodoService) error {
+		if err := service.todoRepository.CreateTodo(ctx, &todo); err != nil {
+			return err
+		}
+		if err := service.recordHistory(ctx, HistoryCreate, Todo{}, todo, userID); err != nil {
+			return err
+		}
+		service.rollUp(ctx, &todo, userID, "Create todo")
+		return nil
+	})
 	if err != nil {
 		service.log.Error("Failed to create todo",
 			logger.F("operation", "Create todo"),
@@ -68,20 +317,15 @@ func (service TodoService) Create(ctx context.Context, title string, description
 		)
 		return utils.InternalServerErrorResponse("Failed to create todo", err, service.isDebug)
 	}
+	service.undo.add(nil, todo, false)
 
 	responseData := CreateTodoResponse{
-		Todo: TodoResponse{
-			Id:          todo.ID,
-			Title:       todo.Title,
-			Description: todo.Description,
-			Completed:   todo.Completed,
-			CreatedAt:   todo.CreatedAt.Format(time.RFC3339),
-		},
+		Todo: NewTodoResponse(todo),
 	}
 	return utils.CreatedResponse("Success to create todo", responseData)
 }
 
-func (service TodoService) Update(ctx context.Context, todoID uuid.UUID, title string, description string, completed bool, userID uuid.UUID) models.Response {
+func (service TodoService) Update(ctx context.Context, todoID uuid.UUID, req UpdateTodoRequest, userID uuid.UUID) models.Response {
 	todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
 	if err != nil {
 		service.log.Error("Failed to find todo",
@@ -92,35 +336,164 @@ func (service TodoService) Update(ctx context.Context, todoID uuid.UUID, title s
 		)
 		return utils.NotFoundResponse("Todo not found", err, service.isDebug)
 	}
+	if !ifMatches(todo, req.IfMatch) {
+		return utils.PreconditionFailedResponse("Todo has been changed", ErrVersionConflict, service.isDebug)
+	}
+
+	return service.undoable(ctx, userID, "Update todo", func(service TodoService) models.Response {
+		return service.update(ctx, todo, req, userID, "Update todo")
+	})
+}
 
-	todo.Title = title
-	todo.Description = description
-	todo.Completed = completed
+// ifMatches reports whether the todo has an ETag listed by the If-Match header, which passes when the request has none
+func ifMatches(todo *Todo, ifMatch string) bool {
+	return ifMatch == "" || utils.MatchETag(ifMatch, todo.ETag(), false)
+}
 
-	err = service.todoRepository.UpdateTodo(ctx, todo)
+// update sets the fields of the request on the todo and saves the columns that changed
+func (service TodoService) update(ctx context.Context, todo *Todo, req UpdateTodoRequest, userID uuid.UUID, operation string) models.Response {
+	todoID := todo.ID
+	workflow, err := service.workflow(ctx, userID)
+	if err != nil {
+		service.log.Error("Failed to get workflow",
+			logger.F("operation", operation),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to update todo", err, service.isDebug)
+	}
+
+	before := *todo
+	before.SkippedDates = slices.Clone(todo.SkippedDates)
+
+	// Clients that only know about completed move the todo to the first done or open status
+	target := todo.Status
+	if req.Status != "" {
+		target = req.Status
+	} else if req.Completed != todo.Completed {
+		target = workflow.InitialStatus().Key
+		if req.Completed {
+			target = workflow.DoneStatus().Key
+		}
+	}
+	status, ok := workflow.Status(target)
+	if !ok {
+		return utils.UnprocessableEntityResponse("Unknown status", ErrUnknownStatus, service.isDebug)
+	}
+	if !workflow.CanTransition(todo.Status, status.Key) {
+		return utils.UnprocessableEntityResponse("Status transition not allowed", ErrInvalidTransition, service.isDebug)
+	}
+	if req.Priority != "" {
+		if todo.Priority, err = ParsePriority(req.Priority); err != nil {
+			return utils.UnprocessableEntityResponse("Invalid priority", err, service.isDebug)
+		}
+	}
+	// Tags are only replaced when the request sends them, an empty list clears them
+	if req.TagIDs != nil || req.TagNames != nil {
+		todo.Tags, err = service.resolveTags(ctx, userID, req.TagIDs, req.TagNames)
+		if errors.Is(err, ErrUnknownTag) {
+			return utils.UnprocessableEntityResponse("Unknown tag", err, service.isDebug)
+		}
+		if err != nil {
+			service.log.Error("Failed to resolve tags",
+				logger.F("operation", operation),
+				logger.F("todo_id", todoID.String()),
+				logger.F("user_id", userID.String()),
+				logger.F("error", err),
+			)
+			return utils.InternalServerErrorResponse("Failed to update todo", err, service.isDebug)
+		}
+	}
+
+	previousStatus := todo.Status
+	wasCompleted := todo.Completed
+	todo.Title = req.Title
+	todo.Description = req.Description
+	todo.Completed = status.Done
+	todo.Status = status.Key
+	todo.DueAt = normalizeDue(req.DueAt, req.AllDay)
+	todo.AllDay = req.AllDay
+	todo.RemindAt = toUTC(req.RemindAt)
+	todo.AutoComplete = req.AutoComplete
+	if err := applyRecurrence(todo, req.Recurrence, req.Timezone); err != nil {
+		return service.recurrenceErrorResponse(err)
+	}
+
+	// Completing an occurrence of a recurring todo hands its rule on to the next instance
+	var next *Todo
+	if todo.Completed && !wasCompleted && todo.Recurrence != "" {
+		next = nextInstance(todo, workflow.InitialStatus(), service.now())
+	}
+
+	if next != nil {
+		todo.Recurrence = ""
+	}
+	columns := changedColumns(before, *todo)
+	err = service.inTransaction(ctx, func(service TodoService) error {
+		var err error
+		if next != nil {
+			err = service.todoRepository.CompleteOccurrence(ctx, todo, &TodoTransition{
+				TodoID:     todo.ID,
+				UserID:     userID,
+				FromStatus: previousStatus,
+				ToStatus:   todo.Status,
+			}, next, columns...)
+		} else if previousStatus != todo.Status {
+			err = service.todoRepository.TransitionTodo(ctx, todo, &TodoTransition{
+				TodoID:     todo.ID,
+				UserID:     userID,
+				FromStatus: previousStatus,
+				ToStatus:   todo.Status,
+			}, columns...)
+		} else if len(columns) > 0 {
+			err = service.todoRepository.UpdateTodo(ctx, todo, columns...)
+		}
+		if err != nil {
+			return err
+		}
+		if err := service.recordHistory(ctx, HistoryUpdate, before, *todo, userID); err != nil {
+			return err
+		}
+		if next != nil {
+			if err := service.recordHistory(ctx, HistoryCreate, Todo{}, *next, userID); err != nil {
+				return err
+			}
+		}
+		service.rollUp(ctx, todo, userID, operation)
+		return nil
+	})
+	if errors.Is(err, ErrVersionConflict) {
+		return utils.PreconditionFailedResponse("Todo has been changed", err, service.isDebug)
+	}
 	if err != nil {
 		service.log.Error("Failed to update todo",
-			logger.F("operation", "Update todo"),
+			logger.F("operation", operation),
 			logger.F("todo_id", todoID.String()),
 			logger.F("user_id", userID.String()),
 			logger.F("error", err),
 		)
 		return utils.InternalServerErrorResponse("Failed to update todo", err, service.isDebug)
 	}
+	if len(columns) > 0 {
+		service.undo.add(&before, *todo, false)
+	}
+	if next != nil {
+		service.undo.add(nil, *next, false)
+	}
 
 	responseData := UpdateTodoResponse{
-		Todo: TodoResponse{
-			Id:          todo.ID,
-			Title:       todo.Title,
-			Description: todo.Description,
-			Completed:   todo.Completed,
-			CreatedAt:   todo.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		},
+		Todo: NewTodoResponse(*todo),
+	}
+	if next != nil {
+		nextResponse := NewTodoResponse(*next)
+		responseData.Next = &nextResponse
 	}
 	return utils.OkResponse("Todo updated successfully", responseData)
 }
 
-func (service TodoService) Delete(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) models.Response {
+// Delete moves a todo to the trash
+// Its subtasks go to the trash with it, or with req.Subtasks set to reparent move up to the todo's parent
+func (service TodoService) Delete(ctx context.Context, todoID uuid.UUID, req DeleteTodoRequest, userID uuid.UUID) models.Response {
 	todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
 	if err != nil {
 		service.log.Error("Failed to find todo",
@@ -131,17 +504,166 @@ func (service TodoService) Delete(ctx context.Context, todoID uuid.UUID, userID
 		)
 		return utils.NotFoundResponse("Todo not found", err, service.isDebug)
 	}
+	if !ifMatches(todo, req.IfMatch) {
+		return utils.PreconditionFailedResponse("Todo has been changed", ErrVersionConflict, service.isDebug)
+	}
+
+	subtasks := req.Subtasks
+	if subtasks == "" {
+		subtasks = SubtasksCascade
+	}
+	return service.undoable(ctx, userID, "Delete todo", func(service TodoService) models.Response {
+		return service.trash(ctx, todo, subtasks, userID)
+	})
+}
 
-	err = service.todoRepository.DeleteTodo(ctx, todo)
+// trash moves a todo to the trash, with subtasks set to SubtasksCascade or SubtasksReparent
+func (service TodoService) trash(ctx context.Context, todo *Todo, subtasks string, userID uuid.UUID) models.Response {
+	// Reparented subtasks are remembered so an undo can move them back, the ones that go to the trash come back with the todo
+	var children []Todo
+	if subtasks == SubtasksReparent && service.undo != nil {
+		var err error
+		children, err = service.todoRepository.FindSubtasksByParentIDs(ctx, userID, []uuid.UUID{todo.ID})
+		if err != nil {
+			service.log.Error("Failed to find subtasks",
+				logger.F("operation", "Delete todo"),
+				logger.F("todo_id", todo.ID.String()),
+				logger.F("user_id", userID.String()),
+				logger.F("error", err),
+			)
+			return utils.InternalServerErrorResponse("Failed to delete todo", err, service.isDebug)
+		}
+	}
+
+	before := *todo
+	err := service.inTransaction(ctx, func(service TodoService) error {
+		changed, err := service.todoRepository.DeleteTodo(ctx, todo, subtasks)
+		if err != nil {
+			return err
+		}
+		if err := service.recordHistory(ctx, HistoryDelete, before, *todo, userID); err != nil {
+			return err
+		}
+		if subtasks != SubtasksReparent {
+			return service.recordSubtasksHistory(ctx, HistoryDelete, changed, userID)
+		}
+		// A subtask moved up to the parent is recorded as an update of its parent_id
+		for _, child := range changed {
+			moved := child
+			child.ParentID = &todo.ID
+			if err := service.recordHistory(ctx, HistoryUpdate, child, moved, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, ErrVersionConflict) {
+		return utils.PreconditionFailedResponse("Todo has been changed", err, service.isDebug)
+	}
 	if err != nil {
 		service.log.Error("Failed to delete todo",
 			logger.F("operation", "Delete todo"),
-			logger.F("todo_id", todoID.String()),
+			logger.F("todo_id", todo.ID.String()),
 			logger.F("user_id", userID.String()),
 			logger.F("error", err),
 		)
 		return utils.InternalServerErrorResponse("Failed to delete todo", err, service.isDebug)
 	}
+	// The todo comes after its subtasks, so an undo restores it before moving them back below it
+	for _, child := range children {
+		moved := child
+		moved.ParentID = todo.ParentID
+		moved.Version++
+		service.undo.add(&child, moved, false)
+	}
+	service.undo.add(&before, *todo, true)
+
+	return utils.OkResponse("Todo moved to trash", DeleteTodoResponse{})
+}
+
+func (service TodoService) Restore(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) models.Response {
+	todo, err := service.todoRepository.FindTrashedTodoByIDAndUserID(ctx, todoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find trashed todo",
+			logger.F("operation", "Restore todo"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found in trash", err, service.isDebug)
+	}
+
+	// A subtask whose parent is no longer around comes back as a top level todo
+	before := *todo
+	if todo.ParentID != nil {
+		_, err := service.todoRepository.FindTodoByIDAndUserID(ctx, *todo.ParentID, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			todo.ParentID = nil
+		} else if err != nil {
+			service.log.Error("Failed to find parent todo",
+				logger.F("operation", "Restore todo"),
+				logger.F("todo_id", todoID.String()),
+				logger.F("user_id", userID.String()),
+				logger.F("error", err),
+			)
+			return utils.InternalServerErrorResponse("Failed to restore todo", err, service.isDebug)
+		}
+	}
+
+	err = service.inTransaction(ctx, func(service TodoService) error {
+		restored, err := service.todoRepository.RestoreTodo(ctx, todo)
+		if err != nil {
+			return err
+		}
+		if err := service.recordHistory(ctx, HistoryRestore, before, *todo, userID); err != nil {
+			return err
+		}
+		return service.recordSubtasksHistory(ctx, HistoryRestore, restored, userID)
+	})
+	if err != nil {
+		service.log.Error("Failed to restore todo",
+			logger.F("operation", "Restore todo"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to restore todo", err, service.isDebug)
+	}
+
+	responseData := RestoreTodoResponse{
+		Todo: NewTodoResponse(*todo),
+	}
+	return utils.OkResponse("Todo restored successfully", responseData)
+}
+
+// Purge permanently deletes a todo from the trash
+func (service TodoService) Purge(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) models.Response {
+	todo, err := service.todoRepository.FindTrashedTodoByIDAndUserID(ctx, todoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find trashed todo",
+			logger.F("operation", "Purge todo"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found in trash", err, service.isDebug)
+	}
+
+	err = service.inTransaction(ctx, func(service TodoService) error {
+		if err := service.todoRepository.PurgeTodo(ctx, todo); err != nil {
+			return err
+		}
+		return service.recordHistory(ctx, HistoryPurge, *todo, *todo, userID)
+	})
+	if err != nil {
+		service.log.Error("Failed to purge todo",
+			logger.F("operation", "Purge todo"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to purge todo", err, service.isDebug)
+	}
 
-	return utils.OkResponse("Todo deleted successfully", nil)
+	return utils.OkResponse("Todo permanently deleted", nil)
 }
@@ -0,0 +1,440 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// testTodoConfig allows two levels of subtasks and undoing changes for a minute
+var testTodoConfig = config.TodoConfig{MaxSubtaskDepth: 2, UndoWindow: time.Minute}
+
+func newTestService(repository TodoRepository) TodoService {
+	return NewTodoService(repository, testTodoConfig, logger.NewNopLogger(), true)
+}
+
+// seedTodos creates todos for the user with increasing creation times
+func seedTodos(t *testing.T, repository TodoRepository, userID uuid.UUID, titles ...string) []Todo {
+	t.Helper()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	todos := make([]Todo, 0, len(titles))
+	for i, title := range titles {
+		todo := Todo{
+			Title:     title,
+			UserID:    userID,
+			Completed: i%2 == 1,
+			Priority:  defaultPriority,
+			Status:    "backlog",
+		}
+		if todo.Completed {
+			todo.Status = "done"
+		}
+		todo.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		todo.UpdatedAt = todo.CreatedAt
+		if err := repository.CreateTodo(context.Background(), &todo); err != nil {
+			t.Fatalf("failed to seed todo: %v", err)
+		}
+		todos = append(todos, todo)
+	}
+	return todos
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func titlesOf(response []TodoResponse) []string {
+	titles := make([]string, 0, len(response))
+	for _, todo := range response {
+		titles = append(titles, todo.Title)
+	}
+	return titles
+}
+
+func TestTodoServiceGetAll(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+
+	tests := []struct {
+		name       string
+		req        GetTodosRequest
+		wantStatus int
+		wantTitles []string
+		wantTotal  int64
+		wantMore   bool
+	}{
+		{
+			name:       "defaults to newest first",
+			req:        GetTodosRequest{},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Write report", "Buy milk", "Call mom", "Walk dog"},
+			wantTotal:  4,
+		},
+		{
+			name:       "sorts by title ascending",
+			req:        GetTodosRequest{Sort: "title"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Buy milk", "Call mom", "Walk dog", "Write report"},
+			wantTotal:  4,
+		},
+		{
+			name:       "limits the page and reports more",
+			req:        GetTodosRequest{Limit: 3, Sort: "created_at"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Walk dog", "Call mom", "Buy milk"},
+			wantTotal:  4,
+			wantMore:   true,
+		},
+		{
+			name:       "filters by completed",
+			req:        GetTodosRequest{Completed: boolPtr(true), Sort: "created_at"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Call mom", "Write report"},
+			wantTotal:  2,
+		},
+		{
+			name:       "filters by title substring case-insensitively",
+			req:        GetTodosRequest{Title: "WALK"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Walk dog"},
+			wantTotal:  1,
+		},
+		{
+			name: "filters by created range",
+			req: GetTodosRequest{
+				Sort:        "created_at",
+				CreatedFrom: timePtr(time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC)),
+				CreatedTo:   timePtr(time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC)),
+			},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"Call mom", "Buy milk"},
+			wantTotal:  2,
+		},
+		{
+			name:       "rejects a malformed cursor",
+			req:        GetTodosRequest{Cursor: "not-a-cursor"},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for name, newRepository := range testRepositories() {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				repository := newRepository(t)
+				service := newTestService(repository)
+				seedTodos(t, repository, userID, "Walk dog", "Call mom", "Buy milk", "Write report")
+				seedTodos(t, repository, otherUserID, "Someone else's todo")
+
+				response := service.GetAll(context.Background(), userID, tt.req)
+				if response.StatusCode != tt.wantStatus {
+					t.Fatalf("status = %d, want %d (%s)", response.StatusCode, tt.wantStatus, response.Message)
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				data := response.Data.(GetTodosResponse)
+				if got := titlesOf(data.Todos); !slices.Equal(got, tt.wantTitles) {
+					t.Errorf("titles = %v, want %v", got, tt.wantTitles)
+				}
+				if data.Meta.Total != tt.wantTotal {
+					t.Errorf("total = %d, want %d", data.Meta.Total, tt.wantTotal)
+				}
+				if data.Meta.HasMore != tt.wantMore || (data.Meta.NextCursor != "") != tt.wantMore {
+					t.Errorf("has_more = %v, next_cursor = %q, want more = %v", data.Meta.HasMore, data.Meta.NextCursor, tt.wantMore)
+				}
+			})
+		}
+	}
+}
+
+func TestTodoServiceGetAllWalksEveryPage(t *testing.T) {
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			repository := newRepository(t)
+			service := newTestService(repository)
+			userID := uuid.New()
+			seedTodos(t, repository, userID, "a", "b", "c", "d", "e")
+
+			var seen []string
+			req := GetTodosRequest{Limit: 2, Sort: "-created_at"}
+			for page := 0; page < 5; page++ {
+				response := service.GetAll(context.Background(), userID, req)
+				if response.StatusCode != http.StatusOK {
+					t.Fatalf("status = %d (%s)", response.StatusCode, response.Message)
+				}
+				data := response.Data.(GetTodosResponse)
+				seen = append(seen, titlesOf(data.Todos)...)
+				if !data.Meta.HasMore {
+					break
+				}
+				req.Cursor = data.Meta.NextCursor
+			}
+
+			if want := []string{"e", "d", "c", "b", "a"}; !slices.Equal(seen, want) {
+				t.Errorf("titles = %v, want %v", seen, want)
+			}
+
+			// A cursor issued for one ordering is refused for another
+			response := service.GetAll(context.Background(), userID, GetTodosRequest{Limit: 2, Sort: "-created_at"})
+			cursor := response.Data.(GetTodosResponse).Meta.NextCursor
+			response = service.GetAll(context.Background(), userID, GetTodosRequest{Cursor: cursor, Sort: "title"})
+			if response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+		})
+	}
+}
+
+func TestTodoServiceCreate(t *testing.T) {
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			repository := newRepository(t)
+			service := newTestService(repository)
+			userID := uuid.New()
+
+			response := service.Create(context.Background(), CreateTodoRequest{Title: "Walk dog", Description: "Around the block"}, userID)
+			if response.StatusCode != http.StatusCreated {
+				t.Fatalf("status = %d, want %d", response.StatusCode, http.StatusCreated)
+			}
+
+			created := response.Data.(CreateTodoResponse).Todo
+			if created.Title != "Walk dog" || created.Description != "Around the block" || created.Completed {
+				t.Errorf("unexpected todo %+v", created)
+			}
+
+			stored, err := repository.FindTodoByIDAndUserID(context.Background(), created.Id, userID)
+			if err != nil {
+				t.Fatalf("created todo not stored: %v", err)
+			}
+			if stored.UserID != userID {
+				t.Errorf("user_id = %s, want %s", stored.UserID, userID)
+			}
+		})
+	}
+}
+
+func TestTodoServiceUpdate(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		todoID     func(todo Todo) uuid.UUID
+		ifMatch    string
+		wantStatus int
+	}{
+		{
+			name:       "updates own todo",
+			userID:     userID,
+			todoID:     func(todo Todo) uuid.UUID { return todo.ID },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "hides todos of other users",
+			userID:     uuid.New(),
+			todoID:     func(todo Todo) uuid.UUID { return todo.ID },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "reports unknown todos",
+			userID:     userID,
+			todoID:     func(todo Todo) uuid.UUID { return uuid.New() },
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "updates while the todo has a listed ETag",
+			userID:     userID,
+			todoID:     func(todo Todo) uuid.UUID { return todo.ID },
+			ifMatch:    `"7", "1"`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "updates any version with a wildcard",
+			userID:     userID,
+			todoID:     func(todo Todo) uuid.UUID { return todo.ID },
+			ifMatch:    "*",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects a changed todo",
+			userID:     userID,
+			todoID:     func(todo Todo) uuid.UUID { return todo.ID },
+			ifMatch:    `"2"`,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "rejects weak ETags",
+			userID:     userID,
+			todoID:     func(todo Todo) uuid.UUID { return todo.ID },
+			ifMatch:    `W/"1"`,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for name, newRepository := range testRepositories() {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				repository := newRepository(t)
+				service := newTestService(repository)
+				todo := seedTodos(t, repository, userID, "Walk dog")[0]
+
+				response := service.Update(context.Background(), tt.todoID(todo), UpdateTodoRequest{Title: "Walk cat", Completed: true, IfMatch: tt.ifMatch}, tt.userID)
+				if response.StatusCode != tt.wantStatus {
+					t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+				}
+
+				stored, _ := repository.FindTodoByIDAndUserID(context.Background(), todo.ID, userID)
+				wantTitle := "Walk dog"
+				if tt.wantStatus == http.StatusOK {
+					wantTitle = "Walk cat"
+				}
+				if stored.Title != wantTitle {
+					t.Errorf("stored title = %q, want %q", stored.Title, wantTitle)
+				}
+				if tt.wantStatus == http.StatusOK && response.Data.(UpdateTodoResponse).Todo.Version != todo.Version+1 {
+					t.Errorf("version = %d, want %d", response.Data.(UpdateTodoResponse).Todo.Version, todo.Version+1)
+				}
+			})
+		}
+	}
+}
+
+func TestTodoServiceDelete(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		ifMatch    string
+		wantStatus int
+		wantKept   bool
+	}{
+		{name: "deletes own todo", userID: userID, wantStatus: http.StatusOK},
+		{name: "hides todos of other users", userID: uuid.New(), wantStatus: http.StatusNotFound, wantKept: true},
+		{name: "deletes while the todo has the ETag", userID: userID, ifMatch: `"1"`, wantStatus: http.StatusOK},
+		{name: "keeps a changed todo", userID: userID, ifMatch: `"2"`, wantStatus: http.StatusPreconditionFailed, wantKept: true},
+	}
+
+	for name, newRepository := range testRepositories() {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				repository := newRepository(t)
+				service := newTestService(repository)
+				todo := seedTodos(t, repository, userID, "Walk dog")[0]
+
+				response := service.Delete(context.Background(), todo.ID, DeleteTodoRequest{IfMatch: tt.ifMatch}, tt.userID)
+				if response.StatusCode != tt.wantStatus {
+					t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+				}
+
+				_, err := repository.FindTodoByIDAndUserID(context.Background(), todo.ID, userID)
+				if kept := err == nil; kept != tt.wantKept {
+					t.Errorf("todo kept = %v, want %v", kept, tt.wantKept)
+				}
+			})
+		}
+	}
+}
+
+func TestTodoServiceTrash(t *testing.T) {
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+			userID := uuid.New()
+			todos := seedTodos(t, repository, userID, "Walk dog", "Call mom")
+
+			if response := service.Delete(ctx, todos[0].ID, DeleteTodoRequest{}, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("delete status = %d (%s)", response.StatusCode, response.Message)
+			}
+
+			// The deleted todo leaves the listing and shows up in the trash
+			active := service.GetAll(ctx, userID, GetTodosRequest{}).Data.(GetTodosResponse)
+			if got := titlesOf(active.Todos); !slices.Equal(got, []string{"Call mom"}) || active.Meta.Total != 1 {
+				t.Errorf("active titles = %v (total %d), want [Call mom]", got, active.Meta.Total)
+			}
+			trash := service.GetTrash(ctx, userID, GetTodosRequest{}).Data.(GetTodosResponse)
+			if got := titlesOf(trash.Todos); !slices.Equal(got, []string{"Walk dog"}) || trash.Meta.Total != 1 {
+				t.Fatalf("trash titles = %v (total %d), want [Walk dog]", got, trash.Meta.Total)
+			}
+			if trash.Todos[0].DeletedAt == nil {
+				t.Errorf("trashed todo has no deleted_at")
+			}
+
+			// Deleting it again finds nothing, the trash is not deleted twice
+			if response := service.Delete(ctx, todos[0].ID, DeleteTodoRequest{}, userID); response.StatusCode != http.StatusNotFound {
+				t.Errorf("second delete status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+
+			response := service.Restore(ctx, todos[0].ID, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("restore status = %d (%s)", response.StatusCode, response.Message)
+			}
+			if restored := response.Data.(RestoreTodoResponse).Todo; restored.DeletedAt != nil {
+				t.Errorf("restored todo still has deleted_at %s", *restored.DeletedAt)
+			}
+			if _, err := repository.FindTodoByIDAndUserID(ctx, todos[0].ID, userID); err != nil {
+				t.Errorf("restored todo not found: %v", err)
+			}
+
+			service.Delete(ctx, todos[0].ID, DeleteTodoRequest{}, userID)
+			if response := service.Purge(ctx, todos[0].ID, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("purge status = %d (%s)", response.StatusCode, response.Message)
+			}
+			if response := service.Restore(ctx, todos[0].ID, userID); response.StatusCode != http.StatusNotFound {
+				t.Errorf("restore after purge status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+			trash = service.GetTrash(ctx, userID, GetTodosRequest{}).Data.(GetTodosResponse)
+			if len(trash.Todos) != 0 {
+				t.Errorf("trash titles = %v, want none", titlesOf(trash.Todos))
+			}
+		})
+	}
+}
+
+func TestTodoServiceRestoreAndPurgeOnlyTouchTheTrash(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		trashed bool
+		userID  uuid.UUID
+	}{
+		{name: "active todo", trashed: false, userID: userID},
+		{name: "trashed todo of another user", trashed: true, userID: uuid.New()},
+	}
+
+	for name, newRepository := range testRepositories() {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				repository := newRepository(t)
+				service := newTestService(repository)
+				todo := seedTodos(t, repository, userID, "Walk dog")[0]
+				if tt.trashed {
+					service.Delete(ctx, todo.ID, DeleteTodoRequest{}, userID)
+				}
+
+				if response := service.Restore(ctx, todo.ID, tt.userID); response.StatusCode != http.StatusNotFound {
+					t.Errorf("restore status = %d, want %d", response.StatusCode, http.StatusNotFound)
+				}
+				if response := service.Purge(ctx, todo.ID, tt.userID); response.StatusCode != http.StatusNotFound {
+					t.Errorf("purge status = %d, want %d", response.StatusCode, http.StatusNotFound)
+				}
+			})
+		}
+	}
+}
+
+func timePtr(value time.Time) *time.Time {
+	return &value
+}
@@ -0,0 +1,55 @@
+package todo
+
+import (
+	"errors"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/google/uuid"
+)
+
+// What happens to the subtasks of a deleted todo
+const (
+	// SubtasksCascade moves the subtasks to the trash along with their parent
+	SubtasksCascade = "cascade"
+
+	// SubtasksReparent moves the subtasks up to the parent of the deleted todo
+	SubtasksReparent = "reparent"
+)
+
+var (
+	ErrUnknownParent        = errors.New("parent todo does not exist")
+	ErrSubtaskCycle         = errors.New("a todo cannot be a subtask of itself or of its subtasks")
+	ErrSubtaskTooDeep       = errors.New("subtasks are nested too deeply")
+	ErrUnknownChecklistItem = errors.New("checklist item does not exist")
+)
+
+// ChecklistItem is a lightweight step of a todo, kept in the order of its position
+type ChecklistItem struct {
+	models.Base
+	TodoID   uuid.UUID
+	Text     string
+	Done     bool
+	Position int
+}
+
+// SubtaskProgress counts the done and total direct subtasks of a todo
+type SubtaskProgress struct {
+	Done  int
+	Total int
+}
+
+// progressOf counts how many of the subtasks are completed
+func progressOf(subtasks []Todo) SubtaskProgress {
+	progress := SubtaskProgress{Total: len(subtasks)}
+	for _, subtask := range subtasks {
+		if subtask.Completed {
+			progress.Done++
+		}
+	}
+	return progress
+}
+
+// AllDone reports whether the todo has subtasks and every one of them is completed
+func (progress SubtaskProgress) AllDone() bool {
+	return progress.Total > 0 && progress.Done == progress.Total
+}
@@ -0,0 +1,75 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      Set todo parent
+// @Description  Nest a todo of the authenticated user below another of their todos, or move it to the top level when parent_id is null
+// @Tags         Todo
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string            true  "Todo ID"
+// @Param        body  body      SetParentRequest  true  "Set Parent Request"
+// @Param        If-Match  header  string  false  "ETag of the todo, the request fails with 412 once the todo has changed"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.SetParentResponse}
+// @Header       200  {string}  ETag  "ETag of the todo"
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      412  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/parent [put]
+func (handler TodoHandler) SetParent(ctx *gin.Context) {
+	var req SetParentRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+	req.IfMatch = ctx.GetHeader("If-Match")
+
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Set todo parent"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Set todo parent"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.SetParent(ctx, todoID, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Set todo parent request failed",
+			logger.F("operation", "Set todo parent"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	if data, ok := response.Data.(SetParentResponse); ok {
+		ctx.Header("ETag", utils.VersionETag(data.Todo.Version))
+	}
+	ctx.JSON(response.StatusCode, response)
+}
@@ -0,0 +1,275 @@
+package todo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// checkParent makes sure a todo can be nested under the parent
+// todo is nil for a todo that is being created, otherwise it may not end up below itself
+// It returns the parent, or the error response to send when the todo can't be nested
+func (service TodoService) checkParent(ctx context.Context, parentID uuid.UUID, todo *Todo, userID uuid.UUID, operation string) (*Todo, models.Response, bool) {
+	parent, err := service.todoRepository.FindTodoByIDAndUserID(ctx, parentID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.UnprocessableEntityResponse("Unknown parent todo", ErrUnknownParent, service.isDebug), false
+	}
+	if err != nil {
+		service.log.Error("Failed to find parent todo",
+			logger.F("operation", operation),
+			logger.F("parent_id", parentID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return nil, utils.InternalServerErrorResponse("Failed to find parent todo", err, service.isDebug), false
+	}
+
+	depth, err := service.nestingDepth(ctx, parent, todo, userID)
+	if err == nil && todo != nil {
+		var height int
+		height, err = service.subtaskHeight(ctx, todo, userID)
+		depth += height
+	}
+	if errors.Is(err, ErrSubtaskCycle) {
+		return nil, utils.UnprocessableEntityResponse("A todo cannot be moved below itself", err, service.isDebug), false
+	}
+	if err != nil {
+		service.log.Error("Failed to find the subtask depth",
+			logger.F("operation", operation),
+			logger.F("parent_id", parentID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return nil, utils.InternalServerErrorResponse("Failed to find parent todo", err, service.isDebug), false
+	}
+	if depth > service.maxSubtaskDepth {
+		return nil, utils.UnprocessableEntityResponse("Subtasks are nested too deeply", ErrSubtaskTooDeep, service.isDebug), false
+	}
+
+	return parent, models.Response{}, true
+}
+
+// nestingDepth returns the depth a subtask of the parent has, where top level todos have depth 0
+// It fails with ErrSubtaskCycle when todo is the parent or one of its ancestors
+func (service TodoService) nestingDepth(ctx context.Context, parent *Todo, todo *Todo, userID uuid.UUID) (int, error) {
+	depth := 1
+	for ancestor := parent; ; depth++ {
+		if todo != nil && ancestor.ID == todo.ID {
+			return 0, ErrSubtaskCycle
+		}
+		// Stored todos are never nested deeper than the limit, so this also stops on corrupt data
+		if ancestor.ParentID == nil || depth > service.maxSubtaskDepth {
+			return depth, nil
+		}
+
+		var err error
+		ancestor, err = service.todoRepository.FindTodoByIDAndUserID(ctx, *ancestor.ParentID, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return depth, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// subtaskHeight returns how many levels of subtasks the todo has below it
+func (service TodoService) subtaskHeight(ctx context.Context, todo *Todo, userID uuid.UUID) (int, error) {
+	height := 0
+	for parentIDs := []uuid.UUID{todo.ID}; ; height++ {
+		subtasks, err := service.todoRepository.FindSubtasksByParentIDs(ctx, userID, parentIDs)
+		if err != nil || len(subtasks) == 0 {
+			return height, err
+		}
+
+		parentIDs = parentIDs[:0]
+		for _, subtask := range subtasks {
+			parentIDs = append(parentIDs, subtask.ID)
+		}
+	}
+}
+
+// autoComplete rolls the completion of a todo up to its parents
+// A parent that auto completes moves to the first done status of the workflow once all of its subtasks are done,
+// which may in turn complete its own parent
+func (service TodoService) autoComplete(ctx context.Context, todo *Todo, workflow Workflow, userID uuid.UUID) error {
+	for todo.Completed && todo.ParentID != nil {
+		parent, err := service.todoRepository.FindTodoByIDAndUserID(ctx, *todo.ParentID, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if parent.Completed || !parent.AutoComplete {
+			return nil
+		}
+
+		subtasks, err := service.todoRepository.FindSubtasksByParentIDs(ctx, userID, []uuid.UUID{parent.ID})
+		if err != nil {
+			return err
+		}
+		done := workflow.DoneStatus()
+		if !progressOf(subtasks).AllDone() || !workflow.CanTransition(parent.Status, done.Key) {
+			return nil
+		}
+
+		transition := &TodoTransition{
+			TodoID:     parent.ID,
+			UserID:     userID,
+			FromStatus: parent.Status,
+			ToStatus:   done.Key,
+		}
+		before := *parent
+		parent.Status = done.Key
+		parent.Completed = true
+		if err := service.todoRepository.TransitionTodo(ctx, parent, transition, "status", "completed"); err != nil {
+			return err
+		}
+		if err := service.recordHistory(ctx, HistoryUpdate, before, *parent, userID); err != nil {
+			return err
+		}
+		service.undo.add(&before, *parent, false)
+		todo = parent
+	}
+	return nil
+}
+
+// todoTree loads the subtasks below the todo level by level, along with the checklists of the whole tree
+func (service TodoService) todoTree(ctx context.Context, todo Todo, userID uuid.UUID) (TodoTreeResponse, error) {
+	ids := []uuid.UUID{todo.ID}
+	subtasks := make(map[uuid.UUID][]Todo)
+	for parentIDs := ids; len(parentIDs) > 0; {
+		level, err := service.todoRepository.FindSubtasksByParentIDs(ctx, userID, parentIDs)
+		if err != nil {
+			return TodoTreeResponse{}, err
+		}
+
+		parentIDs = make([]uuid.UUID, 0, len(level))
+		for _, subtask := range level {
+			subtasks[*subtask.ParentID] = append(subtasks[*subtask.ParentID], subtask)
+			parentIDs = append(parentIDs, subtask.ID)
+		}
+		ids = append(ids, parentIDs...)
+	}
+
+	items, err := service.todoRepository.FindChecklistItemsByTodoIDs(ctx, ids)
+	if err != nil {
+		return TodoTreeResponse{}, err
+	}
+	checklists := make(map[uuid.UUID][]ChecklistItem)
+	for _, item := range items {
+		checklists[item.TodoID] = append(checklists[item.TodoID], item)
+	}
+
+	return newTodoTreeResponse(todo, subtasks, checklists), nil
+}
+
+func newTodoTreeResponse(todo Todo, subtasks map[uuid.UUID][]Todo, checklists map[uuid.UUID][]ChecklistItem) TodoTreeResponse {
+	progress := progressOf(subtasks[todo.ID])
+	response := TodoTreeResponse{
+		TodoResponse: NewTodoResponse(todo),
+		Progress: SubtaskProgressResponse{
+			Done:  progress.Done,
+			Total: progress.Total,
+		},
+		Checklist: make([]ChecklistItemResponse, 0, len(checklists[todo.ID])),
+		Subtasks:  make([]TodoTreeResponse, 0, len(subtasks[todo.ID])),
+	}
+	for _, item := range checklists[todo.ID] {
+		response.Checklist = append(response.Checklist, NewChecklistItemResponse(item))
+	}
+	for _, subtask := range subtasks[todo.ID] {
+		response.Subtasks = append(response.Subtasks, newTodoTreeResponse(subtask, subtasks, checklists))
+	}
+	return response
+}
+
+// SetParent nests a todo below another todo of the user, or moves it to the top level when no parent is given
+// Its own subtasks move along with it
+func (service TodoService) SetParent(ctx context.Context, todoID uuid.UUID, req SetParentRequest, userID uuid.UUID) models.Response {
+	todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find todo",
+			logger.F("operation", "Set todo parent"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found", err, service.isDebug)
+	}
+	if !ifMatches(todo, req.IfMatch) {
+		return utils.PreconditionFailedResponse("Todo has been changed", ErrVersionConflict, service.isDebug)
+	}
+
+	if req.ParentID != nil {
+		if _, response, ok := service.checkParent(ctx, *req.ParentID, todo, userID, "Set todo parent"); !ok {
+			return response
+		}
+	}
+
+	before := *todo
+	todo.ParentID = req.ParentID
+	err = service.inTransaction(ctx, func(service TodoService) error {
+		if err := service.todoRepository.UpdateTodo(ctx, todo, "parent_id"); err != nil {
+			return err
+		}
+		if err := service.recordHistory(ctx, HistoryUpdate, before, *todo, userID); err != nil {
+			return err
+		}
+		// A finished todo may be the last open subtask of its new parent
+		service.rollUp(ctx, todo, userID, "Set todo parent")
+		return nil
+	})
+	if errors.Is(err, ErrVersionConflict) {
+		return utils.PreconditionFailedResponse("Todo has been changed", err, service.isDebug)
+	}
+	if err != nil {
+		service.log.Error("Failed to set todo parent",
+			logger.F("operation", "Set todo parent"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to set todo parent", err, service.isDebug)
+	}
+
+	responseData := SetParentResponse{
+		Todo: NewTodoResponse(*todo),
+	}
+	return utils.OkResponse("Todo parent updated successfully", responseData)
+}
+
+// rollUp auto completes the parents of a completed subtask after a change
+// The change itself is already saved, so a failure is logged rather than reported
+func (service TodoService) rollUp(ctx context.Context, todo *Todo, userID uuid.UUID, operation string) {
+	if !todo.Completed || todo.ParentID == nil {
+		return
+	}
+
+	// The parents are completed in a savepoint of their own, so a failure leaves the change in place
+	completed := service.undo.scratch()
+	err := service.inTransaction(ctx, func(service TodoService) error {
+		service.undo = completed
+		workflow, err := service.workflow(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return service.autoComplete(ctx, todo, workflow, userID)
+	})
+	if err != nil {
+		service.log.Error("Failed to auto complete parent todos",
+			logger.F("operation", operation),
+			logger.F("todo_id", todo.ID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return
+	}
+	service.undo.merge(completed)
+}
@@ -0,0 +1,330 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// createTodo creates a todo through the service and returns it
+func createTodo(t *testing.T, service TodoService, userID uuid.UUID, req CreateTodoRequest) TodoResponse {
+	t.Helper()
+
+	response := service.Create(context.Background(), req, userID)
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create todo %q: %s", req.Title, response.Message)
+	}
+	return response.Data.(CreateTodoResponse).Todo
+}
+
+// treeTitles flattens a todo tree into "parent/child" paths in depth-first order
+func treeTitles(tree TodoTreeResponse, prefix string) []string {
+	path := prefix + tree.Title
+	titles := []string{path}
+	for _, subtask := range tree.Subtasks {
+		titles = append(titles, treeTitles(subtask, path+"/")...)
+	}
+	return titles
+}
+
+func getTree(t *testing.T, service TodoService, todoID uuid.UUID, userID uuid.UUID) TodoTreeResponse {
+	t.Helper()
+
+	response := service.Get(context.Background(), todoID, GetTodoRequest{}, userID)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("get status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+	}
+	return response.Data.(GetTodoResponse).Todo.TodoTreeResponse
+}
+
+func TestTodoServiceSubtasks(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			work := createProject(t, service, userID, "Work")
+			report := createTodo(t, service, userID, CreateTodoRequest{Title: "Write report", ProjectID: &work})
+			draft := createTodo(t, service, userID, CreateTodoRequest{Title: "Draft", ParentID: &report.Id})
+			createTodo(t, service, userID, CreateTodoRequest{Title: "Review", ParentID: &report.Id, Status: "done"})
+			outline := createTodo(t, service, userID, CreateTodoRequest{Title: "Outline", ParentID: &draft.Id})
+
+			if draft.ParentID == nil || *draft.ParentID != report.Id {
+				t.Errorf("parent = %v, want %v", draft.ParentID, report.Id)
+			}
+			if draft.ProjectID == nil || *draft.ProjectID != work {
+				t.Errorf("project = %v, want the parent's project %v", draft.ProjectID, work)
+			}
+
+			tree := getTree(t, service, report.Id, userID)
+			want := []string{"Write report", "Write report/Draft", "Write report/Draft/Outline", "Write report/Review"}
+			if got := treeTitles(tree, ""); !slices.Equal(got, want) {
+				t.Errorf("tree = %v, want %v", got, want)
+			}
+			if tree.Progress != (SubtaskProgressResponse{Done: 1, Total: 2}) {
+				t.Errorf("progress = %+v, want 1 of 2 done", tree.Progress)
+			}
+
+			// The test config allows two levels of subtasks
+			response := service.Create(ctx, CreateTodoRequest{Title: "Too deep", ParentID: &outline.Id}, userID)
+			if response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("too deep status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+			response = service.Create(ctx, CreateTodoRequest{Title: "Not mine", ParentID: &report.Id}, uuid.New())
+			if response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("parent of another user status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+			if response := service.Get(ctx, report.Id, GetTodoRequest{}, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("get todo of another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestTodoServiceAutoCompletesParents(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			trip := createTodo(t, service, userID, CreateTodoRequest{Title: "Plan trip", AutoComplete: true})
+			packing := createTodo(t, service, userID, CreateTodoRequest{Title: "Pack", ParentID: &trip.Id, AutoComplete: true})
+			clothes := createTodo(t, service, userID, CreateTodoRequest{Title: "Clothes", ParentID: &packing.Id})
+			books := createTodo(t, service, userID, CreateTodoRequest{Title: "Books", ParentID: &packing.Id})
+			manual := createTodo(t, service, userID, CreateTodoRequest{Title: "Book hotel"})
+			createTodo(t, service, userID, CreateTodoRequest{Title: "Call hotel", ParentID: &manual.Id, Status: "done"})
+
+			service.Update(ctx, clothes.Id, UpdateTodoRequest{Title: clothes.Title, Completed: true}, userID)
+			if tree := getTree(t, service, trip.Id, userID); tree.Completed || tree.Subtasks[0].Completed {
+				t.Fatalf("parents completed while a subtask is open")
+			}
+
+			service.Update(ctx, books.Id, UpdateTodoRequest{Title: books.Title, Completed: true}, userID)
+			tree := getTree(t, service, trip.Id, userID)
+			if !tree.Subtasks[0].Completed || tree.Subtasks[0].Status != "done" {
+				t.Errorf("packing = %+v, want it completed", tree.Subtasks[0].TodoResponse)
+			}
+			if !tree.Completed {
+				t.Errorf("trip is not completed after its only subtask was")
+			}
+
+			transitions := service.GetTransitions(ctx, trip.Id, userID).Data.(GetTodoTransitionsResponse).Transitions
+			if len(transitions) != 1 || transitions[0].To != "done" {
+				t.Errorf("transitions = %+v, want a single move to done", transitions)
+			}
+
+			// Parents that don't auto complete are left alone
+			if tree := getTree(t, service, manual.Id, userID); tree.Completed {
+				t.Errorf("parent without auto complete was completed")
+			}
+
+			// Reopening a parent is not undone by its finished subtasks
+			response := service.Update(ctx, trip.Id, UpdateTodoRequest{Title: trip.Title, AutoComplete: true}, userID)
+			if response.Data.(UpdateTodoResponse).Todo.Completed {
+				t.Errorf("reopened parent was completed again")
+			}
+		})
+	}
+}
+
+func TestTodoServiceSetParent(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			house := createTodo(t, service, userID, CreateTodoRequest{Title: "Clean house"})
+			kitchen := createTodo(t, service, userID, CreateTodoRequest{Title: "Kitchen", ParentID: &house.Id})
+			dishes := createTodo(t, service, userID, CreateTodoRequest{Title: "Dishes", ParentID: &kitchen.Id})
+			garden := createTodo(t, service, userID, CreateTodoRequest{Title: "Garden"})
+
+			tests := []struct {
+				name     string
+				todoID   uuid.UUID
+				parentID uuid.UUID
+			}{
+				{name: "itself", todoID: house.Id, parentID: house.Id},
+				{name: "below its own subtask", todoID: house.Id, parentID: dishes.Id},
+				{name: "with its subtasks too deep", todoID: kitchen.Id, parentID: dishes.Id},
+				{name: "too deep with its subtasks", todoID: house.Id, parentID: garden.Id},
+				{name: "below a todo that does not exist", todoID: garden.Id, parentID: uuid.New()},
+			}
+			for _, tt := range tests {
+				response := service.SetParent(ctx, tt.todoID, SetParentRequest{ParentID: &tt.parentID}, userID)
+				if response.StatusCode != http.StatusUnprocessableEntity {
+					t.Errorf("%s: status = %d, want %d", tt.name, response.StatusCode, http.StatusUnprocessableEntity)
+				}
+			}
+
+			// Subtasks move along with their parent
+			if response := service.SetParent(ctx, kitchen.Id, SetParentRequest{ParentID: &garden.Id}, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("set parent status = %d, want %d (%s)", response.StatusCode, http.StatusOK, response.Message)
+			}
+			if got := treeTitles(getTree(t, service, garden.Id, userID), ""); !slices.Equal(got, []string{"Garden", "Garden/Kitchen", "Garden/Kitchen/Dishes"}) {
+				t.Errorf("tree = %v, want the kitchen below the garden", got)
+			}
+
+			response := service.SetParent(ctx, dishes.Id, SetParentRequest{}, userID)
+			if moved := response.Data.(SetParentResponse).Todo; moved.ParentID != nil {
+				t.Errorf("parent = %v, want a top level todo", moved.ParentID)
+			}
+			if response := service.SetParent(ctx, dishes.Id, SetParentRequest{}, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("set parent as another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestTodoServiceDeleteWithSubtasks(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			house := createTodo(t, service, userID, CreateTodoRequest{Title: "Clean house"})
+			kitchen := createTodo(t, service, userID, CreateTodoRequest{Title: "Kitchen", ParentID: &house.Id})
+			createTodo(t, service, userID, CreateTodoRequest{Title: "Dishes", ParentID: &kitchen.Id})
+			createTodo(t, service, userID, CreateTodoRequest{Title: "Bathroom", ParentID: &house.Id})
+
+			// Reparenting moves the subtasks up a level
+			response := service.Delete(ctx, kitchen.Id, DeleteTodoRequest{Subtasks: SubtasksReparent}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("delete status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if got := treeTitles(getTree(t, service, house.Id, userID), ""); !slices.Equal(got, []string{"Clean house", "Clean house/Dishes", "Clean house/Bathroom"}) {
+				t.Errorf("tree = %v, want the dishes below the house", got)
+			}
+
+			// Cascading takes the whole tree to the trash and restoring brings it back
+			if response := service.Delete(ctx, house.Id, DeleteTodoRequest{}, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("delete status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if got := titlesOf(service.GetAll(ctx, userID, GetTodosRequest{}).Data.(GetTodosResponse).Todos); len(got) != 0 {
+				t.Errorf("todos = %v, want none", got)
+			}
+			trash := service.GetTrash(ctx, userID, GetTodosRequest{Sort: "title"}).Data.(GetTodosResponse).Todos
+			if got := titlesOf(trash); !slices.Equal(got, []string{"Bathroom", "Clean house", "Dishes", "Kitchen"}) {
+				t.Fatalf("trash = %v, want the house with its subtasks", got)
+			}
+
+			if response := service.Restore(ctx, house.Id, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("restore status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if got := treeTitles(getTree(t, service, house.Id, userID), ""); !slices.Equal(got, []string{"Clean house", "Clean house/Dishes", "Clean house/Bathroom"}) {
+				t.Errorf("restored tree = %v, want the house with its subtasks", got)
+			}
+			// The kitchen was deleted on its own before the house, so it stays in the trash
+			if got := titlesOf(service.GetTrash(ctx, userID, GetTodosRequest{}).Data.(GetTodosResponse).Todos); !slices.Equal(got, []string{"Kitchen"}) {
+				t.Errorf("trash = %v, want [Kitchen]", got)
+			}
+
+			// Purging takes the subtasks in the trash along
+			service.Delete(ctx, house.Id, DeleteTodoRequest{}, userID)
+			if response := service.Purge(ctx, house.Id, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("purge status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if got := titlesOf(service.GetTrash(ctx, userID, GetTodosRequest{}).Data.(GetTodosResponse).Todos); len(got) != 0 {
+				t.Errorf("trash after purge = %v, want it empty", got)
+			}
+
+			// A subtask whose parent is still in the trash comes back at the top level
+			bathroom := createTodo(t, service, userID, CreateTodoRequest{Title: "Bathroom"})
+			mirror := createTodo(t, service, userID, CreateTodoRequest{Title: "Mirror", ParentID: &bathroom.Id})
+			service.Delete(ctx, mirror.Id, DeleteTodoRequest{}, userID)
+			service.Delete(ctx, bathroom.Id, DeleteTodoRequest{}, userID)
+			response = service.Restore(ctx, mirror.Id, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("restore status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if restored := response.Data.(RestoreTodoResponse).Todo; restored.ParentID != nil {
+				t.Errorf("parent = %v, want a top level todo", restored.ParentID)
+			}
+		})
+	}
+}
+
+func TestTodoServiceChecklist(t *testing.T) {
+	userID := uuid.New()
+
+	checklistTexts := func(items []ChecklistItemResponse) []string {
+		texts := make([]string, 0, len(items))
+		for _, item := range items {
+			texts = append(texts, item.Text)
+		}
+		return texts
+	}
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			todo := createTodo(t, service, userID, CreateTodoRequest{Title: "Pack"})
+			ids := make(map[string]uuid.UUID)
+			for _, text := range []string{"Passport", "Charger", "Socks"} {
+				response := service.CreateChecklistItem(ctx, todo.Id, CreateChecklistItemRequest{Text: text}, userID)
+				if response.StatusCode != http.StatusCreated {
+					t.Fatalf("create status = %d, want %d", response.StatusCode, http.StatusCreated)
+				}
+				ids[text] = response.Data.(CreateChecklistItemResponse).Item.Id
+			}
+
+			response := service.ToggleChecklistItem(ctx, todo.Id, ids["Charger"], userID)
+			if item := response.Data.(UpdateChecklistItemResponse).Item; !item.Done {
+				t.Errorf("toggled item = %+v, want it done", item)
+			}
+			response = service.UpdateChecklistItem(ctx, todo.Id, ids["Socks"], UpdateChecklistItemRequest{Text: "Warm socks", Done: true}, userID)
+			if item := response.Data.(UpdateChecklistItemResponse).Item; item.Text != "Warm socks" || !item.Done {
+				t.Errorf("updated item = %+v, want done warm socks", item)
+			}
+
+			response = service.ReorderChecklist(ctx, todo.Id, ReorderChecklistRequest{ItemIDs: []uuid.UUID{ids["Socks"], ids["Charger"]}}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("reorder status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if got := checklistTexts(response.Data.(GetChecklistResponse).Items); !slices.Equal(got, []string{"Warm socks", "Charger", "Passport"}) {
+				t.Errorf("reordered = %v, want [Warm socks Charger Passport]", got)
+			}
+
+			if response := service.DeleteChecklistItem(ctx, todo.Id, ids["Charger"], userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("delete status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			createTodoItem := service.CreateChecklistItem(ctx, todo.Id, CreateChecklistItemRequest{Text: "Tickets"}, userID)
+			if createTodoItem.StatusCode != http.StatusCreated {
+				t.Fatalf("create status = %d, want %d", createTodoItem.StatusCode, http.StatusCreated)
+			}
+
+			tree := getTree(t, service, todo.Id, userID)
+			if got := checklistTexts(tree.Checklist); !slices.Equal(got, []string{"Warm socks", "Passport", "Tickets"}) {
+				t.Errorf("checklist = %v, want [Warm socks Passport Tickets]", got)
+			}
+
+			other := createTodo(t, service, userID, CreateTodoRequest{Title: "Other"})
+			if response := service.ToggleChecklistItem(ctx, other.Id, ids["Passport"], userID); response.StatusCode != http.StatusNotFound {
+				t.Errorf("item of another todo status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+			if response := service.ToggleChecklistItem(ctx, todo.Id, ids["Passport"], uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("item of another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+			if response := service.ReorderChecklist(ctx, todo.Id, ReorderChecklistRequest{ItemIDs: []uuid.UUID{ids["Charger"]}}, userID); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("reorder a deleted item status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+		})
+	}
+}
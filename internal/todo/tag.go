@@ -0,0 +1,69 @@
+package todo
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/google/uuid"
+)
+
+// defaultColor is the color of tags and projects created without one
+const defaultColor = "#808080"
+
+// Tag matching modes of a todo listing
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+var ErrUnknownTag = errors.New("tag does not exist")
+
+// Tag labels todos of a user, names are unique per user regardless of case
+type Tag struct {
+	models.Base
+	UserID uuid.UUID
+	Name   string
+	Color  string
+}
+
+// TodoTag assigns a tag to a todo
+type TodoTag struct {
+	TodoID uuid.UUID
+	TagID  uuid.UUID
+}
+
+// normalizeTagNames trims the names, dropping empty ones and case-insensitive duplicates
+func normalizeTagNames(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// sortTags orders tags by name, ignoring case
+func sortTags(tags []Tag) {
+	slices.SortFunc(tags, func(a Tag, b Tag) int {
+		if result := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); result != 0 {
+			return result
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+}
+
+// tagIDs returns the IDs of the tags
+func tagIDs(tags []Tag) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+	return ids
+}
@@ -0,0 +1,252 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      Get all tags
+// @Description  Get the tags of the authenticated user ordered by name
+// @Tags         Tag
+// @Produce      json
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetTagsResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /tags [get]
+func (handler TodoHandler) GetTags(ctx *gin.Context) {
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get tags"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetTags(ctx, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get tags request failed",
+			logger.F("operation", "Get tags"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Get tag
+// @Description  Get a tag of the authenticated user
+// @Tags         Tag
+// @Produce      json
+// @Param        id   path      string  true  "Tag ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetTagResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /tags/{id} [get]
+func (handler TodoHandler) GetTag(ctx *gin.Context) {
+	// Get tag ID from URL parameter
+	tagIDStr := ctx.Param("id")
+	tagID, err := uuid.Parse(tagIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid tag ID",
+			logger.F("operation", "Get tag"),
+			logger.F("tag_id", tagIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid tag ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get tag"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetTag(ctx, tagID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get tag request failed",
+			logger.F("operation", "Get tag"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("tag_id", tagID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Create tag
+// @Description  Create a tag for the authenticated user, names are unique regardless of case
+// @Tags         Tag
+// @Accept       json
+// @Produce      json
+// @Param        body  body      CreateTagRequest  true  "Create Tag Request"
+// @Security	 BearerAuth
+// @Success      201  {object}  models.Response{data=todo.CreateTagResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /tags [post]
+func (handler TodoHandler) CreateTag(ctx *gin.Context) {
+	var req CreateTagRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Create tag"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.CreateTag(ctx, req, userID)
+	if response.StatusCode != 201 {
+		handler.log.Warn("Create tag request failed",
+			logger.F("operation", "Create tag"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Update tag
+// @Description  Rename or recolor a tag of the authenticated user
+// @Tags         Tag
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string            true  "Tag ID"
+// @Param        body  body      UpdateTagRequest  true  "Update Tag Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.UpdateTagResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /tags/{id} [put]
+func (handler TodoHandler) UpdateTag(ctx *gin.Context) {
+	var req UpdateTagRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get tag ID from URL parameter
+	tagIDStr := ctx.Param("id")
+	tagID, err := uuid.Parse(tagIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid tag ID",
+			logger.F("operation", "Update tag"),
+			logger.F("tag_id", tagIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid tag ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Update tag"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.UpdateTag(ctx, tagID, req, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Update tag request failed",
+			logger.F("operation", "Update tag"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("tag_id", tagID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Delete tag
+// @Description  Delete a tag of the authenticated user and remove it from every todo
+// @Tags         Tag
+// @Produce      json
+// @Param        id   path      string  true  "Tag ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /tags/{id} [delete]
+func (handler TodoHandler) DeleteTag(ctx *gin.Context) {
+	// Get tag ID from URL parameter
+	tagIDStr := ctx.Param("id")
+	tagID, err := uuid.Parse(tagIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid tag ID",
+			logger.F("operation", "Delete tag"),
+			logger.F("tag_id", tagIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid tag ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Delete tag"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.DeleteTag(ctx, tagID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Delete tag request failed",
+			logger.F("operation", "Delete tag"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("tag_id", tagID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
@@ -0,0 +1,217 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// resolveTags looks up the tags to assign to a todo
+// Every ID must be a tag of the user, while names that don't exist yet are created
+func (service TodoService) resolveTags(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, names []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(ids)+len(names))
+	if len(ids) > 0 {
+		found, err := service.todoRepository.FindTagsByIDs(ctx, userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if !containsTag(found, id) {
+				return nil, ErrUnknownTag
+			}
+		}
+		tags = append(tags, found...)
+	}
+
+	names = normalizeTagNames(names)
+	if len(names) > 0 {
+		found, err := service.todoRepository.FindTagsByNames(ctx, userID, names)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if containsTagName(found, name) {
+				continue
+			}
+			tag := Tag{UserID: userID, Name: name, Color: defaultColor}
+			if err := service.todoRepository.CreateTag(ctx, &tag); err != nil {
+				return nil, err
+			}
+			found = append(found, tag)
+		}
+		for _, tag := range found {
+			if !containsTag(tags, tag.ID) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	sortTags(tags)
+	return tags, nil
+}
+
+func containsTag(tags []Tag, id uuid.UUID) bool {
+	for _, tag := range tags {
+		if tag.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTagName(tags []Tag, name string) bool {
+	for _, tag := range tags {
+		if strings.EqualFold(tag.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (service TodoService) GetTags(ctx context.Context, userID uuid.UUID) models.Response {
+	tags, err := service.todoRepository.FindTagsByUserID(ctx, userID)
+	if err != nil {
+		service.log.Error("Failed to get tags",
+			logger.F("operation", "Get tags"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get tags", err, service.isDebug)
+	}
+
+	responseData := GetTagsResponse{
+		Tags: make([]TagResponse, 0, len(tags)),
+	}
+	for _, tag := range tags {
+		responseData.Tags = append(responseData.Tags, NewTagResponse(tag))
+	}
+	return utils.OkResponse("Tags retrieved successfully", responseData)
+}
+
+func (service TodoService) GetTag(ctx context.Context, tagID uuid.UUID, userID uuid.UUID) models.Response {
+	tag, err := service.todoRepository.FindTagByIDAndUserID(ctx, tagID, userID)
+	if err != nil {
+		service.log.Error("Failed to find tag",
+			logger.F("operation", "Get tag"),
+			logger.F("tag_id", tagID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Tag not found", err, service.isDebug)
+	}
+
+	responseData := GetTagResponse{
+		Tag: NewTagResponse(*tag),
+	}
+	return utils.OkResponse("Tag retrieved successfully", responseData)
+}
+
+func (service TodoService) CreateTag(ctx context.Context, req CreateTagRequest, userID uuid.UUID) models.Response {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return utils.UnprocessableEntityResponse("Tag name is required", nil, service.isDebug)
+	}
+
+	tag := Tag{
+		UserID: userID,
+		Name:   name,
+		Color:  colorOrDefault(req.Color),
+	}
+	err := service.todoRepository.CreateTag(ctx, &tag)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return utils.UnprocessableEntityResponse("Tag already exists", err, service.isDebug)
+	}
+	if err != nil {
+		service.log.Error("Failed to create tag",
+			logger.F("operation", "Create tag"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to create tag", err, service.isDebug)
+	}
+
+	responseData := CreateTagResponse{
+		Tag: NewTagResponse(tag),
+	}
+	return utils.CreatedResponse("Success to create tag", responseData)
+}
+
+func (service TodoService) UpdateTag(ctx context.Context, tagID uuid.UUID, req UpdateTagRequest, userID uuid.UUID) models.Response {
+	tag, err := service.todoRepository.FindTagByIDAndUserID(ctx, tagID, userID)
+	if err != nil {
+		service.log.Error("Failed to find tag",
+			logger.F("operation", "Update tag"),
+			logger.F("tag_id", tagID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Tag not found", err, service.isDebug)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return utils.UnprocessableEntityResponse("Tag name is required", nil, service.isDebug)
+	}
+
+	tag.Name = name
+	tag.Color = colorOrDefault(req.Color)
+	err = service.todoRepository.UpdateTag(ctx, tag)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return utils.UnprocessableEntityResponse("Tag already exists", err, service.isDebug)
+	}
+	if err != nil {
+		service.log.Error("Failed to update tag",
+			logger.F("operation", "Update tag"),
+			logger.F("tag_id", tagID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to update tag", err, service.isDebug)
+	}
+
+	responseData := UpdateTagResponse{
+		Tag: NewTagResponse(*tag),
+	}
+	return utils.OkResponse("Tag updated successfully", responseData)
+}
+
+// DeleteTag permanently deletes the tag, the todos that had it keep existing
+func (service TodoService) DeleteTag(ctx context.Context, tagID uuid.UUID, userID uuid.UUID) models.Response {
+	tag, err := service.todoRepository.FindTagByIDAndUserID(ctx, tagID, userID)
+	if err != nil {
+		service.log.Error("Failed to find tag",
+			logger.F("operation", "Delete tag"),
+			logger.F("tag_id", tagID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Tag not found", err, service.isDebug)
+	}
+
+	err = service.todoRepository.DeleteTag(ctx, tag)
+	if err != nil {
+		service.log.Error("Failed to delete tag",
+			logger.F("operation", "Delete tag"),
+			logger.F("tag_id", tagID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to delete tag", err, service.isDebug)
+	}
+
+	return utils.OkResponse("Tag deleted successfully", nil)
+}
+
+// colorOrDefault normalizes a hex color, falling back to the default color when it is empty
+func colorOrDefault(color string) string {
+	if color == "" {
+		return defaultColor
+	}
+	return strings.ToLower(color)
+}
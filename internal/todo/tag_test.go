@@ -0,0 +1,240 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func tagNamesOf(response TodoResponse) []string {
+	names := make([]string, 0, len(response.Tags))
+	for _, tag := range response.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// seedTaggedTodos creates todos tagged by name through the service
+func seedTaggedTodos(t *testing.T, service TodoService, userID uuid.UUID, todos map[string][]string) {
+	t.Helper()
+
+	for title, names := range todos {
+		response := service.Create(context.Background(), CreateTodoRequest{Title: title, TagNames: names}, userID)
+		if response.StatusCode != http.StatusCreated {
+			t.Fatalf("failed to seed todo %q: %s", title, response.Message)
+		}
+	}
+}
+
+func TestTodoServiceTagFilters(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		req        GetTodosRequest
+		wantTitles []string
+	}{
+		{
+			name:       "any of the tags",
+			req:        GetTodosRequest{Tag: []string{"work", "home"}, Sort: "title"},
+			wantTitles: []string{"Call boss", "Fix sink", "Pay rent"},
+		},
+		{
+			name:       "all of the tags",
+			req:        GetTodosRequest{Tag: []string{"work", "urgent"}, TagMode: TagMatchAll, Sort: "title"},
+			wantTitles: []string{"Call boss"},
+		},
+		{
+			name:       "tag names ignore case",
+			req:        GetTodosRequest{Tag: []string{"HOME", "Home"}, TagMode: TagMatchAll, Sort: "title"},
+			wantTitles: []string{"Fix sink", "Pay rent"},
+		},
+		{
+			name:       "unknown tags match nothing when all are required",
+			req:        GetTodosRequest{Tag: []string{"home", "garden"}, TagMode: TagMatchAll},
+			wantTitles: []string{},
+		},
+		{
+			name:       "tags of other users do not match",
+			req:        GetTodosRequest{Tag: []string{"secret"}},
+			wantTitles: []string{},
+		},
+	}
+
+	for name, newRepository := range testRepositories() {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				repository := newRepository(t)
+				service := newTestService(repository)
+				seedTaggedTodos(t, service, userID, map[string][]string{
+					"Call boss": {"work", "urgent"},
+					"Fix sink":  {"Home", "urgent"},
+					"Pay rent":  {"home"},
+					"Read book": nil,
+				})
+				seedTaggedTodos(t, service, uuid.New(), map[string][]string{
+					"Hidden": {"secret", "work"},
+				})
+
+				response := service.GetAll(context.Background(), userID, tt.req)
+				if response.StatusCode != http.StatusOK {
+					t.Fatalf("status = %d, want %d", response.StatusCode, http.StatusOK)
+				}
+
+				data := response.Data.(GetTodosResponse)
+				if got := titlesOf(data.Todos); !slices.Equal(got, tt.wantTitles) {
+					t.Errorf("titles = %v, want %v", got, tt.wantTitles)
+				}
+				if data.Meta.Total != int64(len(tt.wantTitles)) {
+					t.Errorf("total = %d, want %d", data.Meta.Total, len(tt.wantTitles))
+				}
+			})
+		}
+	}
+}
+
+func TestTodoServiceAssignsTags(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			response := service.CreateTag(ctx, CreateTagRequest{Name: "work", Color: "#FF0000"}, userID)
+			if response.StatusCode != http.StatusCreated {
+				t.Fatalf("create tag status = %d, want %d", response.StatusCode, http.StatusCreated)
+			}
+			work := response.Data.(CreateTagResponse).Tag
+			if work.Color != "#ff0000" {
+				t.Errorf("color = %q, want %q", work.Color, "#ff0000")
+			}
+
+			// Names are matched regardless of case and unknown names create a tag
+			response = service.Create(ctx, CreateTodoRequest{
+				Title:    "Call boss",
+				TagIDs:   []uuid.UUID{work.Id},
+				TagNames: []string{"Work", " urgent ", "urgent"},
+			}, userID)
+			if response.StatusCode != http.StatusCreated {
+				t.Fatalf("create status = %d, want %d", response.StatusCode, http.StatusCreated)
+			}
+			created := response.Data.(CreateTodoResponse).Todo
+			if got := tagNamesOf(created); !slices.Equal(got, []string{"urgent", "work"}) {
+				t.Fatalf("tags = %v, want [urgent work]", got)
+			}
+
+			// Updating without tags keeps them
+			response = service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Call the boss"}, userID)
+			if got := tagNamesOf(response.Data.(UpdateTodoResponse).Todo); !slices.Equal(got, []string{"urgent", "work"}) {
+				t.Fatalf("tags after update = %v, want [urgent work]", got)
+			}
+
+			response = service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Call the boss", TagNames: []string{"phone"}}, userID)
+			if got := tagNamesOf(response.Data.(UpdateTodoResponse).Todo); !slices.Equal(got, []string{"phone"}) {
+				t.Fatalf("tags after replace = %v, want [phone]", got)
+			}
+
+			response = service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Call the boss", TagIDs: []uuid.UUID{uuid.New()}}, userID)
+			if response.StatusCode != http.StatusUnprocessableEntity {
+				t.Fatalf("unknown tag status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+
+			other := service.CreateTag(ctx, CreateTagRequest{Name: "theirs"}, uuid.New()).Data.(CreateTagResponse).Tag
+			response = service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Call the boss", TagIDs: []uuid.UUID{other.Id}}, userID)
+			if response.StatusCode != http.StatusUnprocessableEntity {
+				t.Fatalf("tag of another user status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+
+			response = service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Call the boss", TagIDs: []uuid.UUID{}}, userID)
+			if got := tagNamesOf(response.Data.(UpdateTodoResponse).Todo); len(got) != 0 {
+				t.Fatalf("tags after clearing = %v, want none", got)
+			}
+
+			response = service.GetTags(ctx, userID)
+			tags := response.Data.(GetTagsResponse).Tags
+			names := make([]string, 0, len(tags))
+			for _, tag := range tags {
+				names = append(names, tag.Name)
+			}
+			if !slices.Equal(names, []string{"phone", "urgent", "work"}) {
+				t.Errorf("tags = %v, want [phone urgent work]", names)
+			}
+		})
+	}
+}
+
+func TestTodoServiceTagCRUD(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			response := service.CreateTag(ctx, CreateTagRequest{Name: "Work"}, userID)
+			if response.StatusCode != http.StatusCreated {
+				t.Fatalf("create status = %d, want %d", response.StatusCode, http.StatusCreated)
+			}
+			work := response.Data.(CreateTagResponse).Tag
+			if work.Color != defaultColor {
+				t.Errorf("color = %q, want %q", work.Color, defaultColor)
+			}
+			home := service.CreateTag(ctx, CreateTagRequest{Name: "home"}, userID).Data.(CreateTagResponse).Tag
+
+			if response := service.CreateTag(ctx, CreateTagRequest{Name: "work"}, userID); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("duplicate name status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+			if response := service.CreateTag(ctx, CreateTagRequest{Name: "work"}, uuid.New()); response.StatusCode != http.StatusCreated {
+				t.Errorf("same name for another user status = %d, want %d", response.StatusCode, http.StatusCreated)
+			}
+			if response := service.UpdateTag(ctx, home.Id, UpdateTagRequest{Name: "WORK"}, userID); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Errorf("rename to a taken name status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+
+			response = service.UpdateTag(ctx, work.Id, UpdateTagRequest{Name: "Office", Color: "#00ff00"}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("update status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if updated := response.Data.(UpdateTagResponse).Tag; updated.Name != "Office" || updated.Color != "#00ff00" {
+				t.Errorf("updated = %+v, want Office #00ff00", updated)
+			}
+
+			todo := service.Create(ctx, CreateTodoRequest{Title: "Call boss", TagIDs: []uuid.UUID{work.Id, home.Id}}, userID).Data.(CreateTodoResponse).Todo
+			if got := tagNamesOf(todo); !slices.Equal(got, []string{"home", "Office"}) {
+				t.Fatalf("tags = %v, want [home Office]", got)
+			}
+
+			if response := service.GetTag(ctx, work.Id, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("get tag of another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+			if response := service.DeleteTag(ctx, work.Id, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("delete tag of another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+			if response := service.DeleteTag(ctx, work.Id, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("delete status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if response := service.GetTag(ctx, work.Id, userID); response.StatusCode != http.StatusNotFound {
+				t.Errorf("get deleted tag status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+
+			stored, err := repository.FindTodoByIDAndUserID(ctx, todo.Id, userID)
+			if err != nil {
+				t.Fatalf("failed to find todo: %v", err)
+			}
+			if len(stored.Tags) != 1 || stored.Tags[0].Name != "home" {
+				t.Errorf("tags after delete = %+v, want only home", stored.Tags)
+			}
+
+			// A deleted tag's name can be used again
+			if response := service.CreateTag(ctx, CreateTagRequest{Name: "Office"}, userID); response.StatusCode != http.StatusCreated {
+				t.Errorf("recreate status = %d, want %d", response.StatusCode, http.StatusCreated)
+			}
+		})
+	}
+}
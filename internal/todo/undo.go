@@ -0,0 +1,96 @@
+package todo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUndoExpired  = errors.New("undo token has expired")
+	ErrUndoConflict = errors.New("todo has been changed since")
+)
+
+// TodoUndo holds the before-images of a change, its ID is the undo token handed out with the change
+type TodoUndo struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;"`
+	UserID    uuid.UUID
+	Entries   []UndoEntry `gorm:"serializer:json"`
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (TodoUndo) TableName() string {
+	return "todo_undo"
+}
+
+func (undo *TodoUndo) BeforeCreate(tx *gorm.DB) error {
+	if undo.ID == uuid.Nil {
+		undo.ID = uuid.New()
+	}
+	return nil
+}
+
+// UndoEntry is a todo touched by a change
+type UndoEntry struct {
+	TodoID uuid.UUID `json:"todo_id"`
+
+	// Before is the todo as it was before the change, nil for a todo the change created
+	Before *Todo `json:"before"`
+
+	// Version and Deleted are the state the change left the todo in, the undo is refused once the todo moved on
+	Version int64 `json:"version"`
+	Deleted bool  `json:"deleted"`
+}
+
+// undoLog collects the entries of a change as it goes through the service, see TodoService.undoable
+type undoLog struct {
+	entries []UndoEntry
+}
+
+// add records that the change left the todo at its current version, in the trash when deleted is set
+// A todo changed more than once keeps its first before-image
+func (log *undoLog) add(before *Todo, todo Todo, deleted bool) {
+	if log == nil {
+		return
+	}
+	for i := range log.entries {
+		if log.entries[i].TodoID == todo.ID {
+			log.entries[i].Version = todo.Version
+			log.entries[i].Deleted = deleted
+			return
+		}
+	}
+	entry := UndoEntry{
+		TodoID:  todo.ID,
+		Version: todo.Version,
+		Deleted: deleted,
+	}
+	if before != nil {
+		image := *before
+		entry.Before = &image
+	}
+	log.entries = append(log.entries, entry)
+}
+
+// scratch returns an empty log for a part of the change that may still be rolled back, see merge
+func (log *undoLog) scratch() *undoLog {
+	if log == nil {
+		return nil
+	}
+	return &undoLog{}
+}
+
+// merge adds the entries of a part of the change once it went through
+func (log *undoLog) merge(part *undoLog) {
+	if log == nil || part == nil {
+		return
+	}
+	for _, entry := range part.entries {
+		todo := Todo{Version: entry.Version}
+		todo.ID = entry.TodoID
+		log.add(entry.Before, todo, entry.Deleted)
+	}
+}
@@ -0,0 +1,62 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      Undo a change
+// @Description  Revert a delete, update or bulk request of the authenticated user with the undo token of its response. The token is valid for a short window and can be used once, and the undo is refused with 409 when any of the todos has been changed since
+// @Tags         Todo
+// @Produce      json
+// @Param        token  path      string  true  "Undo token"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.UndoResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      409  {object}  models.Response
+// @Failure      410  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /undo/{token} [post]
+func (handler TodoHandler) Undo(ctx *gin.Context) {
+	// Get undo token from URL parameter
+	tokenStr := ctx.Param("token")
+	undoID, err := uuid.Parse(tokenStr)
+	if err != nil {
+		handler.log.Warn("Invalid undo token",
+			logger.F("operation", "Undo"),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid undo token", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Undo"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.Undo(ctx, undoID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Undo request failed",
+			logger.F("operation", "Undo"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("undo_id", undoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
@@ -0,0 +1,209 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// undoable runs a change that can be undone and saves the before-images it collects under an undo token,
+// in the same transaction as the change
+// A change made as part of another undoable change, such as an operation of a bulk request, leaves the token to it
+func (service TodoService) undoable(ctx context.Context, userID uuid.UUID, operation string, fn func(service TodoService) models.Response) models.Response {
+	if service.undo != nil || service.undoWindow <= 0 {
+		return fn(service)
+	}
+
+	var response models.Response
+	var undo *TodoUndo
+	err := service.inTransaction(ctx, func(service TodoService) error {
+		service.undo = &undoLog{}
+		response = fn(service)
+		if response.StatusCode >= http.StatusMultipleChoices || len(service.undo.entries) == 0 {
+			return nil
+		}
+
+		undo = &TodoUndo{
+			UserID:    userID,
+			Entries:   service.undo.entries,
+			ExpiresAt: service.now().Add(service.undoWindow),
+		}
+		return service.todoRepository.CreateTodoUndo(ctx, undo)
+	})
+	if err != nil {
+		service.log.Error("Failed to save undo token",
+			logger.F("operation", operation),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to save undo token", err, service.isDebug)
+	}
+	if undo == nil {
+		return response
+	}
+
+	token := NewUndoTokenResponse(*undo)
+	switch data := response.Data.(type) {
+	case UpdateTodoResponse:
+		data.Undo = &token
+		response.Data = data
+	case DeleteTodoResponse:
+		data.Undo = &token
+		response.Data = data
+	case BulkTodoResponse:
+		data.Undo = &token
+		response.Data = data
+	}
+	return response
+}
+
+// Undo reverts the change an undo token was handed out with, provided none of its todos changed since
+// A token can only be used once
+func (service TodoService) Undo(ctx context.Context, undoID uuid.UUID, userID uuid.UUID) models.Response {
+	undo, err := service.todoRepository.FindTodoUndoByIDAndUserID(ctx, undoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find undo token",
+			logger.F("operation", "Undo"),
+			logger.F("undo_id", undoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Undo token not found", err, service.isDebug)
+	}
+	if service.now().After(undo.ExpiresAt) {
+		return utils.ErrorResponse(http.StatusGone, "Undo token has expired", ErrUndoExpired, service.isDebug)
+	}
+
+	var todos []Todo
+	err = service.inTransaction(ctx, func(service TodoService) error {
+		// Later changes are reverted first, so every todo goes back through the states it was in
+		for _, entry := range slices.Backward(undo.Entries) {
+			todo, err := service.revert(ctx, entry, userID)
+			if err != nil {
+				return err
+			}
+			if todo != nil {
+				todos = append(todos, *todo)
+			}
+		}
+		return service.todoRepository.DeleteTodoUndo(ctx, undo)
+	})
+	if errors.Is(err, ErrUndoConflict) {
+		return utils.ConflictResponse("Todo has been changed since", err, service.isDebug)
+	}
+	if err != nil {
+		service.log.Error("Failed to undo",
+			logger.F("operation", "Undo"),
+			logger.F("undo_id", undoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to undo", err, service.isDebug)
+	}
+
+	responseData := UndoResponse{
+		Todos: make([]TodoResponse, 0, len(todos)),
+	}
+	for _, todo := range slices.Backward(todos) {
+		responseData.Todos = append(responseData.Todos, NewTodoResponse(todo))
+	}
+	return utils.OkResponse("Change undone successfully", responseData)
+}
+
+// revert puts a todo back into the state of its before-image, or purges it when the change created it
+// It returns the reverted todo, nil for a purged one, and ErrUndoConflict when the todo moved on since the change
+func (service TodoService) revert(ctx context.Context, entry UndoEntry, userID uuid.UUID) (*Todo, error) {
+	find := service.todoRepository.FindTodoByIDAndUserID
+	if entry.Deleted {
+		find = service.todoRepository.FindTrashedTodoByIDAndUserID
+	}
+	todo, err := find(ctx, entry.TodoID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUndoConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	if todo.Version != entry.Version {
+		return nil, ErrUndoConflict
+	}
+
+	if entry.Before == nil {
+		var subtasks []Todo
+		if !entry.Deleted {
+			subtasks, err = service.todoRepository.DeleteTodo(ctx, todo, SubtasksCascade)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := service.todoRepository.PurgeTodo(ctx, todo); err != nil {
+			return nil, err
+		}
+		if err := service.recordHistory(ctx, HistoryPurge, *todo, *todo, userID); err != nil {
+			return nil, err
+		}
+		return nil, service.recordSubtasksHistory(ctx, HistoryPurge, subtasks, userID)
+	}
+
+	if entry.Deleted {
+		before := *todo
+		subtasks, err := service.todoRepository.RestoreTodo(ctx, todo)
+		if err != nil {
+			return nil, err
+		}
+		if err := service.recordHistory(ctx, HistoryRestore, before, *todo, userID); err != nil {
+			return nil, err
+		}
+		if err := service.recordSubtasksHistory(ctx, HistoryRestore, subtasks, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	restored := *entry.Before
+	restored.Version = todo.Version
+	restored.DeletedAt = gorm.DeletedAt{}
+	// The parent and tags may have gone since the change, a subtask whose parent is gone comes back at the top level
+	if restored.ParentID != nil {
+		_, err := service.todoRepository.FindTodoByIDAndUserID(ctx, *restored.ParentID, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			restored.ParentID = nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if len(restored.Tags) > 0 {
+		restored.Tags, err = service.todoRepository.FindTagsByIDs(ctx, userID, tagIDs(restored.Tags))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	columns := changedColumns(*todo, restored)
+	if len(columns) == 0 {
+		return todo, nil
+	}
+	if restored.Status != todo.Status {
+		err = service.todoRepository.TransitionTodo(ctx, &restored, &TodoTransition{
+			TodoID:     todo.ID,
+			UserID:     userID,
+			FromStatus: todo.Status,
+			ToStatus:   restored.Status,
+		}, columns...)
+	} else {
+		err = service.todoRepository.UpdateTodo(ctx, &restored, columns...)
+	}
+	if errors.Is(err, ErrVersionConflict) {
+		return nil, ErrUndoConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &restored, service.recordHistory(ctx, HistoryUpdate, *todo, restored, userID)
+}
@@ -0,0 +1,133 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/jsonpatch"
+	"github.com/google/uuid"
+)
+
+func TestTodoServiceUndo(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			undo := func(token *UndoTokenResponse, wantStatus int) UndoResponse {
+				t.Helper()
+				if token == nil {
+					t.Fatal("change has no undo token")
+				}
+				response := service.Undo(ctx, token.Token, userID)
+				if response.StatusCode != wantStatus {
+					t.Fatalf("undo status = %d, want %d (%s)", response.StatusCode, wantStatus, response.Message)
+				}
+				data, _ := response.Data.(UndoResponse)
+				return data
+			}
+			titleOf := func(todoID uuid.UUID) string {
+				t.Helper()
+				todo, err := repository.FindTodoByIDAndUserID(ctx, todoID, userID)
+				if err != nil {
+					return ""
+				}
+				return todo.Title
+			}
+
+			walk := createTodo(t, service, userID, CreateTodoRequest{Title: "Walk dog", TagNames: []string{"errand"}})
+			patch := mustParsePatch(t, jsonpatch.MergePatchType, `{"title": "Walk cat", "completed": true, "tag_ids": []}`)
+			updated := service.Patch(ctx, walk.Id, patch, "", userID).Data.(UpdateTodoResponse)
+			reverted := undo(updated.Undo, http.StatusOK)
+			if len(reverted.Todos) != 1 || reverted.Todos[0].Title != "Walk dog" || reverted.Todos[0].Completed || reverted.Todos[0].Status != walk.Status {
+				t.Errorf("undone update = %+v, want the todo as created", reverted.Todos)
+			}
+			if got := tagNamesOf(reverted.Todos[0]); !slices.Equal(got, []string{"errand"}) {
+				t.Errorf("undone tags = %v, want [errand]", got)
+			}
+			// A token is used up by the undo
+			undo(updated.Undo, http.StatusNotFound)
+
+			// A todo changed again after the change can't be undone, and the undo leaves it alone
+			patch = mustParsePatch(t, jsonpatch.MergePatchType, `{"title": "Walk cat"}`)
+			updated = service.Patch(ctx, walk.Id, patch, "", userID).Data.(UpdateTodoResponse)
+			patch = mustParsePatch(t, jsonpatch.MergePatchType, `{"title": "Walk bird"}`)
+			service.Patch(ctx, walk.Id, patch, "", userID)
+			undo(updated.Undo, http.StatusConflict)
+			if got := titleOf(walk.Id); got != "Walk bird" {
+				t.Errorf("title after refused undo = %q, want Walk bird", got)
+			}
+
+			// Deleting brings back the subtasks that went to the trash with the todo
+			child := createTodo(t, service, userID, CreateTodoRequest{Title: "Buy leash", ParentID: &walk.Id})
+			deleted := service.Delete(ctx, walk.Id, DeleteTodoRequest{}, userID).Data.(DeleteTodoResponse)
+			undo(deleted.Undo, http.StatusOK)
+			if titleOf(walk.Id) != "Walk bird" || titleOf(child.Id) != "Buy leash" {
+				t.Errorf("undone delete left todo %q and subtask %q, want both back", titleOf(walk.Id), titleOf(child.Id))
+			}
+
+			// Reparented subtasks move back below the todo
+			deleted = service.Delete(ctx, walk.Id, DeleteTodoRequest{Subtasks: SubtasksReparent}, userID).Data.(DeleteTodoResponse)
+			undo(deleted.Undo, http.StatusOK)
+			subtask, err := repository.FindTodoByIDAndUserID(ctx, child.Id, userID)
+			if err != nil || subtask.ParentID == nil || *subtask.ParentID != walk.Id {
+				t.Errorf("subtask after undone reparent = %+v (%v), want below %s", subtask, err, walk.Id)
+			}
+
+			// A bulk request is undone as a whole
+			bulk := service.Bulk(ctx, BulkTodoRequest{Operations: []BulkTodoOperation{
+				{Op: "create", Create: &CreateTodoRequest{Title: "Buy milk"}},
+				{Op: "complete", ID: &child.Id},
+				{Op: "delete", ID: &walk.Id, Subtasks: SubtasksReparent},
+			}}, userID).Data.(BulkTodoResponse)
+			if !bulk.Committed {
+				t.Fatalf("bulk results = %+v, want committed", bulk.Results)
+			}
+			created := *bulk.Results[0].ID
+			undo(bulk.Undo, http.StatusOK)
+			if titleOf(created) != "" {
+				t.Errorf("todo created by the undone bulk request is still there")
+			}
+			if _, err := repository.FindTrashedTodoByIDAndUserID(ctx, created, userID); err == nil {
+				t.Errorf("todo created by the undone bulk request is in the trash, want it purged")
+			}
+			subtask, err = repository.FindTodoByIDAndUserID(ctx, child.Id, userID)
+			if err != nil || subtask.Completed || subtask.ParentID == nil || *subtask.ParentID != walk.Id {
+				t.Errorf("subtask after undone bulk = %+v (%v), want open below %s", subtask, err, walk.Id)
+			}
+			if titleOf(walk.Id) != "Walk bird" {
+				t.Errorf("todo deleted by the undone bulk request is still in the trash")
+			}
+
+			// Tokens belong to their user and run out after the undo window
+			deleted = service.Delete(ctx, child.Id, DeleteTodoRequest{}, userID).Data.(DeleteTodoResponse)
+			if response := service.Undo(ctx, deleted.Undo.Token, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("undo by another user status = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+			service.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+			undo(deleted.Undo, http.StatusGone)
+		})
+	}
+}
+
+func TestTodoServiceUndoDisabled(t *testing.T) {
+	userID := uuid.New()
+	repository := NewMemoryTodoRepository()
+	service := newTestService(repository)
+	service.undoWindow = 0
+
+	todo := seedTodos(t, repository, userID, "Walk dog")[0]
+	response := service.Delete(context.Background(), todo.ID, DeleteTodoRequest{}, userID)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("delete status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+	if undo := response.Data.(DeleteTodoResponse).Undo; undo != nil {
+		t.Errorf("undo token = %+v, want none while undo is disabled", undo)
+	}
+}
@@ -0,0 +1,166 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/google/uuid"
+)
+
+// Priorities run from P0, the most urgent, to P3
+const (
+	minPriority     = 0
+	maxPriority     = 3
+	defaultPriority = 2
+)
+
+var (
+	ErrInvalidPriority   = errors.New("invalid priority, expected P0 to P3")
+	ErrUnknownStatus     = errors.New("status is not part of the workflow")
+	ErrInvalidTransition = errors.New("status transition is not allowed by the workflow")
+)
+
+// WorkflowStatus is one column of a user's workflow
+// Moving a todo to a Done status marks it completed
+type WorkflowStatus struct {
+	models.Base
+	UserID   uuid.UUID
+	Key      string
+	Name     string
+	Position int
+	Done     bool
+}
+
+// WorkflowTransition allows todos to move from one status to another
+type WorkflowTransition struct {
+	models.Base
+	UserID     uuid.UUID
+	FromStatus string
+	ToStatus   string
+}
+
+// TodoTransition records a status change of a todo
+type TodoTransition struct {
+	models.Base
+	TodoID     uuid.UUID
+	UserID     uuid.UUID
+	FromStatus string
+	ToStatus   string
+}
+
+// Workflow is the ordered set of statuses a user's todos move through
+// Without any transitions every move between statuses is allowed
+type Workflow struct {
+	Statuses    []WorkflowStatus
+	Transitions []WorkflowTransition
+}
+
+// DefaultWorkflow is used until a user configures their own
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		Statuses: []WorkflowStatus{
+			{Key: "backlog", Name: "Backlog", Position: 0},
+			{Key: "in_progress", Name: "In progress", Position: 1},
+			{Key: "blocked", Name: "Blocked", Position: 2},
+			{Key: "done", Name: "Done", Position: 3, Done: true},
+		},
+	}
+}
+
+// Status looks up a status by key
+func (workflow Workflow) Status(key string) (WorkflowStatus, bool) {
+	for _, status := range workflow.Statuses {
+		if status.Key == key {
+			return status, true
+		}
+	}
+	return WorkflowStatus{}, false
+}
+
+// InitialStatus is the status of new todos, the first one that is not done
+func (workflow Workflow) InitialStatus() WorkflowStatus {
+	for _, status := range workflow.Statuses {
+		if !status.Done {
+			return status
+		}
+	}
+	return workflow.Statuses[0]
+}
+
+// DoneStatus is the status a todo moves to when a client only sets completed
+func (workflow Workflow) DoneStatus() WorkflowStatus {
+	for _, status := range workflow.Statuses {
+		if status.Done {
+			return status
+		}
+	}
+	return workflow.Statuses[len(workflow.Statuses)-1]
+}
+
+func (workflow Workflow) doneKeys() []string {
+	keys := make([]string, 0, len(workflow.Statuses))
+	for _, status := range workflow.Statuses {
+		if status.Done {
+			keys = append(keys, status.Key)
+		}
+	}
+	return keys
+}
+
+// CanTransition reports whether a todo may move between the two statuses
+func (workflow Workflow) CanTransition(from string, to string) bool {
+	if from == to || len(workflow.Transitions) == 0 {
+		return true
+	}
+	for _, transition := range workflow.Transitions {
+		if transition.FromStatus == from && transition.ToStatus == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that statuses are unique and that transitions only reference known statuses
+func (workflow Workflow) Validate() error {
+	if len(workflow.Statuses) == 0 {
+		return errors.New("workflow needs at least one status")
+	}
+
+	hasDone, hasOpen := false, false
+	keys := make(map[string]bool, len(workflow.Statuses))
+	for _, status := range workflow.Statuses {
+		if keys[status.Key] {
+			return fmt.Errorf("status %q is defined twice", status.Key)
+		}
+		keys[status.Key] = true
+		hasDone = hasDone || status.Done
+		hasOpen = hasOpen || !status.Done
+	}
+	if !hasDone || !hasOpen {
+		return errors.New("workflow needs at least one done and one open status")
+	}
+
+	for _, transition := range workflow.Transitions {
+		if !keys[transition.FromStatus] || !keys[transition.ToStatus] {
+			return fmt.Errorf("transition from %q to %q references an unknown status", transition.FromStatus, transition.ToStatus)
+		}
+	}
+	return nil
+}
+
+// ParsePriority parses a priority label such as "P1"
+func ParsePriority(label string) (int, error) {
+	value, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(label), "P"))
+	if err != nil || !strings.HasPrefix(strings.ToUpper(label), "P") || value < minPriority || value > maxPriority {
+		return 0, ErrInvalidPriority
+	}
+	return value, nil
+}
+
+// PriorityLabel formats a priority as its label, such as "P1"
+func PriorityLabel(priority int) string {
+	return "P" + strconv.Itoa(priority)
+}
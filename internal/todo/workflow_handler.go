@@ -0,0 +1,186 @@
+package todo
+
+import (
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary      Get board
+// @Description  Get the todos of the authenticated user grouped in one column per workflow status, most urgent first
+// @Tags         Todo
+// @Produce      json
+// @Param        limit     query     int       false  "Todos per column (1-100, default 20)"
+// @Param        title     query     string    false  "Case-insensitive title substring"
+// @Param        priority  query     []string  false  "Only these priorities"  collectionFormat(multi)  Enums(P0, P1, P2, P3)
+// @Param        If-None-Match  header  string  false  "ETag of a previous response, answered with 304 while the list is unchanged"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetBoardResponse}
+// @Header       200  {string}  ETag  "Weak ETag of the response"
+// @Success      304  "List is unchanged"
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/board [get]
+func (handler TodoHandler) GetBoard(ctx *gin.Context) {
+	var req GetBoardRequest
+	if !utils.ValidateQuery(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get board"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetBoard(ctx, userID, req)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get board request failed",
+			logger.F("operation", "Get board"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	utils.JSONWithETag(ctx, response)
+}
+
+// @Summary      Get workflow
+// @Description  Get the statuses and allowed transitions of the authenticated user's workflow
+// @Tags         Todo
+// @Produce      json
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.WorkflowResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/workflow [get]
+func (handler TodoHandler) GetWorkflow(ctx *gin.Context) {
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get workflow"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetWorkflow(ctx, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get workflow request failed",
+			logger.F("operation", "Get workflow"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Update workflow
+// @Description  Replace the workflow of the authenticated user. Without transitions every move between statuses is allowed
+// @Tags         Todo
+// @Accept       json
+// @Produce      json
+// @Param        body  body      UpdateWorkflowRequest  true  "Update Workflow Request"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.WorkflowResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/workflow [put]
+func (handler TodoHandler) UpdateWorkflow(ctx *gin.Context) {
+	var req UpdateWorkflowRequest
+	if !utils.ValidateRequest(ctx, &req) {
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Update workflow"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.UpdateWorkflow(ctx, userID, req)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Update workflow request failed",
+			logger.F("operation", "Update workflow"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
+
+// @Summary      Get todo transitions
+// @Description  Get the status changes of a todo of the authenticated user, oldest first
+// @Tags         Todo
+// @Produce      json
+// @Param        id   path      string  true  "Todo ID"
+// @Security	 BearerAuth
+// @Success      200  {object}  models.Response{data=todo.GetTodoTransitionsResponse}
+// @Failure      401  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      422  {object}  models.Response
+// @Failure      500  {object}  models.Response
+// @Router       /todo/{id}/transitions [get]
+func (handler TodoHandler) GetTransitions(ctx *gin.Context) {
+	// Get todo ID from URL parameter
+	todoIDStr := ctx.Param("id")
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		handler.log.Warn("Invalid todo ID",
+			logger.F("operation", "Get todo transitions"),
+			logger.F("todo_id", todoIDStr),
+			logger.F("error", err),
+		)
+		response := utils.UnprocessableEntityResponse("Invalid todo ID", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	// Get user ID from JWT claims
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		handler.log.Warn("Failed to get user ID from context",
+			logger.F("operation", "Get todo transitions"),
+			logger.F("error", err),
+		)
+		response := utils.UnauthorizedResponse("Unauthorized", err, false)
+		ctx.JSON(response.StatusCode, response)
+		return
+	}
+
+	response := handler.todoService.GetTransitions(ctx, todoID, userID)
+	if response.StatusCode != 200 {
+		handler.log.Warn("Get todo transitions request failed",
+			logger.F("operation", "Get todo transitions"),
+			logger.F("status_code", response.StatusCode),
+			logger.F("message", response.Message),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+		)
+	}
+
+	ctx.JSON(response.StatusCode, response)
+}
@@ -0,0 +1,190 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// workflow returns the user's workflow, or the default one if the user never configured it
+func (service TodoService) workflow(ctx context.Context, userID uuid.UUID) (Workflow, error) {
+	workflow, err := service.todoRepository.FindWorkflowByUserID(ctx, userID)
+	if err != nil {
+		return workflow, err
+	}
+	if len(workflow.Statuses) == 0 {
+		return DefaultWorkflow(), nil
+	}
+	return workflow, nil
+}
+
+func (service TodoService) GetWorkflow(ctx context.Context, userID uuid.UUID) models.Response {
+	workflow, err := service.workflow(ctx, userID)
+	if err != nil {
+		service.log.Error("Failed to get workflow",
+			logger.F("operation", "Get workflow"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get workflow", err, service.isDebug)
+	}
+
+	return utils.OkResponse("Workflow retrieved successfully", NewWorkflowResponse(workflow))
+}
+
+// UpdateWorkflow replaces the user's workflow
+// Statuses that todos still use, including trashed ones, cannot be removed
+func (service TodoService) UpdateWorkflow(ctx context.Context, userID uuid.UUID, req UpdateWorkflowRequest) models.Response {
+	workflow := Workflow{
+		Statuses:    make([]WorkflowStatus, 0, len(req.Statuses)),
+		Transitions: make([]WorkflowTransition, 0, len(req.Transitions)),
+	}
+	for _, status := range req.Statuses {
+		workflow.Statuses = append(workflow.Statuses, WorkflowStatus{
+			Key:  status.Key,
+			Name: status.Name,
+			Done: status.Done,
+		})
+	}
+	for _, transition := range req.Transitions {
+		workflow.Transitions = append(workflow.Transitions, WorkflowTransition{
+			FromStatus: transition.From,
+			ToStatus:   transition.To,
+		})
+	}
+	if err := workflow.Validate(); err != nil {
+		return utils.UnprocessableEntityResponse("Invalid workflow", err, service.isDebug)
+	}
+
+	used, err := service.todoRepository.FindUsedStatusesByUserID(ctx, userID)
+	if err != nil {
+		service.log.Error("Failed to get used statuses",
+			logger.F("operation", "Update workflow"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to update workflow", err, service.isDebug)
+	}
+	for _, key := range used {
+		if _, ok := workflow.Status(key); !ok {
+			err := fmt.Errorf("status %q is still used by todos", key)
+			return utils.UnprocessableEntityResponse("Invalid workflow", err, service.isDebug)
+		}
+	}
+
+	err = service.todoRepository.SaveWorkflow(ctx, userID, &workflow)
+	if err != nil {
+		service.log.Error("Failed to save workflow",
+			logger.F("operation", "Update workflow"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to update workflow", err, service.isDebug)
+	}
+
+	return utils.OkResponse("Workflow updated successfully", NewWorkflowResponse(workflow))
+}
+
+// GetBoard lists the user's todos in one column per workflow status, most urgent first
+func (service TodoService) GetBoard(ctx context.Context, userID uuid.UUID, req GetBoardRequest) models.Response {
+	workflow, err := service.workflow(ctx, userID)
+	if err != nil {
+		service.log.Error("Failed to get workflow",
+			logger.F("operation", "Get board"),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get board", err, service.isDebug)
+	}
+
+	filter, err := NewTodoFilter(GetTodosRequest{
+		Limit:    req.Limit,
+		Sort:     "priority",
+		Title:    req.Title,
+		Priority: req.Priority,
+	}, service.now())
+	if err != nil {
+		return utils.UnprocessableEntityResponse("Invalid priority", err, service.isDebug)
+	}
+
+	responseData := GetBoardResponse{
+		Columns: make([]BoardColumn, 0, len(workflow.Statuses)),
+	}
+	for _, status := range workflow.Statuses {
+		filter.Statuses = []string{status.Key}
+
+		todos, err := service.todoRepository.FindAllTodoByUserID(ctx, userID.String(), filter)
+		if err != nil {
+			service.log.Error("Failed to get todos",
+				logger.F("operation", "Get board"),
+				logger.F("user_id", userID.String()),
+				logger.F("status", status.Key),
+				logger.F("error", err),
+			)
+			return utils.InternalServerErrorResponse("Failed to get board", err, service.isDebug)
+		}
+		total, err := service.todoRepository.CountTodoByUserID(ctx, userID.String(), filter)
+		if err != nil {
+			service.log.Error("Failed to count todos",
+				logger.F("operation", "Get board"),
+				logger.F("user_id", userID.String()),
+				logger.F("status", status.Key),
+				logger.F("error", err),
+			)
+			return utils.InternalServerErrorResponse("Failed to get board", err, service.isDebug)
+		}
+
+		column := BoardColumn{
+			Status: NewWorkflowStatusResponse(status),
+			Todos:  make([]TodoResponse, 0, len(todos)),
+			Total:  total,
+		}
+		if len(todos) > filter.Limit {
+			todos = todos[:filter.Limit]
+			column.HasMore = true
+		}
+		for _, todo := range todos {
+			column.Todos = append(column.Todos, NewTodoResponse(todo))
+		}
+		responseData.Columns = append(responseData.Columns, column)
+	}
+
+	return utils.OkResponse("Board retrieved successfully", responseData)
+}
+
+// GetTransitions lists the status changes of a todo, oldest first
+func (service TodoService) GetTransitions(ctx context.Context, todoID uuid.UUID, userID uuid.UUID) models.Response {
+	todo, err := service.todoRepository.FindTodoByIDAndUserID(ctx, todoID, userID)
+	if err != nil {
+		service.log.Error("Failed to find todo",
+			logger.F("operation", "Get todo transitions"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.NotFoundResponse("Todo not found", err, service.isDebug)
+	}
+
+	transitions, err := service.todoRepository.FindTodoTransitionsByTodoID(ctx, todo.ID)
+	if err != nil {
+		service.log.Error("Failed to get todo transitions",
+			logger.F("operation", "Get todo transitions"),
+			logger.F("todo_id", todoID.String()),
+			logger.F("user_id", userID.String()),
+			logger.F("error", err),
+		)
+		return utils.InternalServerErrorResponse("Failed to get todo transitions", err, service.isDebug)
+	}
+
+	responseData := GetTodoTransitionsResponse{
+		Transitions: make([]TodoTransitionResponse, 0, len(transitions)),
+	}
+	for _, transition := range transitions {
+		responseData.Transitions = append(responseData.Transitions, NewTodoTransitionResponse(transition))
+	}
+	return utils.OkResponse("Todo transitions retrieved successfully", responseData)
+}
@@ -0,0 +1,412 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// seedPrioritizedTodos creates one todo per title, cycling through priorities and statuses
+func seedPrioritizedTodos(t *testing.T, repository TodoRepository, userID uuid.UUID, titles ...string) []Todo {
+	t.Helper()
+
+	statuses := []string{"backlog", "in_progress", "blocked", "done"}
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	todos := make([]Todo, 0, len(titles))
+	for i, title := range titles {
+		todo := Todo{
+			Title:     title,
+			UserID:    userID,
+			Priority:  (i * 3) % (maxPriority + 1),
+			Status:    statuses[i%len(statuses)],
+			Completed: statuses[i%len(statuses)] == "done",
+		}
+		todo.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		todo.UpdatedAt = todo.CreatedAt
+		if err := repository.CreateTodo(context.Background(), &todo); err != nil {
+			t.Fatalf("failed to seed todo: %v", err)
+		}
+		todos = append(todos, todo)
+	}
+	return todos
+}
+
+func TestParsePriority(t *testing.T) {
+	for label, want := range map[string]int{"P0": 0, "p1": 1, "P3": 3} {
+		priority, err := ParsePriority(label)
+		if err != nil || priority != want {
+			t.Fatalf("ParsePriority(%q) = %d, %v, want %d", label, priority, err, want)
+		}
+	}
+	if got := PriorityLabel(2); got != "P2" {
+		t.Errorf("PriorityLabel(2) = %q, want %q", got, "P2")
+	}
+	for _, label := range []string{"", "P", "P4", "1", "P-1", "high"} {
+		if _, err := ParsePriority(label); !errors.Is(err, ErrInvalidPriority) {
+			t.Errorf("ParsePriority(%q) error = %v, want ErrInvalidPriority", label, err)
+		}
+	}
+}
+
+func TestWorkflowValidate(t *testing.T) {
+	open := WorkflowStatus{Key: "open", Name: "Open"}
+	closed := WorkflowStatus{Key: "closed", Name: "Closed", Done: true}
+
+	tests := []struct {
+		name     string
+		workflow Workflow
+		wantErr  bool
+	}{
+		{name: "default workflow", workflow: DefaultWorkflow()},
+		{
+			name: "transitions between known statuses",
+			workflow: Workflow{
+				Statuses:    []WorkflowStatus{open, closed},
+				Transitions: []WorkflowTransition{{FromStatus: "open", ToStatus: "closed"}},
+			},
+		},
+		{name: "duplicate keys", workflow: Workflow{Statuses: []WorkflowStatus{open, open, closed}}, wantErr: true},
+		{name: "no done status", workflow: Workflow{Statuses: []WorkflowStatus{open}}, wantErr: true},
+		{name: "no open status", workflow: Workflow{Statuses: []WorkflowStatus{closed}}, wantErr: true},
+		{
+			name: "transition to an unknown status",
+			workflow: Workflow{
+				Statuses:    []WorkflowStatus{open, closed},
+				Transitions: []WorkflowTransition{{FromStatus: "open", ToStatus: "archived"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.workflow.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTodoServicePriorityAndStatusFilters(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		req        GetTodosRequest
+		wantStatus int
+		wantTitles []string
+	}{
+		{
+			name:       "sorts by priority, most urgent first",
+			req:        GetTodosRequest{Sort: "priority"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"a", "d", "c", "b"},
+		},
+		{
+			name:       "sorts by priority descending",
+			req:        GetTodosRequest{Sort: "-priority"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"b", "c", "d", "a"},
+		},
+		{
+			name:       "sorts by status",
+			req:        GetTodosRequest{Sort: "status"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"a", "c", "d", "b"},
+		},
+		{
+			name:       "filters by priority",
+			req:        GetTodosRequest{Priority: []string{"P0", "P3"}, Sort: "title"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"a", "b"},
+		},
+		{
+			name:       "filters by status",
+			req:        GetTodosRequest{Status: []string{"in_progress", "done"}, Sort: "title"},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"b", "d"},
+		},
+		{
+			name:       "combines priority and status",
+			req:        GetTodosRequest{Priority: []string{"P3", "P2"}, Status: []string{"in_progress", "backlog"}},
+			wantStatus: http.StatusOK,
+			wantTitles: []string{"b"},
+		},
+		{
+			name:       "rejects an unknown priority",
+			req:        GetTodosRequest{Priority: []string{"P9"}},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for name, newRepository := range testRepositories() {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				repository := newRepository(t)
+				service := newTestService(repository)
+				// Priorities P0 P3 P2 P1, statuses backlog in_progress blocked done
+				seedPrioritizedTodos(t, repository, userID, "a", "b", "c", "d")
+
+				response := service.GetAll(context.Background(), userID, tt.req)
+				if response.StatusCode != tt.wantStatus {
+					t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				data := response.Data.(GetTodosResponse)
+				if got := titlesOf(data.Todos); !slices.Equal(got, tt.wantTitles) {
+					t.Errorf("titles = %v, want %v", got, tt.wantTitles)
+				}
+			})
+		}
+	}
+}
+
+func TestTodoServicePriorityCursorWalksEveryPage(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			repository := newRepository(t)
+			service := newTestService(repository)
+			seedPrioritizedTodos(t, repository, userID, "a", "b", "c", "d", "e", "f", "g")
+
+			var seen []string
+			req := GetTodosRequest{Limit: 2, Sort: "-priority"}
+			for page := 0; page < 10; page++ {
+				response := service.GetAll(context.Background(), userID, req)
+				if response.StatusCode != http.StatusOK {
+					t.Fatalf("status = %d, want %d", response.StatusCode, http.StatusOK)
+				}
+				data := response.Data.(GetTodosResponse)
+				seen = append(seen, titlesOf(data.Todos)...)
+				if !data.Meta.HasMore {
+					break
+				}
+				req.Cursor = data.Meta.NextCursor
+			}
+
+			// Ties on priority are broken by id, so only check the order of priorities and that each todo shows up once
+			if len(seen) != 7 {
+				t.Fatalf("saw %d todos, want 7: %v", len(seen), seen)
+			}
+			priorities := map[string]int{"a": 0, "b": 3, "c": 2, "d": 1, "e": 0, "f": 3, "g": 2}
+			for i := 1; i < len(seen); i++ {
+				if priorities[seen[i-1]] < priorities[seen[i]] {
+					t.Fatalf("titles = %v, want priorities in descending order", seen)
+				}
+			}
+			slices.Sort(seen)
+			if !slices.Equal(slices.Compact(seen), []string{"a", "b", "c", "d", "e", "f", "g"}) {
+				t.Errorf("titles = %v, want every todo once", seen)
+			}
+		})
+	}
+}
+
+func TestTodoServiceStatusWorkflow(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+
+			response := service.Create(ctx, CreateTodoRequest{Title: "Walk dog"}, userID)
+			if response.StatusCode != http.StatusCreated {
+				t.Fatalf("create status = %d, want %d", response.StatusCode, http.StatusCreated)
+			}
+			created := response.Data.(CreateTodoResponse).Todo
+			if created.Status != "backlog" || created.Priority != "P2" || created.Completed {
+				t.Fatalf("created = %+v, want an open P2 todo in the backlog", created)
+			}
+
+			// Clients that only send completed still move the todo to the done status
+			response = service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Walk dog", Completed: true}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("update status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if updated := response.Data.(UpdateTodoResponse).Todo; updated.Status != "done" || !updated.Completed {
+				t.Fatalf("updated = %+v, want a completed todo", updated)
+			}
+
+			response = service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Walk dog", Status: "blocked", Priority: "P0"}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("update status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if updated := response.Data.(UpdateTodoResponse).Todo; updated.Status != "blocked" || updated.Completed || updated.Priority != "P0" {
+				t.Fatalf("updated = %+v, want an open P0 todo that is blocked", updated)
+			}
+
+			response = service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Walk dog", Status: "archived"}, userID)
+			if response.StatusCode != http.StatusUnprocessableEntity {
+				t.Fatalf("unknown status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+
+			response = service.GetTransitions(ctx, created.Id, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("transitions status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			transitions := response.Data.(GetTodoTransitionsResponse).Transitions
+			if len(transitions) != 2 ||
+				transitions[0].From != "backlog" || transitions[0].To != "done" ||
+				transitions[1].From != "done" || transitions[1].To != "blocked" {
+				t.Errorf("transitions = %+v, want backlog to done to blocked", transitions)
+			}
+
+			if response := service.GetTransitions(ctx, created.Id, uuid.New()); response.StatusCode != http.StatusNotFound {
+				t.Errorf("transitions of another user = %d, want %d", response.StatusCode, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestTodoServiceUpdateWorkflow(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newRepository(t)
+			service := newTestService(repository)
+			workflow := UpdateWorkflowRequest{
+				Statuses: []WorkflowStatusRequest{
+					{Key: "todo", Name: "To do"},
+					{Key: "review", Name: "In review"},
+					{Key: "shipped", Name: "Shipped", Done: true},
+				},
+				Transitions: []WorkflowTransitionRequest{
+					{From: "todo", To: "review"},
+					{From: "review", To: "todo"},
+					{From: "review", To: "shipped"},
+				},
+			}
+
+			response := service.GetWorkflow(ctx, userID)
+			if got := len(response.Data.(WorkflowResponse).Statuses); got != len(DefaultWorkflow().Statuses) {
+				t.Fatalf("default statuses = %d, want %d", got, len(DefaultWorkflow().Statuses))
+			}
+
+			// Removing a status that todos still use is refused
+			todo := seedTodos(t, repository, userID, "Walk dog")[0]
+			if response := service.UpdateWorkflow(ctx, userID, workflow); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Fatalf("update with a used status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+			if err := repository.PurgeTodo(ctx, &todo); err != nil {
+				t.Fatalf("failed to purge todo: %v", err)
+			}
+
+			response = service.UpdateWorkflow(ctx, userID, workflow)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("update status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			response = service.GetWorkflow(ctx, userID)
+			saved := response.Data.(WorkflowResponse)
+			if len(saved.Statuses) != 3 || saved.Statuses[0].Key != "todo" || saved.Statuses[2].Key != "shipped" || len(saved.Transitions) != 3 {
+				t.Fatalf("saved workflow = %+v, want the configured one", saved)
+			}
+
+			response = service.Create(ctx, CreateTodoRequest{Title: "Ship it"}, userID)
+			created := response.Data.(CreateTodoResponse).Todo
+			if created.Status != "todo" {
+				t.Fatalf("created status = %q, want %q", created.Status, "todo")
+			}
+
+			// Skipping review is not an allowed transition, even through completed
+			if response := service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Ship it", Completed: true}, userID); response.StatusCode != http.StatusUnprocessableEntity {
+				t.Fatalf("todo to shipped = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+			}
+			if response := service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Ship it", Status: "review"}, userID); response.StatusCode != http.StatusOK {
+				t.Fatalf("todo to review = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			response = service.Update(ctx, created.Id, UpdateTodoRequest{Title: "Ship it", Status: "shipped"}, userID)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("review to shipped = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			if updated := response.Data.(UpdateTodoResponse).Todo; !updated.Completed {
+				t.Errorf("shipped todo completed = false, want true")
+			}
+
+			// Changing which statuses are done keeps completed in sync
+			workflow.Statuses[1].Done = true
+			workflow.Statuses[2].Done = false
+			if response := service.UpdateWorkflow(ctx, userID, workflow); response.StatusCode != http.StatusOK {
+				t.Fatalf("second update status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+			stored, err := repository.FindTodoByIDAndUserID(ctx, created.Id, userID)
+			if err != nil {
+				t.Fatalf("failed to find todo: %v", err)
+			}
+			if stored.Completed {
+				t.Errorf("completed = true after shipped stopped being a done status")
+			}
+		})
+	}
+}
+
+func TestTodoServiceUpdateWorkflowRejectsInvalidWorkflows(t *testing.T) {
+	service := newTestService(NewMemoryTodoRepository())
+
+	response := service.UpdateWorkflow(context.Background(), uuid.New(), UpdateWorkflowRequest{
+		Statuses: []WorkflowStatusRequest{
+			{Key: "todo", Name: "To do"},
+			{Key: "doing", Name: "Doing"},
+		},
+	})
+	if response.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", response.StatusCode, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestTodoServiceGetBoard(t *testing.T) {
+	userID := uuid.New()
+
+	for name, newRepository := range testRepositories() {
+		t.Run(name, func(t *testing.T) {
+			repository := newRepository(t)
+			service := newTestService(repository)
+			// Priorities P0 P3 P2 P1, statuses backlog in_progress blocked done
+			seedPrioritizedTodos(t, repository, userID, "a", "b", "c", "d")
+			seedPrioritizedTodos(t, repository, uuid.New(), "other")
+			service.Create(context.Background(), CreateTodoRequest{Title: "e", Status: "backlog", Priority: "P3"}, userID)
+			service.Create(context.Background(), CreateTodoRequest{Title: "f", Status: "in_progress", Priority: "P0"}, userID)
+
+			response := service.GetBoard(context.Background(), userID, GetBoardRequest{Limit: 1})
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want %d", response.StatusCode, http.StatusOK)
+			}
+
+			columns := response.Data.(GetBoardResponse).Columns
+			want := []struct {
+				status  string
+				titles  []string
+				total   int64
+				hasMore bool
+			}{
+				{status: "backlog", titles: []string{"a"}, total: 2, hasMore: true},
+				{status: "in_progress", titles: []string{"f"}, total: 2, hasMore: true},
+				{status: "blocked", titles: []string{"c"}, total: 1},
+				{status: "done", titles: []string{"d"}, total: 1},
+			}
+			if len(columns) != len(want) {
+				t.Fatalf("columns = %d, want %d", len(columns), len(want))
+			}
+			for i, column := range columns {
+				if column.Status.Key != want[i].status || column.Total != want[i].total || column.HasMore != want[i].hasMore {
+					t.Errorf("column %d = %s total %d has more %v, want %+v", i, column.Status.Key, column.Total, column.HasMore, want[i])
+				}
+				if got := titlesOf(column.Todos); !slices.Equal(got, want[i].titles) {
+					t.Errorf("column %s titles = %v, want %v", column.Status.Key, got, want[i].titles)
+				}
+			}
+		})
+	}
+}
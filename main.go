@@ -11,6 +11,8 @@ import (
 	"github.com/Alfian57/golang-todo/pkg/config"
 	"github.com/Alfian57/golang-todo/pkg/database"
 	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/migrate"
+	"github.com/Alfian57/golang-todo/pkg/module"
 )
 
 // @title Golang Todo API
@@ -32,6 +34,26 @@ func main() {
 		defer zapLogger.Sync()
 	}
 
+	// The config command inspects the configuration, so it runs before anything depends on it
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(cfg, os.Args[2:], os.Stdout); err != nil {
+			log.Fatal("Configuration check failed", logger.F("error", err))
+		}
+		return
+	}
+
+	// Refuse to start with an invalid configuration, in debug mode insecure values are only warned about
+	warnings, err := cfg.Check()
+	for _, warning := range warnings {
+		log.Warn("Configuration problem",
+			logger.F("key", warning.Key),
+			logger.F("problem", warning.Message),
+		)
+	}
+	if err != nil {
+		log.Fatal("Invalid configuration", logger.F("error", err))
+	}
+
 	// Init Swagger Info
 	config.InitSwagger()
 
@@ -41,8 +63,43 @@ func main() {
 		log.Fatal("Failed to initialize database", logger.F("error", err))
 	}
 
+	runner, err := migrate.NewModuleRunner(db, cfg.Database.Driver, log, module.Modules())
+	if err != nil {
+		log.Fatal("Failed to load migrations", logger.F("error", err))
+	}
+
+	// Subcommands run against the database and exit instead of starting the server
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrate(context.Background(), runner, os.Args[2:], os.Stdout); err != nil {
+				log.Fatal("Migration failed", logger.F("error", err))
+			}
+		default:
+			log.Fatal("Unknown command", logger.F("command", os.Args[1]))
+		}
+		return
+	}
+
+	// An in-memory database starts empty every time, so it is always migrated
+	if cfg.Database.AutoMigrate || cfg.Database.IsSQLiteMemory() {
+		if err := runner.Up(context.Background()); err != nil {
+			log.Fatal("Failed to migrate database", logger.F("error", err))
+		}
+	}
+
+	// Build the dependency container shared by all modules
+	deps, err := module.NewDependencies(db, cfg, log)
+	if err != nil {
+		log.Fatal("Failed to build dependencies", logger.F("error", err))
+	}
+
 	// Setup server
-	srv := initServer(db, cfg, log)
+	srv := initServer(deps)
+
+	// Start background jobs of the registered modules
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobs := module.StartJobs(jobsCtx, deps)
 
 	// Start server in goroutine
 	go func() {
@@ -64,6 +121,8 @@ func main() {
 
 	log.Info("Shutting down server...")
 
+	stopJobs()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -73,5 +132,7 @@ func main() {
 		)
 	}
 
+	jobs.Wait()
+
 	log.Info("Server exited")
 }
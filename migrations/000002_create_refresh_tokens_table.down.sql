@@ -1 +0,0 @@
-DROP TABLE IF EXISTS refresh_tokens;
\ No newline at end of file
@@ -1 +0,0 @@
-DROP TABLE IF EXISTS todos;
\ No newline at end of file
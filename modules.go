@@ -0,0 +1,8 @@
+package main
+
+// Feature modules register themselves with the module registry from their init functions
+// Importing a feature package here is all it takes to mount it on the server
+import (
+	_ "github.com/Alfian57/golang-todo/internal/auth"
+	_ "github.com/Alfian57/golang-todo/internal/todo"
+)
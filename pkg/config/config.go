@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
 	"time"
 
@@ -15,6 +16,12 @@ type Config struct {
 	App      AppConfig
 	Database DatabaseConfig
 	JWT      JWTConfig
+	Trash    TrashConfig
+	Todo     TodoConfig
+
+	// settings and problems are recorded while loading, see Settings and Validate
+	settings []Setting
+	problems []Problem
 }
 
 type AppConfig struct {
@@ -25,103 +32,284 @@ type AppConfig struct {
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	Username string
-	Password string
-	Name     string
-	DSN      string
+	Driver      string
+	Path        string
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	Name        string
+	DSN         string
+	AutoMigrate bool
 }
 
 type JWTConfig struct {
+	// Secret signs HS256 tokens, the asymmetric algorithms sign with the keys in KeysDir instead
 	Secret    []byte
 	TTL       time.Duration
 	TTLInHour int
+
+	// Algorithm is AlgorithmHS256, AlgorithmRS256 or AlgorithmEdDSA
+	// KeysDir holds one PEM private key per file, named after its kid, a key is generated when none is usable
+	// KeyRotation is how long a key signs tokens before a new one is generated, 0 never rotates
+	Algorithm   string
+	KeysDir     string
+	KeyRotation time.Duration
+
+	// Denylist is where revoked access tokens are kept until they expire, DenylistMemory or DenylistDatabase
+	Denylist              string
+	DenylistPurgeInterval time.Duration
+}
+
+type TrashConfig struct {
+	// Retention is how long soft deleted rows are kept before they are purged, 0 keeps them forever
+	Retention      time.Duration
+	RetentionInDay int
+	PurgeInterval  time.Duration
+}
+
+type TodoConfig struct {
+	// MaxSubtaskDepth is how many levels of subtasks a todo can have, 0 disables subtasks
+	MaxSubtaskDepth int
+
+	// UndoWindow is how long the undo token of a change stays valid, 0 disables undo
+	UndoWindow time.Duration
 }
 
+// Supported GIN_MODE values
+const (
+	ModeDebug   = "debug"
+	ModeRelease = "release"
+	ModeTest    = "test"
+)
+
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+
+	// SQLiteMemory is the DB_PATH of an in-memory SQLite database
+	SQLiteMemory = ":memory:"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Supported access token denylists
+const (
+	// DenylistMemory keeps revoked tokens in the process, which is only enough for a single instance
+	DenylistMemory = "memory"
+
+	// DenylistDatabase keeps revoked tokens in the database, shared by every instance
+	DenylistDatabase = "database"
+)
+
 var defaultValues = map[string]string{
 	"APP_NAME": "golang-todo",
 	"GIN_MODE": "release",
 	"APP_URL":  "localhost:8080",
 	"PORT":     "8080",
 
+	"DB_DRIVER":   "postgres",
+	"DB_PATH":     "todo_list.db",
 	"DB_HOST":     "127.0.0.1",
 	"DB_PORT":     "5432",
 	"DB_USERNAME": "postgres",
 	"DB_PASSWORD": "postgres",
 	"DB_NAME":     "todo_list",
 
+	"DB_AUTO_MIGRATE": "false",
+
 	"JWT_SECRET":      "my-secret-key",
 	"JWT_EXP_IN_HOUR": "1",
+
+	"JWT_ALGORITHM":           "HS256",
+	"JWT_KEYS_DIR":            "keys",
+	"JWT_KEY_ROTATION_IN_DAY": "30",
+
+	"JWT_DENYLIST":                          "memory",
+	"JWT_DENYLIST_PURGE_INTERVAL_IN_MINUTE": "10",
+
+	"TRASH_RETENTION_IN_DAY":         "30",
+	"TRASH_PURGE_INTERVAL_IN_MINUTE": "60",
+
+	"TODO_MAX_SUBTASK_DEPTH":     "3",
+	"TODO_UNDO_WINDOW_IN_SECOND": "30",
 }
 
 // LoadConfig loads configuration from environment variables
-// This should be called explicitly once in main.go
+// This should be called explicitly once in main.go, followed by Check since the values aren't validated here
 func LoadConfig() (*Config, error) {
 	// Try to load .env file, but don't fail if it doesn't exist
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: Failed to load .env file, using environment variables and defaults")
 	}
 
+	env := &envLoader{}
 	cfg := &Config{
 		App: AppConfig{
-			Name: getEnvAsString("APP_NAME"),
-			Mode: getEnvAsString("GIN_MODE"),
-			URL:  getEnvAsString("APP_URL"),
-			Port: getEnvAsString("PORT"),
+			Name: env.string("APP_NAME"),
+			Mode: env.string("GIN_MODE"),
+			URL:  env.string("APP_URL"),
+			Port: env.string("PORT"),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnvAsString("DB_HOST"),
-			Port:     getEnvAsInt("DB_PORT"),
-			Username: getEnvAsString("DB_USERNAME"),
-			Password: getEnvAsString("DB_PASSWORD"),
-			Name:     getEnvAsString("DB_NAME"),
+			Driver:   env.string("DB_DRIVER"),
+			Path:     env.string("DB_PATH"),
+			Host:     env.string("DB_HOST"),
+			Port:     env.int("DB_PORT"),
+			Username: env.string("DB_USERNAME"),
+			Password: env.secret("DB_PASSWORD"),
+			Name:     env.string("DB_NAME"),
+
+			AutoMigrate: env.bool("DB_AUTO_MIGRATE"),
 		},
 		JWT: JWTConfig{
-			Secret:    []byte(getEnvAsString("JWT_SECRET")),
-			TTLInHour: getEnvAsInt("JWT_EXP_IN_HOUR"),
-			TTL:       time.Duration(getEnvAsInt("JWT_EXP_IN_HOUR")) * time.Hour,
+			Secret:    []byte(env.secret("JWT_SECRET")),
+			TTLInHour: env.int("JWT_EXP_IN_HOUR"),
+			TTL:       time.Duration(env.int("JWT_EXP_IN_HOUR")) * time.Hour,
+
+			Algorithm:   env.string("JWT_ALGORITHM"),
+			KeysDir:     env.string("JWT_KEYS_DIR"),
+			KeyRotation: time.Duration(env.int("JWT_KEY_ROTATION_IN_DAY")) * 24 * time.Hour,
+
+			Denylist:              env.string("JWT_DENYLIST"),
+			DenylistPurgeInterval: time.Duration(env.int("JWT_DENYLIST_PURGE_INTERVAL_IN_MINUTE")) * time.Minute,
+		},
+		Trash: TrashConfig{
+			RetentionInDay: env.int("TRASH_RETENTION_IN_DAY"),
+			Retention:      time.Duration(env.int("TRASH_RETENTION_IN_DAY")) * 24 * time.Hour,
+			PurgeInterval:  time.Duration(env.int("TRASH_PURGE_INTERVAL_IN_MINUTE")) * time.Minute,
+		},
+		Todo: TodoConfig{
+			MaxSubtaskDepth: env.int("TODO_MAX_SUBTASK_DEPTH"),
+			UndoWindow:      time.Duration(env.int("TODO_UNDO_WINDOW_IN_SECOND")) * time.Second,
 		},
 	}
 
-	// Build database DSN
-	cfg.Database.DSN = fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Jakarta",
-		cfg.Database.Host,
-		cfg.Database.Username,
-		cfg.Database.Password,
-		cfg.Database.Name,
-		cfg.Database.Port,
-	)
+	// Build database DSN, an unsupported driver is reported by Validate
+	switch cfg.Database.Driver {
+	case DriverPostgres:
+		cfg.Database.DSN = fmt.Sprintf(
+			"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Jakarta",
+			cfg.Database.Host,
+			cfg.Database.Username,
+			cfg.Database.Password,
+			cfg.Database.Name,
+			cfg.Database.Port,
+		)
+	case DriverSQLite:
+		cfg.Database.DSN = SQLiteDSN(cfg.Database.Path)
+	}
 
+	cfg.settings = env.settings
+	cfg.problems = env.problems
 	return cfg, nil
 }
 
-func getEnvAsString(key string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+// SQLiteDSN builds the DSN of an SQLite database file, or of an in-memory database for ":memory:"
+// Foreign keys are off by default in SQLite, so they are enabled for every connection
+func SQLiteDSN(path string) string {
+	if path == SQLiteMemory {
+		return "file::memory:?_pragma=foreign_keys(1)"
+	}
+	return fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+}
+
+// IsSQLiteMemory reports whether the database lives in memory and disappears with the process
+func (cfg DatabaseConfig) IsSQLiteMemory() bool {
+	return cfg.Driver == DriverSQLite && cfg.Path == SQLiteMemory
+}
+
+// Setting is the effective value of an environment variable, secrets are redacted
+type Setting struct {
+	Key   string
+	Value string
+
+	// Default is set when the variable isn't set and the value comes from defaultValues
+	Default bool
+}
+
+// Redacted replaces the value of a secret in Settings
+const Redacted = "[redacted]"
+
+// Settings returns the effective value of every environment variable the configuration was loaded from, in load order
+func (cfg *Config) Settings() []Setting {
+	return cfg.settings
+}
+
+// envLoader reads environment variables, falling back to defaultValues
+// It records the effective value of every variable it reads and the values that aren't of the expected type
+type envLoader struct {
+	settings []Setting
+	problems []Problem
+}
+
+func (env *envLoader) lookup(key string, secret bool) string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		value = defaultValues[key]
 	}
 
-	if def, ok := defaultValues[key]; ok {
-		return def
+	if !slices.ContainsFunc(env.settings, func(setting Setting) bool { return setting.Key == key }) {
+		setting := Setting{Key: key, Value: value, Default: !ok}
+		if secret && value != "" {
+			setting.Value = Redacted
+		}
+		env.settings = append(env.settings, setting)
 	}
+	return value
+}
+
+func (env *envLoader) string(key string) string {
+	return env.lookup(key, false)
+}
 
-	return ""
+// secret reads a variable whose value is redacted from Settings
+func (env *envLoader) secret(key string) string {
+	return env.lookup(key, true)
 }
 
-func getEnvAsInt(key string) int {
-	valueStr := getEnvAsString(key)
+// int reads an integer, an invalid value is recorded as a problem and replaced by the default
+func (env *envLoader) int(key string) int {
+	valueStr := env.lookup(key, false)
 	valueInt, err := strconv.Atoi(valueStr)
 	if err != nil {
-		log.Printf("%s not an integer", key)
+		env.invalid(key, fmt.Sprintf("is not an integer: %q, using the default %q", valueStr, defaultValues[key]))
 
-		if def, ok := defaultValues[key]; ok {
-			if defInt, err := strconv.Atoi(def); err == nil {
-				return defInt
-			}
+		if defInt, err := strconv.Atoi(defaultValues[key]); err == nil {
+			return defInt
 		}
 		return 0
 	}
 
 	return valueInt
 }
+
+// bool reads a boolean, an invalid value is recorded as a problem and replaced by the default
+func (env *envLoader) bool(key string) bool {
+	valueStr := env.lookup(key, false)
+	valueBool, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		env.invalid(key, fmt.Sprintf("is not a boolean: %q, using the default %q", valueStr, defaultValues[key]))
+
+		if defBool, err := strconv.ParseBool(defaultValues[key]); err == nil {
+			return defBool
+		}
+		return false
+	}
+
+	return valueBool
+}
+
+// invalid records a value that isn't of the expected type, a variable read more than once is reported once
+func (env *envLoader) invalid(key string, message string) {
+	if slices.ContainsFunc(env.problems, func(problem Problem) bool { return problem.Key == key }) {
+		return
+	}
+	env.problems = append(env.problems, Problem{Key: key, Message: message})
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Estimated bits of entropy a secret needs, see entropy
+const (
+	minJWTSecretEntropy  = 128
+	minDBPasswordEntropy = 64
+)
+
+// Problem is a configuration value that is missing, invalid or insecure
+type Problem struct {
+	Key     string
+	Message string
+
+	// Blocking is set when the application can't run with the value at all, which fails startup in every mode
+	Blocking bool
+}
+
+func (problem Problem) String() string {
+	return problem.Key + " " + problem.Message
+}
+
+// ValidationError holds every problem that stops the application from starting
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (err *ValidationError) Error() string {
+	problems := make([]string, 0, len(err.Problems))
+	for _, problem := range err.Problems {
+		problems = append(problems, problem.String())
+	}
+	return "invalid configuration: " + strings.Join(problems, "; ")
+}
+
+// Check validates the configuration
+// In release mode every problem fails startup and is returned in a *ValidationError, in debug mode only the blocking ones are,
+// the others are returned as warnings
+func (cfg *Config) Check() ([]Problem, error) {
+	var warnings, failures []Problem
+	for _, problem := range cfg.Validate() {
+		if problem.Blocking || cfg.App.Mode == ModeRelease {
+			failures = append(failures, problem)
+		} else {
+			warnings = append(warnings, problem)
+		}
+	}
+
+	if len(failures) > 0 {
+		return warnings, &ValidationError{Problems: failures}
+	}
+	return warnings, nil
+}
+
+// Validate returns every problem of the configuration: required keys, types, ranges and the strength of the secrets
+func (cfg *Config) Validate() []Problem {
+	v := &validator{problems: append([]Problem(nil), cfg.problems...)}
+
+	v.required("APP_NAME", cfg.App.Name)
+	v.required("APP_URL", cfg.App.URL)
+	v.oneOf("GIN_MODE", cfg.App.Mode, ModeDebug, ModeRelease, ModeTest)
+	if port, err := strconv.Atoi(cfg.App.Port); err != nil {
+		v.add("PORT", fmt.Sprintf("is not an integer: %q", cfg.App.Port))
+	} else {
+		v.between("PORT", port, 1, 65535)
+	}
+
+	switch cfg.Database.Driver {
+	case DriverPostgres:
+		v.required("DB_HOST", cfg.Database.Host)
+		v.between("DB_PORT", cfg.Database.Port, 1, 65535)
+		v.required("DB_USERNAME", cfg.Database.Username)
+		v.required("DB_NAME", cfg.Database.Name)
+		v.secret("DB_PASSWORD", cfg.Database.Password, minDBPasswordEntropy)
+	case DriverSQLite:
+		v.required("DB_PATH", cfg.Database.Path)
+	default:
+		v.oneOf("DB_DRIVER", cfg.Database.Driver, DriverPostgres, DriverSQLite)
+	}
+
+	v.between("JWT_EXP_IN_HOUR", cfg.JWT.TTLInHour, 1, math.MaxInt)
+	switch cfg.JWT.Algorithm {
+	case AlgorithmHS256:
+		v.secret("JWT_SECRET", string(cfg.JWT.Secret), minJWTSecretEntropy)
+	case AlgorithmRS256, AlgorithmEdDSA:
+		v.required("JWT_KEYS_DIR", cfg.JWT.KeysDir)
+		v.notNegative("JWT_KEY_ROTATION_IN_DAY", cfg.JWT.KeyRotation)
+	default:
+		v.oneOf("JWT_ALGORITHM", cfg.JWT.Algorithm, AlgorithmHS256, AlgorithmRS256, AlgorithmEdDSA)
+	}
+	v.oneOf("JWT_DENYLIST", cfg.JWT.Denylist, DenylistMemory, DenylistDatabase)
+	v.notNegative("JWT_DENYLIST_PURGE_INTERVAL_IN_MINUTE", cfg.JWT.DenylistPurgeInterval)
+
+	v.notNegative("TRASH_RETENTION_IN_DAY", cfg.Trash.Retention)
+	v.notNegative("TRASH_PURGE_INTERVAL_IN_MINUTE", cfg.Trash.PurgeInterval)
+
+	v.between("TODO_MAX_SUBTASK_DEPTH", cfg.Todo.MaxSubtaskDepth, 0, math.MaxInt)
+	v.notNegative("TODO_UNDO_WINDOW_IN_SECOND", cfg.Todo.UndoWindow)
+
+	return v.problems
+}
+
+// validator collects the problems of Validate
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) add(key string, message string) {
+	v.problems = append(v.problems, Problem{Key: key, Message: message})
+}
+
+func (v *validator) required(key string, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(key, "is required")
+	}
+}
+
+// oneOf reports an unsupported value, which the application can't run with
+func (v *validator) oneOf(key string, value string, supported ...string) {
+	for _, s := range supported {
+		if value == s {
+			return
+		}
+	}
+	v.problems = append(v.problems, Problem{
+		Key:      key,
+		Message:  fmt.Sprintf("is not supported: %q, expected one of %s", value, strings.Join(supported, ", ")),
+		Blocking: true,
+	})
+}
+
+func (v *validator) between(key string, value int, min int, max int) {
+	switch {
+	case value < min:
+		v.add(key, fmt.Sprintf("must be at least %d, got %d", min, value))
+	case value > max:
+		v.add(key, fmt.Sprintf("must be at most %d, got %d", max, value))
+	}
+}
+
+func (v *validator) notNegative(key string, value time.Duration) {
+	if value < 0 {
+		v.add(key, "must not be negative")
+	}
+}
+
+// secret reports a secret that is empty, the publicly known default or too easy to guess
+func (v *validator) secret(key string, value string, minEntropy float64) {
+	switch {
+	case value == "":
+		v.add(key, "is required")
+	case value == defaultValues[key]:
+		v.add(key, "is the publicly known default value")
+	case entropy(value) < minEntropy:
+		v.add(key, fmt.Sprintf("is too weak: about %.0f bits of entropy, at least %.0f needed", entropy(value), minEntropy))
+	}
+}
+
+// entropy estimates the bits of entropy of a secret from the frequency of its characters
+// It overestimates the strength of words and patterns, but tells short or repetitive secrets apart from random ones
+func entropy(secret string) float64 {
+	counts := make(map[rune]int)
+	length := 0
+	for _, r := range secret {
+		counts[r]++
+		length++
+	}
+
+	bitsPerChar := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(length)
+		bitsPerChar -= p * math.Log2(p)
+	}
+	return bitsPerChar * float64(length)
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+)
+
+const strongSecret = "q8Vn3rT0zLx5KcW2pYh7MjB4dFs9GuA1eN6oRiZ"
+
+// loadTestConfig loads the configuration from the defaults and the given environment variables only
+func loadTestConfig(t *testing.T, env map[string]string) *Config {
+	t.Helper()
+
+	for key := range defaultValues {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return cfg
+}
+
+func problemKeys(problems []Problem) []string {
+	keys := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		keys = append(keys, problem.Key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestConfigCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantWarnings []string
+		wantErrors   []string
+	}{
+		{
+			name:       "default secrets fail startup in release mode",
+			env:        map[string]string{},
+			wantErrors: []string{"DB_PASSWORD", "JWT_SECRET"},
+		},
+		{
+			name:         "default secrets are only warned about in debug mode",
+			env:          map[string]string{"GIN_MODE": "debug"},
+			wantWarnings: []string{"DB_PASSWORD", "JWT_SECRET"},
+		},
+		{
+			name: "strong secrets pass in release mode",
+			env:  map[string]string{"DB_PASSWORD": strongSecret, "JWT_SECRET": strongSecret},
+		},
+		{
+			name:       "repetitive secret",
+			env:        map[string]string{"DB_PASSWORD": strongSecret, "JWT_SECRET": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+			wantErrors: []string{"JWT_SECRET"},
+		},
+		{
+			name:       "the secret isn't checked with an asymmetric algorithm",
+			env:        map[string]string{"DB_DRIVER": "sqlite", "JWT_ALGORITHM": "EdDSA", "JWT_KEYS_DIR": ""},
+			wantErrors: []string{"JWT_KEYS_DIR"},
+		},
+		{
+			name: "types and ranges",
+			env: map[string]string{
+				"GIN_MODE":               "debug",
+				"DB_DRIVER":              "sqlite",
+				"JWT_SECRET":             strongSecret,
+				"PORT":                   "http",
+				"JWT_EXP_IN_HOUR":        "0",
+				"DB_AUTO_MIGRATE":        "sometimes",
+				"TODO_MAX_SUBTASK_DEPTH": "-1",
+				"TRASH_RETENTION_IN_DAY": "-30",
+			},
+			wantWarnings: []string{"DB_AUTO_MIGRATE", "JWT_EXP_IN_HOUR", "PORT", "TODO_MAX_SUBTASK_DEPTH", "TRASH_RETENTION_IN_DAY"},
+		},
+		{
+			name:         "unsupported values fail startup in debug mode too",
+			env:          map[string]string{"GIN_MODE": "debug", "DB_DRIVER": "mysql", "JWT_DENYLIST": "redis"},
+			wantWarnings: []string{"JWT_SECRET"},
+			wantErrors:   []string{"DB_DRIVER", "JWT_DENYLIST"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, tt.env)
+
+			warnings, err := cfg.Check()
+			if got := problemKeys(warnings); !slices.Equal(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", warnings, tt.wantWarnings)
+			}
+
+			var invalid *ValidationError
+			if len(tt.wantErrors) == 0 {
+				if err != nil {
+					t.Errorf("err = %v, want none", err)
+				}
+				return
+			}
+			if !errors.As(err, &invalid) {
+				t.Fatalf("err = %v, want a *ValidationError", err)
+			}
+			if got := problemKeys(invalid.Problems); !slices.Equal(got, tt.wantErrors) {
+				t.Errorf("problems = %v, want %v", invalid.Problems, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestConfigSettings(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"JWT_SECRET": strongSecret, "DB_PORT": "6543"})
+
+	settings := make(map[string]Setting)
+	for _, setting := range cfg.Settings() {
+		settings[setting.Key] = setting
+	}
+	if len(settings) != len(defaultValues) {
+		t.Errorf("settings has %d keys, want every one of the %d variables", len(settings), len(defaultValues))
+	}
+	if got := settings["JWT_SECRET"]; got.Value != Redacted || got.Default {
+		t.Errorf("JWT_SECRET setting = %+v, want the set value redacted", got)
+	}
+	if got := settings["DB_PASSWORD"]; got.Value != Redacted || !got.Default {
+		t.Errorf("DB_PASSWORD setting = %+v, want the default value redacted", got)
+	}
+	if got := settings["DB_PORT"]; got.Value != "6543" || got.Default {
+		t.Errorf("DB_PORT setting = %+v, want 6543 from the environment", got)
+	}
+}
@@ -1,8 +1,11 @@
 package database
 
 import (
+	"time"
+
 	"github.com/Alfian57/golang-todo/pkg/config"
 	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -10,22 +13,56 @@ import (
 // New creates a new database connection with the given configuration
 // This function should be called once in main.go and the db instance should be passed to repositories
 func New(cfg *config.Config, log logger.Logger) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(cfg.Database.DSN), &gorm.Config{})
+	fields := connectionFields(cfg.Database)
+
+	// TranslateError turns driver specific errors such as unique violations into gorm.ErrDuplicatedKey
+	gormConfig := &gorm.Config{TranslateError: true}
+	var dialector gorm.Dialector
+	switch cfg.Database.Driver {
+	case config.DriverSQLite:
+		dialector = sqlite.Open(cfg.Database.DSN)
+		// SQLite stores timestamps as text, so they must share one offset to compare correctly
+		gormConfig.NowFunc = func() time.Time {
+			return time.Now().UTC()
+		}
+	default:
+		dialector = postgres.Open(cfg.Database.DSN)
+	}
+
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
-		log.Error("Failed to connect to database",
-			logger.F("host", cfg.Database.Host),
-			logger.F("database", cfg.Database.Name),
-			logger.F("port", cfg.Database.Port),
-			logger.F("error", err),
-		)
+		log.Error("Failed to connect to database", append(fields, logger.F("error", err))...)
 		return nil, err
 	}
 
-	log.Info("Successfully connected to database",
-		logger.F("host", cfg.Database.Host),
-		logger.F("database", cfg.Database.Name),
-		logger.F("port", cfg.Database.Port),
-	)
+	// Every connection to ":memory:" opens its own empty database, so keep a single one
+	if cfg.Database.IsSQLiteMemory() {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+
+	log.Info("Successfully connected to database", fields...)
 
 	return db, nil
 }
+
+func connectionFields(cfg config.DatabaseConfig) []logger.Field {
+	if cfg.Driver == config.DriverSQLite {
+		return []logger.Field{
+			logger.F("driver", cfg.Driver),
+			logger.F("path", cfg.Path),
+		}
+	}
+
+	return []logger.Field{
+		logger.F("driver", cfg.Driver),
+		logger.F("host", cfg.Host),
+		logger.F("database", cfg.Name),
+		logger.F("port", cfg.Port),
+	}
+}
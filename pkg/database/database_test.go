@@ -0,0 +1,72 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/google/uuid"
+)
+
+func TestNewSQLiteMemory(t *testing.T) {
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Driver: config.DriverSQLite,
+			Path:   config.SQLiteMemory,
+			DSN:    config.SQLiteDSN(config.SQLiteMemory),
+		},
+	}
+
+	db, err := New(cfg, logger.NewNopLogger())
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+
+	err = db.Exec(`CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE TABLE todos (
+    id TEXT PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);`).Error
+	if err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+
+	type row struct {
+		ID        uuid.UUID
+		Username  string
+		Password  string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	// UUID and timestamp columns round-trip through their text representation
+	created := row{ID: uuid.New(), Username: "alice", Password: "hash"}
+	if err := db.Table("users").Create(&created).Error; err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+
+	var loaded row
+	if err := db.Table("users").Where("id = ?", created.ID).First(&loaded).Error; err != nil {
+		t.Fatalf("failed to load user: %v", err)
+	}
+	if loaded.ID != created.ID || !loaded.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("loaded %+v, want %+v", loaded, created)
+	}
+
+	// Foreign keys are enforced
+	err = db.Exec("INSERT INTO todos (id, title, user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		uuid.New(), "orphan", uuid.New(), time.Now(), time.Now()).Error
+	if err == nil {
+		t.Errorf("expected a foreign key violation")
+	}
+}
@@ -0,0 +1,49 @@
+// Package databasetest provides in-memory databases for tests
+package databasetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/Alfian57/golang-todo/pkg/database"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/migrate"
+	"github.com/Alfian57/golang-todo/pkg/module"
+	"gorm.io/gorm"
+)
+
+// NewSQLite opens an in-memory SQLite database with the migrations of the given modules applied
+// The database is closed when the test finishes
+func NewSQLite(t testing.TB, modules ...module.Module) *gorm.DB {
+	t.Helper()
+
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Driver: config.DriverSQLite,
+			Path:   config.SQLiteMemory,
+			DSN:    config.SQLiteDSN(config.SQLiteMemory),
+		},
+	}
+	log := logger.NewNopLogger()
+
+	db, err := database.New(cfg, log)
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	runner, err := migrate.NewModuleRunner(db, config.DriverSQLite, log, modules)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if err := runner.Up(context.Background()); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return db
+}
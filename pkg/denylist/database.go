@@ -0,0 +1,52 @@
+package denylist
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokedToken is a denylisted access token, the revoked_tokens table is created by the auth module's migrations
+type RevokedToken struct {
+	TokenID   string `gorm:"primaryKey"`
+	ExpiresAt time.Time
+}
+
+// DatabaseDenylist is a Denylist kept in the database, shared by every instance of the API
+type DatabaseDenylist struct {
+	db *gorm.DB
+}
+
+var _ Denylist = DatabaseDenylist{}
+
+func NewDatabaseDenylist(db *gorm.DB) DatabaseDenylist {
+	return DatabaseDenylist{
+		db: db,
+	}
+}
+
+// Add revokes the token, revoking a token twice is not an error
+func (denylist DatabaseDenylist) Add(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	revokedToken := RevokedToken{
+		TokenID:   tokenID,
+		ExpiresAt: expiresAt.UTC(),
+	}
+	return denylist.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&revokedToken).Error
+}
+
+func (denylist DatabaseDenylist) Contains(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+	err := denylist.db.WithContext(ctx).Model(&RevokedToken{}).
+		Where("token_id = ? AND expires_at > ?", tokenID, time.Now().UTC()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (denylist DatabaseDenylist) Purge(ctx context.Context) (int, error) {
+	result := denylist.db.WithContext(ctx).
+		Where("expires_at <= ?", time.Now().UTC()).
+		Delete(&RevokedToken{})
+	return int(result.RowsAffected), result.Error
+}
@@ -0,0 +1,30 @@
+package denylist
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"gorm.io/gorm"
+)
+
+// Denylist holds the IDs of access tokens that were revoked before they expired
+// An entry only lives as long as its token, since an expired token is rejected anyway
+type Denylist interface {
+	// Add revokes the token until it expires
+	Add(ctx context.Context, tokenID string, expiresAt time.Time) error
+
+	// Contains reports whether the token was revoked and hasn't expired yet
+	Contains(ctx context.Context, tokenID string) (bool, error)
+
+	// Purge drops the entries whose token has expired and returns how many there were
+	Purge(ctx context.Context) (int, error)
+}
+
+// New creates the denylist chosen by the configuration
+func New(cfg config.JWTConfig, db *gorm.DB) Denylist {
+	if cfg.Denylist == config.DenylistDatabase {
+		return NewDatabaseDenylist(db)
+	}
+	return NewMemoryDenylist()
+}
@@ -0,0 +1,60 @@
+package denylist_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/internal/auth"
+	"github.com/Alfian57/golang-todo/pkg/database/databasetest"
+	"github.com/Alfian57/golang-todo/pkg/denylist"
+)
+
+func TestDenylist(t *testing.T) {
+	denylists := map[string]func(t *testing.T) denylist.Denylist{
+		"memory": func(t *testing.T) denylist.Denylist {
+			return denylist.NewMemoryDenylist()
+		},
+		// The revoked_tokens table is created by the auth module
+		"database": func(t *testing.T) denylist.Denylist {
+			return denylist.NewDatabaseDenylist(databasetest.NewSQLite(t, auth.Module{}))
+		},
+	}
+
+	for name, newDenylist := range denylists {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			tokens := newDenylist(t)
+
+			if err := tokens.Add(ctx, "revoked", time.Now().Add(time.Hour)); err != nil {
+				t.Fatalf("failed to add token: %v", err)
+			}
+			// Revoking a token twice is not an error
+			if err := tokens.Add(ctx, "revoked", time.Now().Add(time.Hour)); err != nil {
+				t.Fatalf("failed to add token again: %v", err)
+			}
+			if err := tokens.Add(ctx, "expired", time.Now().Add(-time.Minute)); err != nil {
+				t.Fatalf("failed to add expired token: %v", err)
+			}
+
+			for tokenID, want := range map[string]bool{"revoked": true, "expired": false, "unknown": false} {
+				got, err := tokens.Contains(ctx, tokenID)
+				if err != nil {
+					t.Fatalf("failed to look up %s: %v", tokenID, err)
+				}
+				if got != want {
+					t.Errorf("contains %s = %v, want %v", tokenID, got, want)
+				}
+			}
+
+			// Only the entry of the expired token is purged
+			purged, err := tokens.Purge(ctx)
+			if err != nil || purged != 1 {
+				t.Errorf("purged = %d (%v), want 1", purged, err)
+			}
+			if revoked, _ := tokens.Contains(ctx, "revoked"); !revoked {
+				t.Errorf("purge dropped a token that hasn't expired")
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package denylist
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryDenylist is a thread-safe Denylist kept in the process
+// Every instance has its own, so it is only enough when the API runs as a single instance
+type MemoryDenylist struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+var _ Denylist = (*MemoryDenylist)(nil)
+
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{
+		entries: make(map[string]time.Time),
+	}
+}
+
+func (denylist *MemoryDenylist) Add(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	denylist.mu.Lock()
+	defer denylist.mu.Unlock()
+
+	denylist.entries[tokenID] = expiresAt
+	return nil
+}
+
+func (denylist *MemoryDenylist) Contains(ctx context.Context, tokenID string) (bool, error) {
+	denylist.mu.RLock()
+	defer denylist.mu.RUnlock()
+
+	expiresAt, exists := denylist.entries[tokenID]
+	return exists && time.Now().Before(expiresAt), nil
+}
+
+func (denylist *MemoryDenylist) Purge(ctx context.Context) (int, error) {
+	denylist.mu.Lock()
+	defer denylist.mu.Unlock()
+
+	now := time.Now()
+	purged := 0
+	for tokenID, expiresAt := range denylist.entries {
+		if !now.Before(expiresAt) {
+			delete(denylist.entries, tokenID)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,406 @@
+// Package jsonpatch applies JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902) documents
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types of the patch formats
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported patch media type")
+	ErrInvalidPatch    = errors.New("invalid patch")
+	ErrTestFailed      = errors.New("patch test failed")
+)
+
+// Patch changes a JSON document
+type Patch interface {
+	Apply(document []byte) ([]byte, error)
+}
+
+// Parse parses a patch in the format of its media type, plain JSON is read as a merge patch
+func Parse(mediaType string, body []byte) (Patch, error) {
+	switch mediaType {
+	case MergePatchType, "application/json":
+		return ParseMergePatch(body)
+	case JSONPatchType:
+		return ParseJSONPatch(body)
+	}
+	return nil, ErrUnsupportedType
+}
+
+// MergePatch is a JSON Merge Patch, its members replace those of the document and null removes them
+type MergePatch struct {
+	value any
+}
+
+func ParseMergePatch(body []byte) (MergePatch, error) {
+	var value any
+	if err := decode(body, &value); err != nil {
+		return MergePatch{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return MergePatch{value: value}, nil
+}
+
+func (patch MergePatch) Apply(document []byte) ([]byte, error) {
+	var target any
+	if err := decode(document, &target); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(target, patch.value))
+}
+
+func mergePatch(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any, len(patchObject))
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergePatch(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
+
+// Operation is one step of a JSON Patch
+// Value is empty when the operation has none and holds null when it is null
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch is a JSON Patch, its operations are applied in order and fail as a whole
+type JSONPatch []Operation
+
+func ParseJSONPatch(body []byte) (JSONPatch, error) {
+	var patch JSONPatch
+	if err := decode(body, &patch); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	for i, operation := range patch {
+		if err := operation.validate(); err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+		}
+	}
+	return patch, nil
+}
+
+func (operation Operation) validate() error {
+	switch operation.Op {
+	case "add", "replace", "test":
+		if len(operation.Value) == 0 {
+			return fmt.Errorf("%s needs a value", operation.Op)
+		}
+	case "move", "copy":
+		if _, err := parsePointer(operation.From); err != nil {
+			return err
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unknown op %q", operation.Op)
+	}
+	_, err := parsePointer(operation.Path)
+	return err
+}
+
+func (patch JSONPatch) Apply(document []byte) ([]byte, error) {
+	var target any
+	if err := decode(document, &target); err != nil {
+		return nil, err
+	}
+
+	for i, operation := range patch {
+		var err error
+		if target, err = operation.apply(target); err != nil {
+			if errors.Is(err, ErrTestFailed) {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+			return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+func (operation Operation) apply(target any) (any, error) {
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if len(operation.Value) > 0 {
+		if err := decode(operation.Value, &value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch operation.Op {
+	case "add":
+		return add(target, path, value)
+	case "remove":
+		target, _, err = remove(target, path)
+		return target, err
+	case "replace":
+		// Replacing is removing followed by adding, which requires the value to exist
+		if len(path) == 0 {
+			return value, nil
+		}
+		if target, _, err = remove(target, path); err != nil {
+			return nil, err
+		}
+		return add(target, path, value)
+	case "move":
+		from, _ := parsePointer(operation.From)
+		if len(from) < len(path) && isPrefix(from, path) {
+			return nil, fmt.Errorf("cannot move %s into itself", operation.From)
+		}
+		target, value, err = remove(target, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(target, path, value)
+	case "copy":
+		from, _ := parsePointer(operation.From)
+		value, err := get(target, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(target, path, deepCopy(value))
+	case "test":
+		current, err := get(target, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, fmt.Errorf("%w: %s does not match", ErrTestFailed, operation.Path)
+		}
+		return target, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", operation.Op)
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q does not start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex parses the index of an array element, "-" is the end of the array where elements can be added
+func arrayIndex(token string, length int, end bool) (int, error) {
+	if end && token == "-" {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') || token[0] == '+' {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index > length || (!end && index == length) {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+func get(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q does not exist", token)
+			}
+			node = value
+		case []any:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			node = container[index]
+		default:
+			return nil, fmt.Errorf("cannot reference %q in a scalar", token)
+		}
+	}
+	return node, nil
+}
+
+// update walks to the parent of the path and replaces it with the result of change
+// Arrays are replaced rather than changed in place, so every container on the way is set again
+func update(node any, path []string, change func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return change(node, path[0])
+	}
+
+	child, err := get(node, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = update(child, path[1:], change)
+	if err != nil {
+		return nil, err
+	}
+	switch container := node.(type) {
+	case map[string]any:
+		container[path[0]] = child
+	case []any:
+		index, _ := arrayIndex(path[0], len(container), false)
+		container[index] = child
+	}
+	return node, nil
+}
+
+func add(node any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(node, path, func(parent any, token string) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			container[token] = value
+			return container, nil
+		case []any:
+			index, err := arrayIndex(token, len(container), true)
+			if err != nil {
+				return nil, err
+			}
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil
+		}
+		return nil, fmt.Errorf("cannot add %q to a scalar", token)
+	})
+}
+
+// remove removes the value at the path and returns it
+func remove(node any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+	var removed any
+	node, err := update(node, path, func(parent any, token string) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q does not exist", token)
+			}
+			removed = value
+			delete(container, token)
+			return container, nil
+		case []any:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = container[index]
+			return append(container[:index], container[index+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove %q from a scalar", token)
+	})
+	return node, removed, err
+}
+
+func deepCopy(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(value))
+		for name, member := range value {
+			copied[name] = deepCopy(member)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(value))
+		for i, element := range value {
+			copied[i] = deepCopy(element)
+		}
+		return copied
+	}
+	return value
+}
+
+// equal compares JSON values, numbers are equal when their values are rather than their text
+func equal(a any, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		return errA == nil && errB == nil && x == y
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for name, member := range a {
+			other, ok := b[name]
+			if !ok || !equal(member, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// decode decodes exactly one JSON value, keeping numbers as they were written
+func decode(data []byte, value any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(value); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("unexpected data after the JSON value")
+	}
+	return nil
+}
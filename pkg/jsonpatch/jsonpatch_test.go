@@ -0,0 +1,235 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// assertJSON compares JSON documents regardless of member order and formatting
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+
+	var gotValue, wantValue any
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("invalid result %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid expectation %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("result = %s, want %s", got, want)
+	}
+}
+
+// The examples of RFC 7396 appendix A
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		document string
+		patch    string
+		want     string
+	}{
+		{document: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{document: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{document: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{document: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{document: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{document: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{document: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{document: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{document: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{document: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{document: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{document: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{document: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{document: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range tests {
+		patch, err := Parse(MergePatchType, []byte(test.patch))
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", test.patch, err)
+		}
+		got, err := patch.Apply([]byte(test.document))
+		if err != nil {
+			t.Fatalf("failed to apply %s to %s: %v", test.patch, test.document, err)
+		}
+		assertJSON(t, got, test.want)
+	}
+}
+
+// Mostly the examples of RFC 6902 appendix A
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "adds a member",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz","value":"qux"}]`,
+			want:     `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:     "adds an array element",
+			document: `{"foo":["bar","baz"]}`,
+			patch:    `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			want:     `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:     "appends to an array",
+			document: `{"foo":["bar"]}`,
+			patch:    `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			want:     `{"foo":["bar",["abc","def"]]}`,
+		},
+		{
+			name:     "removes a member",
+			document: `{"baz":"qux","foo":"bar"}`,
+			patch:    `[{"op":"remove","path":"/baz"}]`,
+			want:     `{"foo":"bar"}`,
+		},
+		{
+			name:     "removes an array element",
+			document: `{"foo":["bar","qux","baz"]}`,
+			patch:    `[{"op":"remove","path":"/foo/1"}]`,
+			want:     `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:     "replaces a value",
+			document: `{"baz":"qux","foo":"bar"}`,
+			patch:    `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			want:     `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:     "replaces a value with null",
+			document: `{"baz":"qux"}`,
+			patch:    `[{"op":"replace","path":"/baz","value":null}]`,
+			want:     `{"baz":null}`,
+		},
+		{
+			name:     "moves a value",
+			document: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch:    `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			want:     `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:     "moves an array element",
+			document: `{"foo":["all","grass","cows","eat"]}`,
+			patch:    `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			want:     `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:     "copies a value",
+			document: `{"foo":{"bar":1}}`,
+			patch:    `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`,
+			want:     `{"foo":{"bar":1},"baz":{"bar":2}}`,
+		},
+		{
+			name:     "passes a test",
+			document: `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch:    `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`,
+			want:     `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:     "unescapes the path",
+			document: `{"/":9,"~1":10}`,
+			patch:    `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`,
+			want:     `{"~1":10}`,
+		},
+		{
+			name:     "replaces the whole document",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"replace","path":"","value":{"baz":"qux"}}]`,
+			want:     `{"baz":"qux"}`,
+		},
+		{
+			name:     "fails a test",
+			document: `{"baz":"qux"}`,
+			patch:    `[{"op":"replace","path":"/baz","value":"boo"},{"op":"test","path":"/baz","value":"qux"}]`,
+			wantErr:  ErrTestFailed,
+		},
+		{
+			name:     "rejects adding to a missing parent",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			wantErr:  ErrInvalidPatch,
+		},
+		{
+			name:     "rejects removing a missing member",
+			document: `{"foo":"bar"}`,
+			patch:    `[{"op":"remove","path":"/baz"}]`,
+			wantErr:  ErrInvalidPatch,
+		},
+		{
+			name:     "rejects an index past the end",
+			document: `{"foo":["bar"]}`,
+			patch:    `[{"op":"add","path":"/foo/2","value":"qux"}]`,
+			wantErr:  ErrInvalidPatch,
+		},
+		{
+			name:     "rejects an index with a leading zero",
+			document: `{"foo":["bar","baz"]}`,
+			patch:    `[{"op":"replace","path":"/foo/01","value":"qux"}]`,
+			wantErr:  ErrInvalidPatch,
+		},
+		{
+			name:     "rejects moving a value into itself",
+			document: `{"foo":{"bar":1}}`,
+			patch:    `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`,
+			wantErr:  ErrInvalidPatch,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patch, err := Parse(JSONPatchType, []byte(test.patch))
+			if err != nil {
+				t.Fatalf("failed to parse %s: %v", test.patch, err)
+			}
+			got, err := patch.Apply([]byte(test.document))
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("error = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to apply: %v", err)
+			}
+			assertJSON(t, got, test.want)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		body      string
+		wantErr   error
+	}{
+		{name: "merge patch", mediaType: MergePatchType, body: `{"title":"Walk dog"}`},
+		{name: "plain JSON", mediaType: "application/json", body: `{"title":"Walk dog"}`},
+		{name: "JSON patch", mediaType: JSONPatchType, body: `[{"op":"remove","path":"/title"}]`},
+		{name: "unknown media type", mediaType: "text/plain", body: `{}`, wantErr: ErrUnsupportedType},
+		{name: "malformed merge patch", mediaType: MergePatchType, body: `{"title":`, wantErr: ErrInvalidPatch},
+		{name: "trailing data", mediaType: MergePatchType, body: `{} {}`, wantErr: ErrInvalidPatch},
+		{name: "JSON patch that isn't an array", mediaType: JSONPatchType, body: `{"op":"remove","path":"/title"}`, wantErr: ErrInvalidPatch},
+		{name: "unknown op", mediaType: JSONPatchType, body: `[{"op":"merge","path":"/title"}]`, wantErr: ErrInvalidPatch},
+		{name: "op without a value", mediaType: JSONPatchType, body: `[{"op":"add","path":"/title"}]`, wantErr: ErrInvalidPatch},
+		{name: "relative path", mediaType: JSONPatchType, body: `[{"op":"remove","path":"title"}]`, wantErr: ErrInvalidPatch},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.mediaType, []byte(test.body))
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
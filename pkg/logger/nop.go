@@ -0,0 +1,16 @@
+package logger
+
+// NopLogger is a Logger that discards every message
+// It is meant for tests and tools that don't need log output
+type NopLogger struct{}
+
+// NewNopLogger creates a new NopLogger
+func NewNopLogger() Logger {
+	return NopLogger{}
+}
+
+func (NopLogger) Info(msg string, fields ...Field)  {}
+func (NopLogger) Debug(msg string, fields ...Field) {}
+func (NopLogger) Warn(msg string, fields ...Field)  {}
+func (NopLogger) Error(msg string, fields ...Field) {}
+func (NopLogger) Fatal(msg string, fields ...Field) {}
@@ -1,16 +1,31 @@
 package middleware
 
 import (
+	"context"
+
 	"github.com/Alfian57/golang-todo/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
+// TokenValidator rejects access tokens that were revoked before they expired
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, claims *utils.Claims) error
+}
+
 // AuthMiddleware creates a middleware that validates JWT tokens
-func AuthMiddleware(jwtUtils *utils.JWTUtils, isDebug bool) gin.HandlerFunc {
+// It panics without a validator, since revoked tokens would then be accepted until they expire
+func AuthMiddleware(jwtUtils *utils.JWTUtils, validator TokenValidator, isDebug bool) gin.HandlerFunc {
+	if validator == nil {
+		panic("middleware: AuthMiddleware needs a TokenValidator")
+	}
+
 	return func(ctx *gin.Context) {
 		apiKey := ctx.GetHeader("Authorization")
 
 		claims, err := jwtUtils.ParseJWT(apiKey)
+		if err == nil {
+			err = validator.ValidateToken(ctx, claims)
+		}
 		if err != nil {
 			response := utils.UnauthorizedResponse("Unauthorized", err, isDebug)
 			ctx.JSON(response.StatusCode, response)
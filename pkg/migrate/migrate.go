@@ -0,0 +1,373 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// schemaTable records the versions that have been applied
+const schemaTable = "schema_versions"
+
+// legacySchemaTable is the table of the golang-migrate CLI that was used before the built-in runner
+const legacySchemaTable = "schema_migrations"
+
+// advisoryLockKey identifies the Postgres advisory lock held while migrating
+const advisoryLockKey = 7_402_118_653
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var ErrUnknownVersion = errors.New("unknown migration version")
+
+// Migration is a versioned pair of up and down SQL scripts
+type Migration struct {
+	Version uint
+	Name    string
+	Source  string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration has been applied and when
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// Runner applies and rolls back migrations, tracking applied versions in the schema table
+type Runner struct {
+	db         *gorm.DB
+	driver     string
+	migrations []Migration
+	log        logger.Logger
+}
+
+type appliedVersion struct {
+	Version   uint
+	Name      string
+	AppliedAt time.Time
+}
+
+// NewRunner loads the migrations for the driver from each named source
+// A source holds one directory per driver, such as postgres/000001_create_users_table.up.sql
+// Versions are global, so two sources must never use the same version
+func NewRunner(db *gorm.DB, driver string, log logger.Logger, sources map[string]fs.FS) (*Runner, error) {
+	byVersion := make(map[uint]*Migration)
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, sourceName := range names {
+		source := sources[sourceName]
+		if source == nil {
+			continue
+		}
+
+		entries, err := fs.ReadDir(source, driver)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s migrations: %w", sourceName, err)
+		}
+
+		for _, entry := range entries {
+			match := fileNamePattern.FindStringSubmatch(entry.Name())
+			if entry.IsDir() || match == nil {
+				continue
+			}
+
+			version, err := strconv.ParseUint(match[1], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+			}
+
+			sql, err := fs.ReadFile(source, path.Join(driver, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+			}
+
+			migration, exists := byVersion[uint(version)]
+			if !exists {
+				migration = &Migration{Version: uint(version), Name: match[2], Source: sourceName}
+				byVersion[uint(version)] = migration
+			}
+			if migration.Source != sourceName || migration.Name != match[2] {
+				return nil, fmt.Errorf("migration version %d is used by both %s/%s and %s/%s",
+					version, migration.Source, migration.Name, sourceName, match[2])
+			}
+
+			if match[3] == "up" {
+				migration.Up = string(sql)
+			} else {
+				migration.Down = string(sql)
+			}
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return &Runner{
+		db:         db,
+		driver:     driver,
+		migrations: migrations,
+		log:        log,
+	}, nil
+}
+
+// Migrations returns every known migration ordered by version
+func (runner *Runner) Migrations() []Migration {
+	return runner.migrations
+}
+
+// Up applies every pending migration
+func (runner *Runner) Up(ctx context.Context) error {
+	return runner.To(ctx, runner.latestVersion())
+}
+
+// Down rolls back the given number of most recently applied migrations
+func (runner *Runner) Down(ctx context.Context, steps int) error {
+	return runner.withLock(ctx, func(tx *gorm.DB) error {
+		applied, err := runner.appliedVersions(tx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(runner.migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := runner.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := runner.rollback(tx, migration); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// To migrates up or down so that exactly the migrations up to version are applied
+// Version 0 rolls back every migration
+func (runner *Runner) To(ctx context.Context, version uint) error {
+	if version != 0 && !runner.hasVersion(version) {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return runner.withLock(ctx, func(tx *gorm.DB) error {
+		applied, err := runner.appliedVersions(tx)
+		if err != nil {
+			return err
+		}
+
+		// Roll back newer migrations first, newest to oldest
+		for i := len(runner.migrations) - 1; i >= 0; i-- {
+			migration := runner.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := runner.rollback(tx, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, migration := range runner.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := runner.apply(tx, migration); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every known migration and whether it has been applied
+func (runner *Runner) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := runner.withLock(ctx, func(tx *gorm.DB) error {
+		applied, err := runner.appliedVersions(tx)
+		if err != nil {
+			return err
+		}
+
+		statuses = make([]MigrationStatus, 0, len(runner.migrations))
+		for _, migration := range runner.migrations {
+			status := MigrationStatus{Migration: migration}
+			if version, ok := applied[migration.Version]; ok {
+				appliedAt := version.AppliedAt
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+func (runner *Runner) apply(tx *gorm.DB, migration Migration) error {
+	runner.log.Info("Applying migration",
+		logger.F("version", migration.Version),
+		logger.F("name", migration.Name),
+	)
+
+	return tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Up).Error; err != nil {
+			return fmt.Errorf("apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		return tx.Table(schemaTable).Create(&appliedVersion{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+}
+
+func (runner *Runner) rollback(tx *gorm.DB, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+	}
+
+	runner.log.Info("Rolling back migration",
+		logger.F("version", migration.Version),
+		logger.F("name", migration.Name),
+	)
+
+	return tx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Down).Error; err != nil {
+			return fmt.Errorf("roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		return tx.Exec("DELETE FROM "+schemaTable+" WHERE version = ?", migration.Version).Error
+	})
+}
+
+// withLock runs fn on a single connection that holds the migration lock
+// Postgres uses a session advisory lock so that concurrent instances migrate one at a time
+// SQLite already serializes writers, so it needs no extra lock
+func (runner *Runner) withLock(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return runner.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		// A new session keeps chained calls from leaking conditions into each other
+		tx := conn.Session(&gorm.Session{})
+
+		if runner.driver == config.DriverPostgres {
+			if err := tx.Exec("SELECT pg_advisory_lock(?)", advisoryLockKey).Error; err != nil {
+				return fmt.Errorf("acquire migration lock: %w", err)
+			}
+			defer tx.Exec("SELECT pg_advisory_unlock(?)", advisoryLockKey)
+		}
+
+		if err := runner.ensureSchemaTable(tx); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+func (runner *Runner) ensureSchemaTable(tx *gorm.DB) error {
+	if tx.Migrator().HasTable(schemaTable) {
+		return nil
+	}
+
+	err := tx.Exec(`CREATE TABLE ` + schemaTable + ` (
+    version BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`).Error
+	if err != nil {
+		return fmt.Errorf("create %s table: %w", schemaTable, err)
+	}
+
+	return runner.adoptLegacyVersions(tx)
+}
+
+// adoptLegacyVersions records the migrations already applied by the golang-migrate CLI
+// so that existing databases are not migrated a second time
+func (runner *Runner) adoptLegacyVersions(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(legacySchemaTable) {
+		return nil
+	}
+
+	var legacy struct {
+		Version int64
+		Dirty   bool
+	}
+	err := tx.Table(legacySchemaTable).Select("version, dirty").Take(&legacy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", legacySchemaTable, err)
+	}
+	if legacy.Dirty {
+		return fmt.Errorf("%s is dirty at version %d, fix the database before migrating", legacySchemaTable, legacy.Version)
+	}
+
+	for _, migration := range runner.migrations {
+		if int64(migration.Version) > legacy.Version {
+			break
+		}
+		err := tx.Table(schemaTable).Create(&appliedVersion{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	runner.log.Info("Adopted versions applied by golang-migrate", logger.F("version", legacy.Version))
+	return nil
+}
+
+func (runner *Runner) appliedVersions(tx *gorm.DB) (map[uint]appliedVersion, error) {
+	var versions []appliedVersion
+	if err := tx.Table(schemaTable).Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("read %s: %w", schemaTable, err)
+	}
+
+	applied := make(map[uint]appliedVersion, len(versions))
+	for _, version := range versions {
+		applied[version.Version] = version
+	}
+	return applied, nil
+}
+
+func (runner *Runner) latestVersion() uint {
+	if len(runner.migrations) == 0 {
+		return 0
+	}
+	return runner.migrations[len(runner.migrations)-1].Version
+}
+
+func (runner *Runner) hasVersion(version uint) bool {
+	for _, migration := range runner.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
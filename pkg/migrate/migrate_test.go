@@ -0,0 +1,226 @@
+package migrate
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/Alfian57/golang-todo/pkg/database"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"gorm.io/gorm"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Driver: config.DriverSQLite,
+			Path:   config.SQLiteMemory,
+			DSN:    config.SQLiteDSN(config.SQLiteMemory),
+		},
+	}
+	db, err := database.New(cfg, logger.NewNopLogger())
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	return db
+}
+
+func testSources() map[string]fs.FS {
+	return map[string]fs.FS{
+		"users": fstest.MapFS{
+			"sqlite/000001_create_users.up.sql":     {Data: []byte("CREATE TABLE users (id TEXT PRIMARY KEY);")},
+			"sqlite/000001_create_users.down.sql":   {Data: []byte("DROP TABLE users;")},
+			"sqlite/000003_add_user_name.up.sql":    {Data: []byte("ALTER TABLE users ADD COLUMN name TEXT;")},
+			"sqlite/000003_add_user_name.down.sql":  {Data: []byte("ALTER TABLE users DROP COLUMN name;")},
+			"postgres/000001_create_users.up.sql":   {Data: []byte("not used by sqlite")},
+			"postgres/000001_create_users.down.sql": {Data: []byte("not used by sqlite")},
+		},
+		"notes": fstest.MapFS{
+			"sqlite/000002_create_notes.up.sql":   {Data: []byte("CREATE TABLE notes (id TEXT PRIMARY KEY);")},
+			"sqlite/000002_create_notes.down.sql": {Data: []byte("DROP TABLE notes;")},
+			"sqlite/README.md":                    {Data: []byte("ignored")},
+		},
+	}
+}
+
+func appliedList(t *testing.T, runner *Runner) []uint {
+	t.Helper()
+
+	statuses, err := runner.Status(context.Background())
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+
+	var applied []uint
+	for _, status := range statuses {
+		if status.Applied {
+			applied = append(applied, status.Version)
+		}
+	}
+	return applied
+}
+
+func TestRunner(t *testing.T) {
+	tests := []struct {
+		name        string
+		run         func(ctx context.Context, runner *Runner) error
+		wantApplied []uint
+		wantTables  map[string]bool
+	}{
+		{
+			name:        "up applies every migration across sources",
+			run:         func(ctx context.Context, runner *Runner) error { return runner.Up(ctx) },
+			wantApplied: []uint{1, 2, 3},
+			wantTables:  map[string]bool{"users": true, "notes": true},
+		},
+		{
+			name: "up is idempotent",
+			run: func(ctx context.Context, runner *Runner) error {
+				if err := runner.Up(ctx); err != nil {
+					return err
+				}
+				return runner.Up(ctx)
+			},
+			wantApplied: []uint{1, 2, 3},
+			wantTables:  map[string]bool{"users": true, "notes": true},
+		},
+		{
+			name: "down rolls back the latest migrations",
+			run: func(ctx context.Context, runner *Runner) error {
+				if err := runner.Up(ctx); err != nil {
+					return err
+				}
+				return runner.Down(ctx, 2)
+			},
+			wantApplied: []uint{1},
+			wantTables:  map[string]bool{"users": true, "notes": false},
+		},
+		{
+			name:        "to migrates up to a version",
+			run:         func(ctx context.Context, runner *Runner) error { return runner.To(ctx, 2) },
+			wantApplied: []uint{1, 2},
+			wantTables:  map[string]bool{"users": true, "notes": true},
+		},
+		{
+			name: "to zero rolls back everything",
+			run: func(ctx context.Context, runner *Runner) error {
+				if err := runner.Up(ctx); err != nil {
+					return err
+				}
+				return runner.To(ctx, 0)
+			},
+			wantApplied: nil,
+			wantTables:  map[string]bool{"users": false, "notes": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			runner, err := NewRunner(db, config.DriverSQLite, logger.NewNopLogger(), testSources())
+			if err != nil {
+				t.Fatalf("failed to create runner: %v", err)
+			}
+
+			if err := tt.run(context.Background(), runner); err != nil {
+				t.Fatalf("run failed: %v", err)
+			}
+
+			applied := appliedList(t, runner)
+			if len(applied) != len(tt.wantApplied) {
+				t.Fatalf("applied = %v, want %v", applied, tt.wantApplied)
+			}
+			for i := range applied {
+				if applied[i] != tt.wantApplied[i] {
+					t.Fatalf("applied = %v, want %v", applied, tt.wantApplied)
+				}
+			}
+			for table, want := range tt.wantTables {
+				if got := db.Migrator().HasTable(table); got != want {
+					t.Errorf("table %s exists = %v, want %v", table, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunnerFailedMigrationIsNotRecorded(t *testing.T) {
+	db := newTestDB(t)
+	sources := map[string]fs.FS{
+		"broken": fstest.MapFS{
+			"sqlite/000001_ok.up.sql":    {Data: []byte("CREATE TABLE ok (id TEXT);")},
+			"sqlite/000001_ok.down.sql":  {Data: []byte("DROP TABLE ok;")},
+			"sqlite/000002_bad.up.sql":   {Data: []byte("CREATE TABLE bad (id TEXT); THIS IS NOT SQL;")},
+			"sqlite/000002_bad.down.sql": {Data: []byte("DROP TABLE bad;")},
+		},
+	}
+	runner, err := NewRunner(db, config.DriverSQLite, logger.NewNopLogger(), sources)
+	if err != nil {
+		t.Fatalf("failed to create runner: %v", err)
+	}
+
+	if err := runner.Up(context.Background()); err == nil {
+		t.Fatalf("expected the broken migration to fail")
+	}
+	if applied := appliedList(t, runner); len(applied) != 1 || applied[0] != 1 {
+		t.Errorf("applied = %v, want [1]", applied)
+	}
+	if db.Migrator().HasTable("bad") {
+		t.Errorf("the failed migration was not rolled back")
+	}
+}
+
+func TestNewRunnerRejectsInvalidSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources map[string]fs.FS
+	}{
+		{
+			name: "duplicate version across sources",
+			sources: map[string]fs.FS{
+				"a": fstest.MapFS{"sqlite/000001_a.up.sql": {Data: []byte("SELECT 1;")}},
+				"b": fstest.MapFS{"sqlite/000001_b.up.sql": {Data: []byte("SELECT 1;")}},
+			},
+		},
+		{
+			name: "down script without up script",
+			sources: map[string]fs.FS{
+				"a": fstest.MapFS{"sqlite/000001_a.down.sql": {Data: []byte("SELECT 1;")}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRunner(newTestDB(t), config.DriverSQLite, logger.NewNopLogger(), tt.sources)
+			if err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestRunnerAdoptsLegacyVersions(t *testing.T) {
+	db := newTestDB(t)
+	err := db.Exec(`CREATE TABLE schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL);
+INSERT INTO schema_migrations (version, dirty) VALUES (1, false);
+CREATE TABLE users (id TEXT PRIMARY KEY);`).Error
+	if err != nil {
+		t.Fatalf("failed to seed legacy schema: %v", err)
+	}
+
+	runner, err := NewRunner(db, config.DriverSQLite, logger.NewNopLogger(), testSources())
+	if err != nil {
+		t.Fatalf("failed to create runner: %v", err)
+	}
+	if err := runner.Up(context.Background()); err != nil {
+		t.Fatalf("up failed: %v", err)
+	}
+	if applied := appliedList(t, runner); len(applied) != 3 {
+		t.Errorf("applied = %v, want [1 2 3]", applied)
+	}
+}
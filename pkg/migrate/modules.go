@@ -0,0 +1,18 @@
+package migrate
+
+import (
+	"io/fs"
+
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/module"
+	"gorm.io/gorm"
+)
+
+// NewModuleRunner creates a Runner over the migrations shipped by the given modules
+func NewModuleRunner(db *gorm.DB, driver string, log logger.Logger, modules []module.Module) (*Runner, error) {
+	sources := make(map[string]fs.FS, len(modules))
+	for _, m := range modules {
+		sources[m.Name()] = m.Migrations()
+	}
+	return NewRunner(db, driver, log, sources)
+}
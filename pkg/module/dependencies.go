@@ -0,0 +1,59 @@
+package module
+
+import (
+	"errors"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/Alfian57/golang-todo/pkg/denylist"
+	"github.com/Alfian57/golang-todo/pkg/logger"
+	"github.com/Alfian57/golang-todo/pkg/middleware"
+	"github.com/Alfian57/golang-todo/pkg/utils"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// Dependencies is the container of shared services handed to every module
+// It is built once in main.go so modules don't construct their own copies
+type Dependencies struct {
+	DB        *gorm.DB
+	Config    *config.Config
+	Log       logger.Logger
+	JWTUtils  *utils.JWTUtils
+	Validator *validator.Validate
+	IsDebug   bool
+
+	// Denylist holds the access tokens revoked before they expired
+	Denylist denylist.Denylist
+
+	// TokenValidator is handed to middleware.AuthMiddleware, it comes from the module implementing TokenValidatorProvider
+	TokenValidator middleware.TokenValidator
+}
+
+// NewDependencies creates the shared dependency container
+func NewDependencies(db *gorm.DB, cfg *config.Config, log logger.Logger) (*Dependencies, error) {
+	jwtUtils, err := utils.NewJWTUtils(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := &Dependencies{
+		DB:        db,
+		Config:    cfg,
+		Log:       log,
+		JWTUtils:  jwtUtils,
+		Validator: utils.GetValidator(),
+		IsDebug:   cfg.App.Mode != config.ModeRelease,
+		Denylist:  denylist.New(cfg.JWT, db),
+	}
+
+	for _, m := range Modules() {
+		if provider, ok := m.(TokenValidatorProvider); ok {
+			deps.TokenValidator = provider.TokenValidator(deps)
+		}
+	}
+	if deps.TokenValidator == nil {
+		return nil, errors.New("no registered module provides a token validator")
+	}
+
+	return deps, nil
+}
@@ -0,0 +1,64 @@
+package module
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/logger"
+)
+
+// Job is a background task that runs periodically until the server shuts down
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// StartJobs runs every job of the registered modules in its own goroutine
+// The returned WaitGroup is done once all jobs have stopped after ctx is cancelled
+func StartJobs(ctx context.Context, deps *Dependencies) *sync.WaitGroup {
+	var wg sync.WaitGroup
+
+	for _, m := range Modules() {
+		for _, job := range m.Jobs(deps) {
+			wg.Add(1)
+			go func(moduleName string, job Job) {
+				defer wg.Done()
+				runJob(ctx, moduleName, job, deps.Log)
+			}(m.Name(), job)
+		}
+	}
+
+	return &wg
+}
+
+func runJob(ctx context.Context, moduleName string, job Job, log logger.Logger) {
+	log.Info("Starting background job",
+		logger.F("module", moduleName),
+		logger.F("job", job.Name),
+		logger.F("interval", job.Interval),
+	)
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Background job stopped",
+				logger.F("module", moduleName),
+				logger.F("job", job.Name),
+			)
+			return
+		case <-ticker.C:
+			if err := job.Run(ctx); err != nil {
+				log.Error("Background job failed",
+					logger.F("module", moduleName),
+					logger.F("job", job.Name),
+					logger.F("error", err),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package module
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/Alfian57/golang-todo/pkg/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Module is a self-contained feature that is mounted by the server
+// Feature packages register themselves from an init function with Register
+type Module interface {
+	// Name returns the unique name of the module
+	Name() string
+
+	// RegisterRoutes mounts the module's HTTP routes on the versioned API group
+	RegisterRoutes(router *gin.RouterGroup, deps *Dependencies)
+
+	// Migrations returns the SQL migrations owned by the module, or nil if it has none
+	Migrations() fs.FS
+
+	// Jobs returns the background jobs the module needs running, or nil if it has none
+	Jobs(deps *Dependencies) []Job
+}
+
+// TokenValidatorProvider is implemented by the module that owns the access tokens
+// NewDependencies hands its validator to every module through Dependencies.TokenValidator
+type TokenValidatorProvider interface {
+	TokenValidator(deps *Dependencies) middleware.TokenValidator
+}
+
+var registry []Module
+
+// Register adds a module to the registry
+// It panics when a module with the same name is already registered
+func Register(m Module) {
+	for _, registered := range registry {
+		if registered.Name() == m.Name() {
+			panic(fmt.Sprintf("module %q registered twice", m.Name()))
+		}
+	}
+	registry = append(registry, m)
+}
+
+// Modules returns the registered modules in registration order
+func Modules() []Module {
+	modules := make([]Module, len(registry))
+	copy(modules, registry)
+	return modules
+}
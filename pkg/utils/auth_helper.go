@@ -4,7 +4,6 @@ import (
 	"errors"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
@@ -13,6 +12,8 @@ var (
 	ErrInvalidClaimsType = errors.New("invalid claims type")
 	ErrInvalidUserID     = errors.New("invalid user ID in token")
 	ErrUserIDNotFound    = errors.New("user ID not found in token")
+	ErrInvalidSessionID  = errors.New("invalid session ID in token")
+	ErrInvalidTokenID    = errors.New("invalid token ID in token")
 )
 
 // GetUserIDFromContext extracts and validates user ID from JWT claims in context
@@ -24,8 +25,8 @@ func GetUserIDFromContext(ctx *gin.Context) (uuid.UUID, error) {
 		return uuid.Nil, ErrClaimsNotFound
 	}
 
-	// Type assert to *jwt.RegisteredClaims
-	claims, ok := claimsInterface.(*jwt.RegisteredClaims)
+	// Type assert to *Claims
+	claims, ok := claimsInterface.(*Claims)
 	if !ok {
 		return uuid.Nil, ErrInvalidClaimsType
 	}
@@ -46,16 +47,31 @@ func GetUserIDFromContext(ctx *gin.Context) (uuid.UUID, error) {
 
 // GetClaimsFromContext extracts JWT claims from context
 // Use this if you need access to full claims data (issuer, expiry, etc.)
-func GetClaimsFromContext(ctx *gin.Context) (*jwt.RegisteredClaims, error) {
+func GetClaimsFromContext(ctx *gin.Context) (*Claims, error) {
 	claimsInterface, exists := ctx.Get("claims")
 	if !exists {
 		return nil, ErrClaimsNotFound
 	}
 
-	claims, ok := claimsInterface.(*jwt.RegisteredClaims)
+	claims, ok := claimsInterface.(*Claims)
 	if !ok {
 		return nil, ErrInvalidClaimsType
 	}
 
 	return claims, nil
 }
+
+// GetSessionIDFromContext extracts the session the access token in context was issued for
+func GetSessionIDFromContext(ctx *gin.Context) (uuid.UUID, error) {
+	claims, err := GetClaimsFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return uuid.Nil, ErrInvalidSessionID
+	}
+
+	return sessionID, nil
+}
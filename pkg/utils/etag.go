@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Alfian57/golang-todo/common/models"
+	"github.com/gin-gonic/gin"
+)
+
+// VersionETag returns the strong entity tag of a row version
+func VersionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ContentETag returns a weak entity tag of the JSON encoding of data
+func ContentETag(data any) (string, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// MatchETag reports whether an If-Match or If-None-Match header lists the entity tag, * matches any
+// If-None-Match compares weakly, ignoring W/ prefixes, while If-Match compares strongly so weak tags never match
+func MatchETag(header string, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	} else if strings.HasPrefix(etag, "W/") {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONWithETag writes a response with a content ETag of its data
+// A successful response whose ETag the request's If-None-Match lists becomes 304 Not Modified without a body
+func JSONWithETag(ctx *gin.Context, response models.Response) {
+	if response.StatusCode == http.StatusOK {
+		if etag, err := ContentETag(response.Data); err == nil {
+			ctx.Header("ETag", etag)
+			if match := ctx.GetHeader("If-None-Match"); match != "" && MatchETag(match, etag, true) {
+				ctx.Status(http.StatusNotModified)
+				return
+			}
+		}
+	}
+	ctx.JSON(response.StatusCode, response)
+}
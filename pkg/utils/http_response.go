@@ -51,6 +51,21 @@ func NotFoundResponse(message string, err error, isDebug bool) models.Response {
 	return ErrorResponse(http.StatusNotFound, message, err, isDebug)
 }
 
+// ConflictResponse creates a 409 Conflict response
+func ConflictResponse(message string, err error, isDebug bool) models.Response {
+	return ErrorResponse(http.StatusConflict, message, err, isDebug)
+}
+
+// PreconditionFailedResponse creates a 412 Precondition Failed response
+func PreconditionFailedResponse(message string, err error, isDebug bool) models.Response {
+	return ErrorResponse(http.StatusPreconditionFailed, message, err, isDebug)
+}
+
+// UnsupportedMediaTypeResponse creates a 415 Unsupported Media Type response
+func UnsupportedMediaTypeResponse(message string, err error, isDebug bool) models.Response {
+	return ErrorResponse(http.StatusUnsupportedMediaType, message, err, isDebug)
+}
+
 // UnprocessableEntityResponse creates a 422 Unprocessable Entity response
 func UnprocessableEntityResponse(message string, err error, isDebug bool) models.Response {
 	return ErrorResponse(http.StatusUnprocessableEntity, message, err, isDebug)
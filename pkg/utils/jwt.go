@@ -7,6 +7,7 @@ import (
 
 	"github.com/Alfian57/golang-todo/pkg/config"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // JWTUtils provides JWT token operations with injected configuration
@@ -14,47 +15,120 @@ type JWTUtils struct {
 	appName   string
 	jwtSecret []byte
 	tokenTTL  time.Duration
+
+	// keys signs and verifies tokens with an asymmetric algorithm, nil with HS256
+	keys *keySet
 }
 
 // NewJWTUtils creates a new JWTUtils instance with the given configuration
-func NewJWTUtils(cfg *config.Config) *JWTUtils {
-	return &JWTUtils{
+// With an asymmetric algorithm the keys are loaded from the keys directory, a key is generated when none is usable
+func NewJWTUtils(cfg *config.Config) (*JWTUtils, error) {
+	j := &JWTUtils{
 		appName:   cfg.App.Name,
 		jwtSecret: cfg.JWT.Secret,
 		tokenTTL:  cfg.JWT.TTL,
 	}
+	if cfg.JWT.Algorithm == "" || cfg.JWT.Algorithm == config.AlgorithmHS256 {
+		return j, nil
+	}
+
+	keys, err := newKeySet(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
+	j.keys = keys
+	return j, nil
+}
+
+// Claims are the claims of an access token
+type Claims struct {
+	// SessionID is the login the token was issued for, revoking the session invalidates the token
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
 }
 
-// CreateJWT creates a new JWT token for the given user ID
-func (j *JWTUtils) CreateJWT(userId string) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Issuer:    j.appName,
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.tokenTTL)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Subject:   userId,
+// NewClaims builds the claims of a new access token for the given user ID and session
+// Every token gets a unique ID, which is what a revoked token is denylisted by
+func (j *JWTUtils) NewClaims(userId string, sessionID string) Claims {
+	now := time.Now()
+	return Claims{
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    j.appName,
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.tokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   userId,
+		},
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.jwtSecret)
+// SignJWT signs the claims into a JWT token
+// With an asymmetric algorithm the token is signed by the active key, named in the kid header
+func (j *JWTUtils) SignJWT(claims Claims) (string, error) {
+	if j.keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(j.jwtSecret)
+	}
+
+	key, err := j.keys.active()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// CreateJWT creates a new JWT token for the given user ID and session
+func (j *JWTUtils) CreateJWT(userId string, sessionID string) (string, error) {
+	return j.SignJWT(j.NewClaims(userId, sessionID))
 }
 
 // ParseJWT parses and validates a JWT token string
-func (j *JWTUtils) ParseJWT(tokenStr string) (*jwt.RegisteredClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) {
+func (j *JWTUtils) ParseJWT(tokenStr string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, j.verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, jwt.ErrTokenInvalidClaims
+}
+
+// verificationKey returns the key a token is verified with
+// With an asymmetric algorithm that is the public key named by the kid header, which must have been made for the signing method
+func (j *JWTUtils) verificationKey(token *jwt.Token) (any, error) {
+	if j.keys == nil {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrTokenSignatureInvalid
 		}
 		return j.jwtSecret, nil
-	})
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+	key, err := j.keys.find(kid)
 	if err != nil {
 		return nil, err
 	}
-
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
-		return claims, nil
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
 	}
+	return key.private.Public(), nil
+}
 
-	return nil, jwt.ErrTokenInvalidClaims
+// JWKS returns the public keys tokens are verified with, empty with HS256
+func (j *JWTUtils) JWKS() JWKS {
+	if j.keys == nil {
+		return JWKS{Keys: []JWK{}}
+	}
+	return j.keys.jwks()
 }
 
 // GetJWTTTL returns the expiration time for a new token
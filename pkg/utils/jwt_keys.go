@@ -0,0 +1,332 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrUnsupportedKey = errors.New("unsupported JWT signing key")
+
+// kidTimeLayout starts the kid of a generated key, so its creation time survives copying the keys directory
+const kidTimeLayout = "20060102T150405Z"
+
+// minReloadInterval is how often an unknown kid may make the keys directory be read again,
+// so tokens with made up kids can't make every request read the disk
+const minReloadInterval = time.Minute
+
+// signingKey is a private key of the key set, identified by the kid header of the tokens it signs
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	createdAt time.Time
+}
+
+// keySet holds the asymmetric keys tokens are signed and verified with
+// The newest key of the configured algorithm signs, every key that may still have signed an unexpired token verifies
+type keySet struct {
+	mu        sync.RWMutex
+	algorithm string
+	dir       string
+	rotation  time.Duration
+	tokenTTL  time.Duration
+	now       func() time.Time
+
+	// keys is sorted by creation time, oldest first
+	keys []signingKey
+
+	// loadedAt is when the keys directory was last read
+	loadedAt time.Time
+}
+
+func newKeySet(cfg config.JWTConfig) (*keySet, error) {
+	keys := &keySet{
+		algorithm: cfg.Algorithm,
+		dir:       cfg.KeysDir,
+		rotation:  cfg.KeyRotation,
+		tokenTTL:  cfg.TTL,
+		now:       time.Now,
+	}
+	if err := keys.load(); err != nil {
+		return nil, err
+	}
+	if _, err := keys.active(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// load reads every key from the keys directory, replacing the keys already loaded
+func (keys *keySet) load() error {
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+
+	return keys.read()
+}
+
+// reload reads the keys directory again, unless it was read less than minReloadInterval ago
+func (keys *keySet) reload() error {
+	keys.mu.RLock()
+	due := keys.now().Sub(keys.loadedAt) >= minReloadInterval
+	keys.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+
+	// Another request may have read the directory in the meantime
+	if keys.now().Sub(keys.loadedAt) < minReloadInterval {
+		return nil
+	}
+	return keys.read()
+}
+
+// read reads every key from the keys directory, the caller holds the write lock
+func (keys *keySet) read() error {
+	if err := os.MkdirAll(keys.dir, 0o700); err != nil {
+		return fmt.Errorf("create JWT keys directory: %w", err)
+	}
+	entries, err := os.ReadDir(keys.dir)
+	if err != nil {
+		return fmt.Errorf("read JWT keys directory: %w", err)
+	}
+
+	loaded := make([]signingKey, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pem" {
+			continue
+		}
+		key, err := readSigningKey(filepath.Join(keys.dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		loaded = append(loaded, key)
+	}
+	slices.SortFunc(loaded, func(a, b signingKey) int {
+		return a.createdAt.Compare(b.createdAt)
+	})
+
+	keys.keys = loaded
+	keys.loadedAt = keys.now()
+	return nil
+}
+
+// readSigningKey reads a PKCS #8, PKCS #1 or SEC 1 PEM private key, the file name without .pem is its kid
+// The creation time is read from the kid of a generated key, a key added by hand falls back to the file's modification time
+func readSigningKey(path string) (signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("read JWT key %s: %w", path, err)
+	}
+
+	key := signingKey{
+		id: strings.TrimSuffix(filepath.Base(path), ".pem"),
+	}
+	prefix, _, _ := strings.Cut(key.id, "-")
+	if createdAt, err := time.Parse(kidTimeLayout, prefix); err == nil {
+		key.createdAt = createdAt
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return signingKey{}, fmt.Errorf("read JWT key %s: %w", path, err)
+		}
+		key.createdAt = info.ModTime()
+	}
+	if private, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		key.method = jwt.SigningMethodRS256
+		key.private = private
+		return key, nil
+	}
+	if private, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		key.method = jwt.SigningMethodEdDSA
+		key.private = private.(ed25519.PrivateKey)
+		return key, nil
+	}
+	return signingKey{}, fmt.Errorf("%w: %s is neither an RSA nor an Ed25519 private key", ErrUnsupportedKey, path)
+}
+
+// active returns the key that signs new tokens, a new key is generated when there is none or it is due for rotation
+func (keys *keySet) active() (signingKey, error) {
+	keys.mu.RLock()
+	key, ok := keys.newest()
+	keys.mu.RUnlock()
+	if ok && !keys.rotationDue(key) {
+		return key, nil
+	}
+
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+
+	// Another request may have rotated the key in the meantime, or another instance sharing the keys directory
+	if key, ok := keys.newest(); ok && !keys.rotationDue(key) {
+		return key, nil
+	}
+	if err := keys.read(); err != nil {
+		return signingKey{}, err
+	}
+	if key, ok := keys.newest(); ok && !keys.rotationDue(key) {
+		return key, nil
+	}
+	key, err := keys.generate()
+	if err != nil {
+		return signingKey{}, err
+	}
+	keys.keys = append(keys.keys, key)
+	return key, nil
+}
+
+// newest returns the newest key of the configured algorithm, the caller holds the lock
+func (keys *keySet) newest() (signingKey, bool) {
+	for _, key := range slices.Backward(keys.keys) {
+		if key.method.Alg() == keys.algorithm {
+			return key, true
+		}
+	}
+	return signingKey{}, false
+}
+
+func (keys *keySet) rotationDue(key signingKey) bool {
+	return keys.rotation > 0 && keys.now().Sub(key.createdAt) >= keys.rotation
+}
+
+// generate creates a key of the configured algorithm and saves it to the keys directory, the caller holds the write lock
+func (keys *keySet) generate() (signingKey, error) {
+	// The kid only keeps whole seconds, so the key is created at the time it will be read back with
+	createdAt := keys.now().UTC().Truncate(time.Second)
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return signingKey{}, err
+	}
+	key := signingKey{
+		id:        createdAt.Format(kidTimeLayout) + "-" + hex.EncodeToString(suffix),
+		createdAt: createdAt,
+	}
+
+	var err error
+	switch keys.algorithm {
+	case config.AlgorithmRS256:
+		key.method = jwt.SigningMethodRS256
+		key.private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case config.AlgorithmEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+		_, key.private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		err = fmt.Errorf("%w: algorithm %s", ErrUnsupportedKey, keys.algorithm)
+	}
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key.private)
+	if err != nil {
+		return signingKey{}, err
+	}
+	path := filepath.Join(keys.dir, key.id+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return signingKey{}, fmt.Errorf("save JWT key: %w", err)
+	}
+	return key, nil
+}
+
+// verifying returns the keys that may have signed a token that hasn't expired yet
+// A key is retired once the key that replaced it is older than the token TTL, the caller holds the lock
+func (keys *keySet) verifying() []signingKey {
+	now := keys.now()
+	verifying := make([]signingKey, 0, len(keys.keys))
+	for i, key := range keys.keys {
+		if i+1 < len(keys.keys) && now.Sub(keys.keys[i+1].createdAt) > keys.tokenTTL {
+			continue
+		}
+		verifying = append(verifying, key)
+	}
+	return verifying
+}
+
+// find returns the verifying key with the kid
+// An unknown kid may be a key another instance generated, so the keys directory is read again, at most once every minReloadInterval
+func (keys *keySet) find(kid string) (signingKey, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		keys.mu.RLock()
+		for _, key := range keys.verifying() {
+			if key.id == kid {
+				keys.mu.RUnlock()
+				return key, nil
+			}
+		}
+		keys.mu.RUnlock()
+
+		if attempt == 0 {
+			if err := keys.reload(); err != nil {
+				return signingKey{}, err
+			}
+		}
+	}
+	return signingKey{}, jwt.ErrTokenUnverifiable
+}
+
+// JWK is a public key of the JSON Web Key Set, RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+
+	// N and E are the modulus and exponent of an RSA key
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Curve and X are the curve and public key of an Ed25519 key, RFC 8037
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is the JSON Web Key Set published at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (keys *keySet) jwks() JWKS {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(keys.keys))}
+	for _, key := range keys.verifying() {
+		jwk := JWK{
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+		}
+		switch public := key.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/golang-todo/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestJWTUtils(t *testing.T, algorithm string, keysDir string) *JWTUtils {
+	t.Helper()
+
+	j, err := NewJWTUtils(&config.Config{
+		App: config.AppConfig{Name: "golang-todo-test"},
+		JWT: config.JWTConfig{
+			Secret:      []byte("test-secret"),
+			TTL:         time.Hour,
+			Algorithm:   algorithm,
+			KeysDir:     keysDir,
+			KeyRotation: 24 * time.Hour,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create JWT utils: %v", err)
+	}
+	return j
+}
+
+// kidOf returns the kid header of a token without verifying it
+func kidOf(t *testing.T, token string) string {
+	t.Helper()
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	if err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestJWTAsymmetric(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		keyType   string
+	}{
+		{algorithm: config.AlgorithmRS256, keyType: "RSA"},
+		{algorithm: config.AlgorithmEdDSA, keyType: "OKP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			dir := t.TempDir()
+			j := newTestJWTUtils(t, tt.algorithm, dir)
+
+			token, err := j.CreateJWT("user-1", "session-1")
+			if err != nil {
+				t.Fatalf("failed to create token: %v", err)
+			}
+			claims, err := j.ParseJWT(token)
+			if err != nil {
+				t.Fatalf("failed to parse token: %v", err)
+			}
+			if claims.Subject != "user-1" || claims.SessionID != "session-1" {
+				t.Errorf("claims = %+v, want user-1 and session-1", claims)
+			}
+
+			jwks := j.JWKS()
+			if len(jwks.Keys) != 1 {
+				t.Fatalf("JWKS has %d keys, want 1", len(jwks.Keys))
+			}
+			key := jwks.Keys[0]
+			if key.KeyID != kidOf(t, token) || key.KeyType != tt.keyType || key.Algorithm != tt.algorithm || key.Use != "sig" {
+				t.Errorf("JWK = %+v, want %s key %s for %s", key, tt.keyType, kidOf(t, token), tt.algorithm)
+			}
+
+			// Another instance sharing the keys directory signs with and accepts the same key
+			other := newTestJWTUtils(t, tt.algorithm, dir)
+			if _, err := other.ParseJWT(token); err != nil {
+				t.Errorf("token rejected by an instance sharing the keys: %v", err)
+			}
+			otherToken, _ := other.CreateJWT("user-1", "session-1")
+			if kidOf(t, otherToken) != kidOf(t, token) {
+				t.Errorf("instance sharing the keys signed with %s, want %s", kidOf(t, otherToken), kidOf(t, token))
+			}
+
+			// A token signed with the shared secret is no longer accepted
+			hmacToken, _ := newTestJWTUtils(t, config.AlgorithmHS256, "").CreateJWT("user-1", "session-1")
+			if _, err := j.ParseJWT(hmacToken); err == nil {
+				t.Error("HS256 token accepted, want it rejected")
+			}
+		})
+	}
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	j := newTestJWTUtils(t, config.AlgorithmEdDSA, dir)
+	now := time.Now()
+
+	oldToken, _ := j.CreateJWT("user-1", "session-1")
+
+	// The active key is replaced once it is older than the rotation interval
+	j.keys.now = func() time.Time { return now.Add(25 * time.Hour) }
+	newToken, err := j.CreateJWT("user-1", "session-1")
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+	if kidOf(t, newToken) == kidOf(t, oldToken) {
+		t.Fatal("token signed with the old key after the rotation interval")
+	}
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := j.ParseJWT(token); err != nil {
+			t.Errorf("token rejected after rotation: %v", err)
+		}
+	}
+	if got := len(j.JWKS().Keys); got != 2 {
+		t.Errorf("JWKS has %d keys after rotation, want 2", got)
+	}
+
+	// An instance started after the rotation picks up the newest key
+	other := newTestJWTUtils(t, config.AlgorithmEdDSA, dir)
+	other.keys.now = j.keys.now
+	otherToken, _ := other.CreateJWT("user-1", "session-1")
+	if kidOf(t, otherToken) != kidOf(t, newToken) {
+		t.Errorf("instance started after the rotation signed with %s, want %s", kidOf(t, otherToken), kidOf(t, newToken))
+	}
+
+	// The old key is retired once every token it signed has expired
+	j.keys.now = func() time.Time { return now.Add(25*time.Hour + 2*time.Hour) }
+	if _, err := j.ParseJWT(oldToken); !errors.Is(err, jwt.ErrTokenUnverifiable) {
+		t.Errorf("token of a retired key err = %v, want %v", err, jwt.ErrTokenUnverifiable)
+	}
+	jwks := j.JWKS()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != kidOf(t, newToken) {
+		t.Errorf("JWKS = %+v, want only key %s", jwks, kidOf(t, newToken))
+	}
+}
+
+// The creation time of a key comes from its kid, so copying the keys directory doesn't postpone rotation or retirement
+func TestJWTKeyCreationTime(t *testing.T) {
+	dir := t.TempDir()
+	j := newTestJWTUtils(t, config.AlgorithmEdDSA, dir)
+	now := time.Now()
+	oldToken, _ := j.CreateJWT("user-1", "session-1")
+	j.keys.now = func() time.Time { return now.Add(25 * time.Hour) }
+	newToken, _ := j.CreateJWT("user-1", "session-1")
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if err := os.Chtimes(filepath.Join(dir, entry.Name()), now.Add(48*time.Hour), now.Add(48*time.Hour)); err != nil {
+			t.Fatalf("failed to touch key: %v", err)
+		}
+	}
+
+	copied := newTestJWTUtils(t, config.AlgorithmEdDSA, dir)
+	copied.keys.now = func() time.Time { return now.Add(25*time.Hour + 2*time.Hour) }
+	jwks := copied.JWKS()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != kidOf(t, newToken) {
+		t.Errorf("JWKS of the copied keys = %+v, want only key %s", jwks, kidOf(t, newToken))
+	}
+	if _, err := copied.ParseJWT(oldToken); err == nil {
+		t.Error("token of a retired key accepted after copying the keys")
+	}
+
+	// The newest key is still rotated on schedule
+	copied.keys.now = func() time.Time { return now.Add(50 * time.Hour) }
+	rotated, _ := copied.CreateJWT("user-1", "session-1")
+	if kidOf(t, rotated) == kidOf(t, newToken) {
+		t.Error("copied key not rotated after the rotation interval")
+	}
+}
+
+// Instances sharing the keys directory sign with the key the first of them rotated to, rather than each generating one
+func TestJWTSharedKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	first := newTestJWTUtils(t, config.AlgorithmEdDSA, dir)
+	second := newTestJWTUtils(t, config.AlgorithmEdDSA, dir)
+	firstToken, _ := first.CreateJWT("user-1", "session-1")
+	secondToken, _ := second.CreateJWT("user-1", "session-1")
+	if kidOf(t, firstToken) != kidOf(t, secondToken) {
+		t.Fatalf("instances sign with keys %s and %s, want the same key", kidOf(t, firstToken), kidOf(t, secondToken))
+	}
+
+	later := time.Now().Add(25 * time.Hour)
+	first.keys.now = func() time.Time { return later }
+	second.keys.now = func() time.Time { return later }
+	firstToken, _ = first.CreateJWT("user-1", "session-1")
+	secondToken, _ = second.CreateJWT("user-1", "session-1")
+	if kidOf(t, secondToken) != kidOf(t, firstToken) {
+		t.Errorf("second instance signs with key %s, want %s the first one rotated to", kidOf(t, secondToken), kidOf(t, firstToken))
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("keys directory has %d keys, want the first and the rotated one", len(entries))
+	}
+}
+
+// An unknown kid reads the keys directory again at most once every minReloadInterval
+func TestJWTReloadInterval(t *testing.T) {
+	dir := t.TempDir()
+	j := newTestJWTUtils(t, config.AlgorithmEdDSA, dir)
+	now := time.Now()
+	j.keys.now = func() time.Time { return now }
+
+	// Another instance sharing the directory rotates the key
+	other := newTestJWTUtils(t, config.AlgorithmEdDSA, dir)
+	other.keys.now = func() time.Time { return now.Add(25 * time.Hour) }
+	token, _ := other.CreateJWT("user-1", "session-1")
+
+	// The directory was read too recently to look for the new key
+	if _, err := j.ParseJWT(token); err == nil {
+		t.Fatal("token accepted before the reload interval passed")
+	}
+	j.keys.now = func() time.Time { return now.Add(minReloadInterval) }
+	if _, err := j.ParseJWT(token); err != nil {
+		t.Errorf("token of a key generated by another instance rejected after the reload interval: %v", err)
+	}
+}
+
+func TestJWTUnknownKey(t *testing.T) {
+	j := newTestJWTUtils(t, config.AlgorithmRS256, t.TempDir())
+	other := newTestJWTUtils(t, config.AlgorithmRS256, t.TempDir())
+
+	token, _ := other.CreateJWT("user-1", "session-1")
+	if _, err := j.ParseJWT(token); err == nil {
+		t.Error("token signed with an unknown key accepted")
+	}
+
+	// The kid must name a key of the algorithm in the header
+	parts := strings.Split(token, ".")
+	header, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	forged := strings.Replace(string(header), `"RS256"`, `"EdDSA"`, 1)
+	parts[0] = base64.RawURLEncoding.EncodeToString([]byte(forged))
+	if _, err := other.ParseJWT(strings.Join(parts, ".")); err == nil {
+		t.Error("token with a mismatched algorithm accepted")
+	}
+}
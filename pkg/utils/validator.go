@@ -13,6 +13,11 @@ func init() {
 	validate = validator.New()
 }
 
+// GetValidator returns the shared validator used to validate requests
+func GetValidator() *validator.Validate {
+	return validate
+}
+
 func ValidateRequest(ctx *gin.Context, req any) bool {
 	isDebug := os.Getenv("GIN_MODE") == "debug"
 
@@ -30,3 +35,21 @@ func ValidateRequest(ctx *gin.Context, req any) bool {
 
 	return true
 }
+
+func ValidateQuery(ctx *gin.Context, req any) bool {
+	isDebug := os.Getenv("GIN_MODE") == "debug"
+
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		response := UnprocessableEntityResponse("Invalid query parameters", err, isDebug)
+		ctx.JSON(response.StatusCode, response)
+		return false
+	}
+
+	if err := validate.Struct(req); err != nil {
+		response := UnprocessableEntityResponse("Validation failed", err, isDebug)
+		ctx.JSON(response.StatusCode, response)
+		return false
+	}
+
+	return true
+}
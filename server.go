@@ -3,34 +3,40 @@ package main
 import (
 	"net/http"
 
-	"github.com/Alfian57/golang-todo/internal/auth"
-	"github.com/Alfian57/golang-todo/pkg/config"
 	"github.com/Alfian57/golang-todo/pkg/logger"
 	"github.com/Alfian57/golang-todo/pkg/middleware"
+	"github.com/Alfian57/golang-todo/pkg/module"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"gorm.io/gorm"
 )
 
-func initServer(db *gorm.DB, cfg *config.Config, log logger.Logger) *http.Server {
+func initServer(deps *module.Dependencies) *http.Server {
 	r := gin.New()
 
 	// Use custom Zap middleware with injected logger
-	r.Use(middleware.ZapRecovery(log))
-	r.Use(middleware.ZapLogger(log))
+	r.Use(middleware.ZapRecovery(deps.Log))
+	r.Use(middleware.ZapLogger(deps.Log))
 
 	api := r.Group("api")
 	v1 := api.Group("v1")
 
 	// Register Routes
-	auth.RegisterRoutes(v1, db, cfg, log)
+	for _, m := range module.Modules() {
+		m.RegisterRoutes(v1, deps)
+		deps.Log.Info("Module mounted", logger.F("module", m.Name()))
+	}
+
+	// Public keys the access tokens are signed with, so other services can verify them
+	r.GET("/.well-known/jwks.json", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, deps.JWTUtils.JWKS())
+	})
 
 	// swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	srv := &http.Server{
-		Addr:    cfg.App.URL,
+		Addr:    deps.Config.App.URL,
 		Handler: r,
 	}
 